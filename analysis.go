@@ -0,0 +1,154 @@
+package astiffmpeg
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/asticode/go-astikit"
+)
+
+// BitratePoint represents the bitrate of a stream over a time bucket
+// Buckets start at the first timestamp of the stream so that streams that don't start at 0 (e.g. mpegts) don't get
+// empty leading buckets.
+type BitratePoint struct {
+	Bitrate float64 // bits/s
+	Time    time.Duration
+}
+
+// GOP represents a group of pictures, starting with a keyframe
+type GOP struct {
+	Duration time.Duration
+	Frames   int
+	Size     int // bytes
+	Time     time.Duration
+}
+
+// StreamAnalysis represents time series describing a stream, suitable for plotting
+type StreamAnalysis struct {
+	Bitrates  []BitratePoint
+	CodecType string
+	GOPs      []GOP // Only computed for video streams
+	Index     int
+}
+
+// AnalysisOptions represents analysis options
+type AnalysisOptions struct {
+	// Defaults to 1s
+	BucketDuration time.Duration
+	Probe          ProbeStreamOptions
+}
+
+// Analyze streams the input's packets and aggregates them per stream into bitrate buckets and GOPs
+// Streams are sorted by index.
+func (f *FFMpeg) Analyze(ctx context.Context, path string, o AnalysisOptions) (as []StreamAnalysis, err error) {
+	// Stream packets
+	ch, j, err := f.StreamPackets(ctx, path, o.Probe)
+	if err != nil {
+		err = fmt.Errorf("astiffmpeg: streaming packets failed: %w", err)
+		return
+	}
+
+	// Aggregate
+	a := newStreamsAnalyzer(o.BucketDuration)
+	for p := range ch {
+		a.add(p)
+	}
+
+	// Wait
+	if err = j.Wait(); err != nil {
+		err = fmt.Errorf("astiffmpeg: waiting failed: %w", err)
+		return
+	}
+	as = a.analyses()
+	return
+}
+
+type streamsAnalyzer struct {
+	bucket  time.Duration
+	streams map[int]*streamAnalyzer
+}
+
+type streamAnalyzer struct {
+	a     StreamAnalysis
+	sizes []int
+	start *time.Duration // First timestamp of the stream
+}
+
+func newStreamsAnalyzer(bucket time.Duration) *streamsAnalyzer {
+	if bucket <= 0 {
+		bucket = time.Second
+	}
+	return &streamsAnalyzer{
+		bucket:  bucket,
+		streams: make(map[int]*streamAnalyzer),
+	}
+}
+
+func (a *streamsAnalyzer) add(p ProbePacket) {
+	// Get stream
+	s, ok := a.streams[p.StreamIndex]
+	if !ok {
+		s = &streamAnalyzer{a: StreamAnalysis{
+			CodecType: p.CodecType,
+			Index:     p.StreamIndex,
+		}}
+		a.streams[p.StreamIndex] = s
+	}
+
+	// Get time
+	t := p.PTS
+	if t == nil {
+		t = p.DTS
+	}
+
+	// Bitrate
+	if t != nil {
+		if s.start == nil {
+			s.start = astikit.DurationPtr(*t)
+		}
+
+		// Packets presented before the first one (e.g. with B-frames) are added to the first bucket
+		idx := 0
+		if *t > *s.start {
+			idx = int((*t - *s.start) / a.bucket)
+		}
+		for len(s.sizes) <= idx {
+			s.sizes = append(s.sizes, 0)
+		}
+		s.sizes[idx] += p.Size
+	}
+
+	// GOP
+	if p.CodecType != "video" {
+		return
+	}
+	if p.Keyframe || len(s.a.GOPs) == 0 {
+		g := GOP{}
+		if t != nil {
+			g.Time = *t
+		}
+		s.a.GOPs = append(s.a.GOPs, g)
+	}
+	g := &s.a.GOPs[len(s.a.GOPs)-1]
+	g.Frames++
+	g.Size += p.Size
+	if p.Duration != nil {
+		g.Duration += *p.Duration
+	}
+}
+
+func (a *streamsAnalyzer) analyses() (as []StreamAnalysis) {
+	for _, s := range a.streams {
+		for idx, size := range s.sizes {
+			s.a.Bitrates = append(s.a.Bitrates, BitratePoint{
+				Bitrate: float64(size*8) / a.bucket.Seconds(),
+				Time:    *s.start + time.Duration(idx)*a.bucket,
+			})
+		}
+		as = append(as, s.a)
+	}
+	sort.Slice(as, func(i, j int) bool { return as[i].Index < as[j].Index })
+	return
+}
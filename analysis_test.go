@@ -0,0 +1,47 @@
+package astiffmpeg
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/asticode/go-astikit"
+)
+
+func TestStreamsAnalyzer(t *testing.T) {
+	a := newStreamsAnalyzer(time.Second)
+	for _, p := range []ProbePacket{
+		{CodecType: "video", Duration: astikit.DurationPtr(500 * time.Millisecond), Keyframe: true, PTS: astikit.DurationPtr(0), Size: 100},
+		{CodecType: "audio", Keyframe: true, PTS: astikit.DurationPtr(0), Size: 10, StreamIndex: 1},
+		{CodecType: "video", Duration: astikit.DurationPtr(500 * time.Millisecond), PTS: astikit.DurationPtr(500 * time.Millisecond), Size: 50},
+		{CodecType: "video", Duration: astikit.DurationPtr(500 * time.Millisecond), Keyframe: true, PTS: astikit.DurationPtr(time.Second), Size: 200},
+		{CodecType: "audio", PTS: astikit.DurationPtr(1400 * time.Millisecond), Size: 10, StreamIndex: 2},
+		{CodecType: "audio", PTS: astikit.DurationPtr(1300 * time.Millisecond), Size: 10, StreamIndex: 2},
+		{CodecType: "audio", PTS: astikit.DurationPtr(2500 * time.Millisecond), Size: 20, StreamIndex: 2},
+	} {
+		a.add(p)
+	}
+	e := []StreamAnalysis{
+		{
+			Bitrates:  []BitratePoint{{Bitrate: 1200}, {Bitrate: 1600, Time: time.Second}},
+			CodecType: "video",
+			GOPs: []GOP{
+				{Duration: time.Second, Frames: 2, Size: 150},
+				{Duration: 500 * time.Millisecond, Frames: 1, Size: 200, Time: time.Second},
+			},
+		},
+		{
+			Bitrates:  []BitratePoint{{Bitrate: 80}},
+			CodecType: "audio",
+			Index:     1,
+		},
+		{
+			Bitrates:  []BitratePoint{{Bitrate: 160, Time: 1400 * time.Millisecond}, {Bitrate: 160, Time: 2400 * time.Millisecond}},
+			CodecType: "audio",
+			Index:     2,
+		},
+	}
+	if g := a.analyses(); !reflect.DeepEqual(e, g) {
+		t.Errorf("expected %+v, got %+v", e, g)
+	}
+}
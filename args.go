@@ -0,0 +1,178 @@
+package astiffmpeg
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Options that don't take a value
+var valuelessOptions = map[string]bool{
+	"-accurate_seek":      true,
+	"-an":                 true,
+	"-autorotate":         true,
+	"-autoscale":          true,
+	"-benchmark":          true,
+	"-benchmark_all":      true,
+	"-copy_unknown":       true,
+	"-copyinkf":           true,
+	"-copyts":             true,
+	"-debug_ts":           true,
+	"-display_hflip":      true,
+	"-display_vflip":      true,
+	"-dn":                 true,
+	"-dump":               true,
+	"-find_stream_info":   true,
+	"-fix_sub_duration":   true,
+	"-hex":                true,
+	"-hide_banner":        true,
+	"-ignore_unknown":     true,
+	"-n":                  true,
+	"-noaccurate_seek":    true,
+	"-noautorotate":       true,
+	"-noautoscale":        true,
+	"-nofind_stream_info": true,
+	"-nostats":            true,
+	"-nostdin":            true,
+	"-re":                 true,
+	"-report":             true,
+	"-shortest":           true,
+	"-sn":                 true,
+	"-start_at_zero":      true,
+	"-stats":              true,
+	"-stdin":              true,
+	"-vn":                 true,
+	"-vstats":             true,
+	"-xerror":             true,
+	"-y":                  true,
+}
+
+// Options that can be set several times with different values
+var repeatableOptions = map[string]bool{
+	"-attach":  true,
+	"-map":     true,
+	"-program": true,
+}
+
+// Options that are aliases of other options, possibly with a stream specifier
+var optionAliases = map[string]string{
+	"-ab":      "-b:a",
+	"-acodec":  "-codec:a",
+	"-af":      "-filter:a",
+	"-aframes": "-frames:a",
+	"-aq":      "-q:a",
+	"-atag":    "-tag:a",
+	"-c":       "-codec",
+	"-dcodec":  "-codec:d",
+	"-dframes": "-frames:d",
+	"-lavfi":   "-filter_complex",
+	"-qscale":  "-q",
+	"-scodec":  "-codec:s",
+	"-stag":    "-tag:s",
+	"-vb":      "-b:v",
+	"-vcodec":  "-codec:v",
+	"-vf":      "-filter:v",
+	"-vframes": "-frames:v",
+	"-vtag":    "-tag:v",
+}
+
+type optionArg struct {
+	name  string
+	value []string
+}
+
+// canonicalName returns the option name where aliases are replaced by the option they stand for (e.g. "-codec:v"
+// for both "-c:v" and "-vcodec")
+func (a optionArg) canonicalName() string {
+	n, s := a.name, ""
+	if idx := strings.Index(n, ":"); idx > -1 {
+		n, s = n[:idx], n[idx:]
+	}
+	if c, ok := optionAliases[n]; ok {
+		if strings.Contains(c, ":") {
+			return c
+		}
+		n = c
+	}
+	return n + s
+}
+
+// base returns the canonical option name without its stream specifier (e.g. "-codec" for "-c:v")
+func (a optionArg) base() string {
+	n := a.canonicalName()
+	if idx := strings.Index(n, ":"); idx > -1 {
+		return n[:idx]
+	}
+	return n
+}
+
+// key identifies what the option sets, metadata options setting different keys don't conflict
+func (a optionArg) key() string {
+	if a.base() == "-metadata" && len(a.value) > 0 {
+		return a.canonicalName() + " " + strings.SplitN(a.value[0], "=", 2)[0]
+	}
+	return a.canonicalName()
+}
+
+func parseOptionArgs(args []string) (as []optionArg) {
+	for idx := 0; idx < len(args); idx++ {
+		a := optionArg{name: args[idx]}
+		if !valuelessOptions[a.name] && idx+1 < len(args) {
+			a.value = []string{args[idx+1]}
+			idx++
+		}
+		as = append(as, a)
+	}
+	return
+}
+
+// normalizeOptionArgs makes the options of a section of the command line (global options, or the options of an
+// input or an output) deterministic:
+//   - an option without stream specifier is placed before options with a stream specifier sharing the same name,
+//     so that the latter override the former instead of ffmpeg picking whichever comes last
+//   - an option set several times with different values, either by typed or raw options, is an error
+func normalizeOptionArgs(args []string) (o []string, err error) {
+	// Loop through options
+	var as []optionArg
+	values := make(map[string][]string)
+	for _, a := range parseOptionArgs(args) {
+		// Check conflicts
+		if !repeatableOptions[a.base()] {
+			if v, ok := values[a.key()]; ok && strings.Join(v, " ") != strings.Join(a.value, " ") {
+				err = fmt.Errorf("astiffmpeg: option %s is set several times with different values (%s and %s)", a.name, strings.Join(v, " "), strings.Join(a.value, " "))
+				return
+			}
+			values[a.key()] = a.value
+		}
+
+		// Option without stream specifier is placed before the first option with a stream specifier sharing the same
+		// name
+		pos := len(as)
+		if a.canonicalName() == a.base() {
+			for idx, b := range as {
+				if b.base() == a.base() && b.canonicalName() != b.base() {
+					pos = idx
+					break
+				}
+			}
+		}
+		as = append(as[:pos], append([]optionArg{a}, as[pos:]...)...)
+	}
+
+	// Create args
+	for _, a := range as {
+		o = append(o, a.name)
+		o = append(o, a.value...)
+	}
+	return
+}
+
+// normalizeCmdArgs normalizes the cmd args starting at the specified index
+func normalizeCmdArgs(cmd *exec.Cmd, start int) (err error) {
+	var args []string
+	if args, err = normalizeOptionArgs(cmd.Args[start:]); err != nil {
+		return
+	}
+	cmd.Args = append(cmd.Args[:start], args...)
+	return
+}
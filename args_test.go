@@ -0,0 +1,102 @@
+package astiffmpeg
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeOptionArgs(t *testing.T) {
+	// Ordering
+	args, err := normalizeOptionArgs([]string{"-map", "0", "-map", "1", "-codec:v", "libx264", "-an", "-b:v", "1M", "-codec", "copy", "-itsoffset", "-2"})
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	e := []string{"-map", "0", "-map", "1", "-codec", "copy", "-codec:v", "libx264", "-an", "-b:v", "1M", "-itsoffset", "-2"}
+	if !reflect.DeepEqual(e, args) {
+		t.Errorf("expected %+v, got %+v", e, args)
+	}
+
+	// Conflicts
+	if _, err = normalizeOptionArgs([]string{"-metadata", "title=a", "-metadata", "artist=b", "-b:v", "1M", "-b:v", "1M"}); err != nil {
+		t.Errorf("expected no error, got %s", err)
+	}
+	if _, err = normalizeOptionArgs([]string{"-metadata", "title=a", "-metadata", "title=b"}); err == nil {
+		t.Error("expected error")
+	}
+
+	// Aliases
+	for _, args := range [][]string{
+		{"-codec:v", "libx264", "-c:v", "libx265"},
+		{"-codec:v", "libx264", "-vcodec", "libx265"},
+		{"-b:v", "1M", "-vb", "2M"},
+		{"-filter:v", "null", "-vf", "hflip"},
+		{"-filter:a", "anull", "-af", "areverse"},
+	} {
+		if _, err = normalizeOptionArgs(args); err == nil {
+			t.Errorf("expected error for %+v", args)
+		}
+	}
+	if args, err = normalizeOptionArgs([]string{"-vcodec", "libx264", "-c", "copy"}); err != nil {
+		t.Errorf("expected no error, got %s", err)
+	}
+	if e = []string{"-c", "copy", "-vcodec", "libx264"}; !reflect.DeepEqual(e, args) {
+		t.Errorf("expected %+v, got %+v", e, args)
+	}
+
+	// Raw options
+	cmd := exec.Command("ffmpeg")
+	if err = (Output{Options: &OutputOptions{Format: "mp4", Raw: []string{"-f", "mov"}}, Path: "out"}).adaptCmd(cmd); err == nil {
+		t.Error("expected error")
+	}
+	cmd = exec.Command("ffmpeg")
+	if err = (Output{Options: &OutputOptions{NoAudio: true, Raw: []string{"-brand", "isom"}}, Path: "out"}).adaptCmd(cmd); err != nil {
+		t.Errorf("expected no error, got %s", err)
+	}
+	if e = []string{"ffmpeg", "-an", "-brand", "isom", "out"}; !reflect.DeepEqual(e, cmd.Args) {
+		t.Errorf("expected %+v, got %+v", e, cmd.Args)
+	}
+}
+
+func TestFFMpegArgs(t *testing.T) {
+	// Binary must exist
+	if _, err := New(Configuration{BinaryPath: "/does/not/exist/ffmpeg"}).Args(GlobalOptions{}, nil, Output{Path: "-"}); !errors.As(err, &ConfigurationError{}) {
+		t.Errorf("expected configuration error, got %v", err)
+	}
+	p, err := os.Executable()
+	if err != nil {
+		t.Skipf("getting executable failed: %s", err)
+	}
+	f := New(Configuration{BinaryPath: p, CheckFilters: true})
+
+	args, err := f.Args(GlobalOptions{}, []Input{{Path: "in.mp4"}}, Output{Options: &OutputOptions{Format: "mpegts"}, Path: "out.ts"})
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	if e := []string{p, "-hide_banner", "-i", "in.mp4", "-f", "mpegts", "out.ts"}; !reflect.DeepEqual(e, args) {
+		t.Errorf("expected %+v, got %+v", e, args)
+	}
+	if _, err = f.Args(GlobalOptions{}, nil, Output{Writer: &bytes.Buffer{}}); err == nil {
+		t.Error("expected error")
+	}
+
+	// Nothing local is created
+	d := t.TempDir()
+	out := filepath.Join(d, "out.ts")
+	if args, err = f.ArgsWithOptions(GlobalOptions{}, []Input{{Path: "in.mp4"}}, Output{Atomic: true, Path: out}, ExecOptions{
+		GracefulStop:    true,
+		Outputs:         []Output{{Path: "out.mp4", Sink: OutputSinkFunc(func(ctx context.Context, name string, r io.Reader) error { return nil })}},
+		ProgressHandler: func(p Progress) {},
+	}); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	if e := []string{p, "-hide_banner", "-n", "-i", "in.mp4", out, "-movflags", "+faststart", "out.mp4"}; !reflect.DeepEqual(e, args) {
+		t.Errorf("expected %+v, got %+v", e, args)
+	}
+}
@@ -0,0 +1,115 @@
+package astiffmpeg
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/asticode/go-astikit"
+)
+
+// CoverArtOutputOptions creates output options attaching a cover image to an audio output without re-encoding
+// It expects the audio to be read from the first input and the cover image (jpeg or png) from the second one.
+// Supported formats are "mp3" and "ipod" (m4a).
+func CoverArtOutputOptions(format string) (o OutputOptions, err error) {
+	// Create options
+	o = OutputOptions{
+		Dispositions: []StreamOption{{
+			Stream: &StreamSpecifier{Index: astikit.IntPtr(0), Type: StreamSpecifierTypeVideo},
+			Value:  "attached_pic",
+		}},
+		Encoding: &EncodingOptions{Codec: []StreamOption{{Value: CodecCopy}}},
+		Format:   format,
+		Map: &MapOptions{
+			{InputFileID: 0, Stream: &StreamSpecifier{Type: StreamSpecifierTypeAudio}},
+			{InputFileID: 1, Stream: &StreamSpecifier{Type: StreamSpecifierTypeVideo}},
+		},
+	}
+
+	// Format specific options
+	switch format {
+	case "mp3":
+		// Players expect the picture type to be "Cover (front)" which the mp3 muxer infers from the stream comment
+		o.Muxing = &MuxingOptions{
+			ID3v2Version: astikit.IntPtr(3),
+			WriteID3v1:   astikit.BoolPtr(true),
+		}
+		o.StreamMetadata = []StreamOption{{
+			Stream: &StreamSpecifier{Type: StreamSpecifierTypeVideo},
+			Value: map[string]string{
+				"comment": "Cover (front)",
+				"title":   "Album cover",
+			},
+		}}
+	case "ipod":
+	default:
+		err = fmt.Errorf("astiffmpeg: format %s is not supported", format)
+		return
+	}
+	return
+}
+
+// AudioTrack represents an audio track of a multi audio tracks output
+type AudioTrack struct {
+	// Only one track should be the default one
+	Default     bool
+	InputFileID int
+	// ISO 639-2 code (e.g. "eng", "fra", ...)
+	Language string
+	// Defaults to the first audio stream of the input
+	Stream *StreamSpecifier
+	Title  string
+}
+
+// MultiAudioTracksOutputOptions creates output options copying the video of the first input and adding one audio
+// track per provided track, all encoded with the same codec and bitrate so that players can switch seamlessly
+// between them (e.g. original, dubbed and audio description tracks)
+func MultiAudioTracksOutputOptions(codec string, bitrate Number, tracks []AudioTrack) (o OutputOptions, err error) {
+	// Check tracks
+	if len(tracks) == 0 {
+		err = errors.New("astiffmpeg: no audio tracks provided")
+		return
+	}
+
+	// Create options
+	o = OutputOptions{
+		Encoding: &EncodingOptions{
+			Bitrate: []StreamOption{audioStreamOption(bitrate)},
+			Codec: []StreamOption{
+				videoStreamOption(CodecCopy),
+				audioStreamOption(codec),
+			},
+		},
+		Map: &MapOptions{{Stream: &StreamSpecifier{Index: astikit.IntPtr(0), Type: StreamSpecifierTypeVideo}}},
+	}
+
+	// Loop through tracks
+	for idx, t := range tracks {
+		// Map
+		s := t.Stream
+		if s == nil {
+			s = &StreamSpecifier{Index: astikit.IntPtr(0), Type: StreamSpecifierTypeAudio}
+		}
+		*o.Map = append(*o.Map, MapOption{InputFileID: t.InputFileID, Stream: s})
+
+		// Disposition
+		ss := &StreamSpecifier{Index: astikit.IntPtr(idx), Type: StreamSpecifierTypeAudio}
+		d := "0"
+		if t.Default {
+			d = "default"
+		}
+		o.Dispositions = append(o.Dispositions, StreamOption{Stream: ss, Value: d})
+
+		// Metadata
+		m := make(map[string]string)
+		if len(t.Language) > 0 {
+			m["language"] = t.Language
+		}
+		if len(t.Title) > 0 {
+			m["title"] = t.Title
+		}
+		if len(m) > 0 {
+			o.StreamMetadata = append(o.StreamMetadata, StreamOption{Stream: ss, Value: m})
+		}
+	}
+	return
+}
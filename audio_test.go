@@ -0,0 +1,35 @@
+package astiffmpeg
+
+import (
+	"os/exec"
+	"reflect"
+	"testing"
+
+	"github.com/asticode/go-astikit"
+)
+
+func TestMultiAudioTracksOutputOptions(t *testing.T) {
+	o, err := MultiAudioTracksOutputOptions(CodecAAC, Number{Prefix: "k", Value: 128}, []AudioTrack{
+		{Default: true, Language: "eng", Title: "Original"},
+		{InputFileID: 1, Language: "fra"},
+		{InputFileID: 2, Language: "eng", Stream: &StreamSpecifier{Index: astikit.IntPtr(1), Type: StreamSpecifierTypeAudio}, Title: "Audio description"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmd := &exec.Cmd{}
+	if err = o.adaptCmd(cmd); err != nil {
+		t.Fatal(err)
+	}
+	e := []string{
+		"-map", "0:v:0", "-map", "0:a:0", "-map", "1:a:0", "-map", "2:a:1",
+		"-b:a", "128k", "-codec:v", "copy", "-codec:a", "aac",
+		"-disposition:a:0", "default", "-disposition:a:1", "0", "-disposition:a:2", "0",
+		"-metadata:s:a:0", "language=eng", "-metadata:s:a:0", "title=Original",
+		"-metadata:s:a:1", "language=fra",
+		"-metadata:s:a:2", "language=eng", "-metadata:s:a:2", "title=Audio description",
+	}
+	if !reflect.DeepEqual(e, cmd.Args) {
+		t.Errorf("expected %+v, got %+v", e, cmd.Args)
+	}
+}
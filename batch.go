@@ -0,0 +1,132 @@
+package astiffmpeg
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// JobSpec represents everything needed to execute ffmpeg once
+type JobSpec struct {
+	Global GlobalOptions
+	Inputs []Input
+	Output Output
+}
+
+// JobTemplate creates the job spec of a file
+type JobTemplate func(path string) (JobSpec, error)
+
+// BatchOptions represents batch options
+type BatchOptions struct {
+	// Number of jobs executed concurrently. Defaults to 1.
+	Concurrency int
+	// Only files whose base name matches one of these glob patterns (e.g. "*.mov") are processed. All files are
+	// processed when empty.
+	Patterns  []string
+	Recursive bool
+	Template  JobTemplate
+}
+
+// BatchReport represents the report of a file processed in a batch
+type BatchReport struct {
+	Duration time.Duration
+	Err      error
+	Path     string
+}
+
+// Batch walks the directory, creates a job for each matching file using the template and executes them
+// Reports are in lexical order of paths. A job failing doesn't stop the batch, its error is stored in its report.
+func (f *FFMpeg) Batch(ctx context.Context, dir string, o BatchOptions) (rs []BatchReport, err error) {
+	// List files
+	var ps []string
+	if ps, err = batchFiles(dir, o.Patterns, o.Recursive); err != nil {
+		err = fmt.Errorf("astiffmpeg: listing files failed: %w", err)
+		return
+	}
+
+	// Create reports
+	rs = make([]BatchReport, len(ps))
+	for idx, p := range ps {
+		rs[idx].Path = p
+	}
+
+	// Get concurrency
+	c := o.Concurrency
+	if c <= 0 {
+		c = 1
+	}
+
+	// Loop through files
+	wg := &sync.WaitGroup{}
+	sem := make(chan struct{}, c)
+	for idx := range rs {
+		// Acquire slot
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(r *BatchReport) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			// Context is done
+			if r.Err = ctx.Err(); r.Err != nil {
+				return
+			}
+
+			// Create job
+			s, err := o.Template(r.Path)
+			if err != nil {
+				r.Err = fmt.Errorf("astiffmpeg: creating job failed: %w", err)
+				return
+			}
+
+			// Exec
+			n := time.Now()
+			if err = f.Exec(ctx, s.Global, s.Inputs, s.Output); err != nil {
+				r.Err = fmt.Errorf("astiffmpeg: executing failed: %w", err)
+			}
+			r.Duration = time.Since(n)
+		}(&rs[idx])
+	}
+	wg.Wait()
+	return
+}
+
+func batchFiles(dir string, patterns []string, recursive bool) (ps []string, err error) {
+	err = filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		// Directory
+		if d.IsDir() {
+			if p != dir && !recursive {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		// Match patterns
+		if len(patterns) > 0 {
+			var match bool
+			for _, pattern := range patterns {
+				if ok, err := filepath.Match(pattern, d.Name()); err != nil {
+					return fmt.Errorf("astiffmpeg: matching pattern %s failed: %w", pattern, err)
+				} else if ok {
+					match = true
+					break
+				}
+			}
+			if !match {
+				return nil
+			}
+		}
+		ps = append(ps, p)
+		return nil
+	})
+	return
+}
@@ -0,0 +1,41 @@
+package astiffmpeg
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestBatchFiles(t *testing.T) {
+	d := t.TempDir()
+	if err := os.Mkdir(filepath.Join(d, "sub"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []string{"b.mov", "a.MOV", "c.txt", "sub/d.mov"} {
+		if err := os.WriteFile(filepath.Join(d, p), nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, v := range []struct {
+		e         []string
+		patterns  []string
+		recursive bool
+	}{
+		{e: []string{"a.MOV", "b.mov", "c.txt"}},
+		{e: []string{"a.MOV", "b.mov"}, patterns: []string{"*.mov", "*.MOV"}},
+		{e: []string{"b.mov", "sub/d.mov"}, patterns: []string{"*.mov"}, recursive: true},
+	} {
+		ps, err := batchFiles(d, v.patterns, v.recursive)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var e []string
+		for _, p := range v.e {
+			e = append(e, filepath.Join(d, p))
+		}
+		if !reflect.DeepEqual(e, ps) {
+			t.Errorf("expected %+v, got %+v", e, ps)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package astiffmpeg
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/asticode/go-astikit"
+)
+
+var (
+	benchmarkMaxRSSRegexp = regexp.MustCompile(`bench: maxrss=(\d+[a-zA-Z]*)`)
+	benchmarkTimesRegexp  = regexp.MustCompile(`bench: utime=([\d.]+)s stime=([\d.]+)s rtime=([\d.]+)s`)
+)
+
+// BenchmarkResults represents benchmark results
+type BenchmarkResults struct {
+	MaxRSS     *int // bytes
+	RealTime   time.Duration
+	SystemTime time.Duration
+	UserTime   time.Duration
+}
+
+// BenchmarkDecode decodes the input into a null output and returns benchmarking information
+func (f *FFMpeg) BenchmarkDecode(ctx context.Context, g GlobalOptions, in Input) (r BenchmarkResults, err error) {
+	// Start job
+	g.Benchmark = true
+	var j *Job
+	if j, err = f.ExecAsync(ctx, g, []Input{in}, NullOutput(nil)); err != nil {
+		err = fmt.Errorf("astiffmpeg: starting job failed: %w", err)
+		return
+	}
+
+	// Wait
+	if err = j.Wait(); err != nil {
+		err = fmt.Errorf("astiffmpeg: waiting for job failed: %w", err)
+		return
+	}
+
+	// Parse results
+	if r, err = parseBenchmarkResults(j.bufErr.Bytes()); err != nil {
+		err = fmt.Errorf("astiffmpeg: parsing benchmark results failed: %w", err)
+		return
+	}
+	return
+}
+
+func parseBenchmarkResults(b []byte) (r BenchmarkResults, err error) {
+	// Parse times
+	ms := benchmarkTimesRegexp.FindSubmatch(b)
+	if len(ms) < 4 {
+		err = fmt.Errorf("astiffmpeg: no benchmark times found in %s", b)
+		return
+	}
+	for idx, d := range []*time.Duration{&r.UserTime, &r.SystemTime, &r.RealTime} {
+		var f float64
+		if f, err = strconv.ParseFloat(string(ms[idx+1]), 64); err != nil {
+			err = fmt.Errorf("astiffmpeg: parsing float %s failed: %w", ms[idx+1], err)
+			return
+		}
+		*d = time.Duration(f * float64(time.Second))
+	}
+
+	// Parse max rss
+	if ms = benchmarkMaxRSSRegexp.FindSubmatch(b); len(ms) >= 2 {
+		if n, err := numberFromString(string(ms[1])); err == nil {
+			r.MaxRSS = astikit.IntPtr(int(n.float64() / 8))
+		}
+	}
+	return
+}
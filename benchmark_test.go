@@ -0,0 +1,29 @@
+package astiffmpeg
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/asticode/go-astikit"
+)
+
+func TestParseBenchmarkResults(t *testing.T) {
+	_, err := parseBenchmarkResults([]byte("invalid"))
+	if err == nil {
+		t.Error("expected error")
+	}
+	r, err := parseBenchmarkResults([]byte("frame= 250 fps=0.0\nbench: utime=1.500s stime=0.250s rtime=0.800s\nbench: maxrss=2KiB\n"))
+	if err != nil {
+		t.Errorf("expected no error, got %s", err.Error())
+	}
+	e := BenchmarkResults{
+		MaxRSS:     astikit.IntPtr(2048),
+		RealTime:   800 * time.Millisecond,
+		SystemTime: 250 * time.Millisecond,
+		UserTime:   1500 * time.Millisecond,
+	}
+	if !reflect.DeepEqual(e, r) {
+		t.Errorf("expected %+v, got %+v", e, r)
+	}
+}
@@ -0,0 +1,140 @@
+package astiffmpeg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// BoomerangOptions represents boomerang options
+type BoomerangOptions struct {
+	// Reverses the audio as well, otherwise audio is dropped
+	Audio bool
+	// Encoding options of the output. Defaults to H.264/AAC.
+	Encoding *EncodingOptions
+	// Used to estimate the number of frames of the input when its frame rate can't be probed. Defaults to 30.
+	Framerate float64
+	// Since reverse filters buffer the entire stream in memory, inputs with more frames are rejected. Defaults to 300.
+	MaxFrames int
+}
+
+// Boomerang creates a clip playing the input forward then backward
+// Since reversing requires buffering the whole input in memory, the input duration and frame rate are probed first
+// and inputs whose estimated number of frames exceeds MaxFrames are rejected
+func (f *FFMpeg) Boomerang(ctx context.Context, g GlobalOptions, in Input, o BoomerangOptions, outputPath string) (err error) {
+	// Default options
+	if o.Framerate <= 0 {
+		o.Framerate = 30
+	}
+	if o.MaxFrames <= 0 {
+		o.MaxFrames = 300
+	}
+	if o.Encoding == nil {
+		o.Encoding = &EncodingOptions{
+			Codec: []StreamOption{
+				videoStreamOption(CodecLibx264),
+				audioStreamOption(CodecAAC),
+			},
+			PixelFormat: PixelFormatYUV420P,
+		}
+	}
+
+	// Probe
+	var i InputInfo
+	if i, err = f.Probe(ctx, in); err != nil {
+		err = fmt.Errorf("astiffmpeg: probing failed: %w", err)
+		return
+	}
+
+	// Check number of frames
+	var n int
+	if n, err = boomerangFrames(i, o.Framerate); err != nil {
+		err = fmt.Errorf("astiffmpeg: estimating number of frames failed: %w", err)
+		return
+	} else if n > o.MaxFrames {
+		err = fmt.Errorf("astiffmpeg: input has an estimated %d frames which is more than the max %d", n, o.MaxFrames)
+		return
+	}
+
+	// Create output options
+	e := copyEncodingOptions(o.Encoding)
+	e.ComplexFilters = boomerangFilters(o.Audio)
+	oo := &OutputOptions{
+		Encoding: e,
+		Map:      &MapOptions{{Label: "v"}},
+	}
+	if o.Audio {
+		*oo.Map = append(*oo.Map, MapOption{Label: "a"})
+	}
+
+	// Exec
+	if err = f.Exec(ctx, g, []Input{in}, Output{Options: oo, Path: outputPath}); err != nil {
+		err = fmt.Errorf("astiffmpeg: executing failed: %w", err)
+		return
+	}
+	return
+}
+
+// boomerangFrames estimates the number of frames of the input based on its duration and the frame rate of its first
+// video stream, or the fallback frame rate if it can't be found
+func boomerangFrames(i InputInfo, fallback float64) (n int, err error) {
+	// No duration
+	if i.Duration == nil {
+		err = errors.New("astiffmpeg: no duration found")
+		return
+	}
+
+	// Get frame rate
+	fr := fallback
+	for _, s := range i.Streams {
+		if s.Type == StreamInfoTypeVideo {
+			if s.FPS != nil && *s.FPS > 0 {
+				fr = *s.FPS
+			}
+			break
+		}
+	}
+	n = int(math.Ceil(i.Duration.Seconds() * fr))
+	return
+}
+
+func boomerangFilters(audio bool) (cfs []ComplexFilterOption) {
+	cfs = []ComplexFilterOption{
+		{
+			Filters:       []string{"split"},
+			InputStreams:  []StreamSpecifier{{Name: "0:v"}},
+			OutputStreams: []StreamSpecifier{{Name: "vf"}, {Name: "vr"}},
+		},
+		{
+			Filters:       []string{"reverse"},
+			InputStreams:  []StreamSpecifier{{Name: "vr"}},
+			OutputStreams: []StreamSpecifier{{Name: "vrr"}},
+		},
+		{
+			Filters:       []string{"concat=n=2:v=1:a=0"},
+			InputStreams:  []StreamSpecifier{{Name: "vf"}, {Name: "vrr"}},
+			OutputStreams: []StreamSpecifier{{Name: "v"}},
+		},
+	}
+	if audio {
+		cfs = append(cfs,
+			ComplexFilterOption{
+				Filters:       []string{"asplit"},
+				InputStreams:  []StreamSpecifier{{Name: "0:a"}},
+				OutputStreams: []StreamSpecifier{{Name: "af"}, {Name: "ar"}},
+			},
+			ComplexFilterOption{
+				Filters:       []string{"areverse"},
+				InputStreams:  []StreamSpecifier{{Name: "ar"}},
+				OutputStreams: []StreamSpecifier{{Name: "arr"}},
+			},
+			ComplexFilterOption{
+				Filters:       []string{"concat=n=2:v=0:a=1"},
+				InputStreams:  []StreamSpecifier{{Name: "af"}, {Name: "arr"}},
+				OutputStreams: []StreamSpecifier{{Name: "a"}},
+			},
+		)
+	}
+	return
+}
@@ -0,0 +1,32 @@
+package astiffmpeg
+
+import (
+	"testing"
+	"time"
+
+	"github.com/asticode/go-astikit"
+)
+
+func TestBoomerangFrames(t *testing.T) {
+	for _, v := range []struct {
+		e int
+		i InputInfo
+	}{
+		{e: 600, i: InputInfo{Duration: astikit.DurationPtr(10 * time.Second), Streams: []StreamInfo{
+			{Type: StreamInfoTypeAudio},
+			{FPS: astikit.Float64Ptr(60), Type: StreamInfoTypeVideo},
+		}}},
+		{e: 300, i: InputInfo{Duration: astikit.DurationPtr(10 * time.Second), Streams: []StreamInfo{{Type: StreamInfoTypeVideo}}}},
+	} {
+		n, err := boomerangFrames(v.i, 30)
+		if err != nil {
+			t.Fatalf("expected no error, got %s", err)
+		}
+		if n != v.e {
+			t.Errorf("expected %d, got %d", v.e, n)
+		}
+	}
+	if _, err := boomerangFrames(InputInfo{}, 30); err == nil {
+		t.Error("expected error")
+	}
+}
@@ -0,0 +1,59 @@
+package astiffmpeg
+
+import (
+	"bytes"
+	"sync"
+)
+
+// Stderr of long running jobs (e.g. 24/7 live streams) grows without limit, therefore only its first bytes, which
+// contain the banner, and its last bytes, which contain the latest logs and stats, are kept
+const (
+	syncBufferHeadSize = 64 * 1024
+	syncBufferTailSize = 1024 * 1024
+)
+
+// syncBuffer is a buffer that can be written by the cmd while being read by stderr parsers
+type syncBuffer struct {
+	b    bytes.Buffer
+	head []byte // Only set once bytes have started being dropped
+	m    sync.Mutex
+}
+
+func (b *syncBuffer) Write(p []byte) (n int, err error) {
+	b.m.Lock()
+	defer b.m.Unlock()
+
+	// Write
+	if n, err = b.b.Write(p); err != nil {
+		return
+	}
+
+	// Buffer is not full
+	max := syncBufferTailSize
+	if b.head == nil {
+		max += syncBufferHeadSize
+	}
+	if b.b.Len() <= max {
+		return
+	}
+
+	// Keep head
+	if b.head == nil {
+		b.head = append([]byte{}, b.b.Next(syncBufferHeadSize)...)
+	}
+
+	// Drop oldest lines so that parsers are not provided with truncated lines
+	d := b.b.Len() - syncBufferTailSize
+	if idx := bytes.IndexByte(b.b.Bytes()[d:], '\n'); idx >= 0 {
+		d += idx + 1
+	}
+	b.b.Next(d)
+	return
+}
+
+// Bytes returns a copy of the buffer content
+func (b *syncBuffer) Bytes() []byte {
+	b.m.Lock()
+	defer b.m.Unlock()
+	return append(append([]byte{}, b.head...), b.b.Bytes()...)
+}
@@ -0,0 +1,37 @@
+package astiffmpeg
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSyncBuffer(t *testing.T) {
+	// Buffer is not full
+	b := &syncBuffer{}
+	b.Write([]byte("banner\n"))
+	if e, g := "banner\n", string(b.Bytes()); e != g {
+		t.Errorf("expected %s, got %s", e, g)
+	}
+
+	// Head and tail are kept
+	head := bytes.Repeat([]byte("h"), syncBufferHeadSize-len("banner\n"))
+	line := append(bytes.Repeat([]byte("l"), 1023), '\n')
+	b.Write(head)
+	for idx := 0; idx < 2*syncBufferTailSize/len(line); idx++ {
+		b.Write(line)
+	}
+	b.Write([]byte("last\n"))
+	g := b.Bytes()
+	if len(g) > syncBufferHeadSize+syncBufferTailSize {
+		t.Errorf("expected at most %d bytes, got %d", syncBufferHeadSize+syncBufferTailSize, len(g))
+	}
+	if !bytes.HasPrefix(g, append([]byte("banner\n"), head...)) {
+		t.Error("expected head to be kept")
+	}
+	if !bytes.HasSuffix(g, append(line, []byte("last\n")...)) {
+		t.Error("expected tail to be kept")
+	}
+	if (len(g)-syncBufferHeadSize)%len(line) != len("last\n") {
+		t.Error("expected only complete lines to be kept")
+	}
+}
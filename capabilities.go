@@ -0,0 +1,235 @@
+package astiffmpeg
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// ErrFilterNotAvailable is returned when a filter is not available in the ffmpeg build
+var ErrFilterNotAvailable = errors.New("astiffmpeg: filter not available")
+
+// ErrMuxerNotAvailable is returned when a muxer is not available in the ffmpeg build
+var ErrMuxerNotAvailable = errors.New("astiffmpeg: muxer not available")
+
+// capabilities lists and caches the capabilities (filters, muxers, ...) of the ffmpeg build
+type capabilities struct {
+	flag  string
+	m     *sync.Mutex
+	names map[string]bool
+	parse func(b []byte) map[string]bool
+}
+
+func newCapabilities(flag string, parse func(b []byte) map[string]bool) *capabilities {
+	return &capabilities{
+		flag:  flag,
+		m:     &sync.Mutex{},
+		parse: parse,
+	}
+}
+
+func (c *capabilities) list(ctx context.Context, binaryPath string) (names map[string]bool, err error) {
+	// Lock
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	// Already listed
+	if c.names != nil {
+		names = c.names
+		return
+	}
+
+	// Check binary path
+	if err = checkBinaryPath(binaryPath); err != nil {
+		return
+	}
+
+	// Create cmd
+	var cmd = exec.CommandContext(ctx, binaryPath, "-hide_banner", c.flag)
+	cmd.Env = os.Environ()
+	var bufErr = &bytes.Buffer{}
+	cmd.Stderr = bufErr
+
+	// Run cmd
+	var b []byte
+	if b, err = cmd.Output(); err != nil {
+		err = fmt.Errorf("astiffmpeg: running %s failed with stderr %s: %w", cmd.String(), bufErr.Bytes(), err)
+		return
+	}
+
+	// Parse
+	c.names = c.parse(b)
+	names = c.names
+	return
+}
+
+var filtersLineRegexp = regexp.MustCompile(`^\s*[T.][S.][C.]?\s+(\S+)\s+\S*->\S*\s`)
+
+// T.. = Timeline support
+// ...
+// T.C acompressor       A->A       Audio compressor.
+func parseFilters(b []byte) map[string]bool {
+	names := make(map[string]bool)
+	for _, l := range bytes.Split(b, []byte("\n")) {
+		if ms := filtersLineRegexp.FindSubmatch(l); len(ms) >= 2 {
+			names[string(ms[1])] = true
+		}
+	}
+	return names
+}
+
+// Filters returns the names of the filters available in the ffmpeg build
+func (f *FFMpeg) Filters(ctx context.Context) (names []string, err error) {
+	// List
+	var m map[string]bool
+	if m, err = f.filters.list(ctx, f.binaryPath); err != nil {
+		err = fmt.Errorf("astiffmpeg: listing filters failed: %w", err)
+		return
+	}
+
+	// Convert
+	for n := range m {
+		names = append(names, n)
+	}
+	return
+}
+
+var muxersLineRegexp = regexp.MustCompile(`^ [D ]E[d ]? +([\w,]+) `)
+
+// .E = Muxing supported
+// ...
+// DE matroska,webm  Matroska / WebM
+func parseMuxers(b []byte) map[string]bool {
+	names := make(map[string]bool)
+	for _, l := range bytes.Split(b, []byte("\n")) {
+		if ms := muxersLineRegexp.FindSubmatch(l); len(ms) >= 2 {
+			for _, n := range strings.Split(string(ms[1]), ",") {
+				names[n] = true
+			}
+		}
+	}
+	return names
+}
+
+// Muxers returns the names of the muxers available in the ffmpeg build
+func (f *FFMpeg) Muxers(ctx context.Context) (names []string, err error) {
+	// List
+	var m map[string]bool
+	if m, err = f.muxers.list(ctx, f.binaryPath); err != nil {
+		err = fmt.Errorf("astiffmpeg: listing muxers failed: %w", err)
+		return
+	}
+
+	// Convert
+	for n := range m {
+		names = append(names, n)
+	}
+	return
+}
+
+// CheckMuxer returns an error wrapping ErrMuxerNotAvailable if the muxer is not available in the ffmpeg build
+func (f *FFMpeg) CheckMuxer(ctx context.Context, name string) (err error) {
+	// List
+	var m map[string]bool
+	if m, err = f.muxers.list(ctx, f.binaryPath); err != nil {
+		err = fmt.Errorf("astiffmpeg: listing muxers failed: %w", err)
+		return
+	}
+
+	// Check
+	if !m[name] {
+		err = fmt.Errorf("%w: %s is not available in this ffmpeg build", ErrMuxerNotAvailable, name)
+		return
+	}
+	return
+}
+
+func (f *FFMpeg) checkFiltersAvailable(ctx context.Context, args []string) (err error) {
+	// Get filter names
+	var ns []string
+	for idx := 0; idx < len(args)-1; idx++ {
+		if a := args[idx]; a == "-filter_complex" || a == "-lavfi" || a == "-vf" || a == "-af" || a == "-filter" || strings.HasPrefix(a, "-filter:") {
+			ns = append(ns, filterNamesFromGraph(args[idx+1])...)
+		}
+	}
+	if len(ns) == 0 {
+		return
+	}
+
+	// List available filters
+	var m map[string]bool
+	if m, err = f.filters.list(ctx, f.binaryPath); err != nil {
+		err = fmt.Errorf("astiffmpeg: listing filters failed: %w", err)
+		return
+	}
+
+	// Check
+	for _, n := range ns {
+		if !m[n] {
+			err = fmt.Errorf("%w: %s is not available in this ffmpeg build", ErrFilterNotAvailable, n)
+			return
+		}
+	}
+	return
+}
+
+// [0:v]scale=w=1280:h=-1,drawtext@title=text='a\,b'[v];[v]split[a][b]
+// Instance names (e.g. "@title") are not part of the filter name, and the graph may start with scaler flags (e.g.
+// "sws_flags=bicubic;") which are not a filter
+func filterNamesFromGraph(g string) (names []string) {
+	if t := strings.TrimLeft(g, " \n"); strings.HasPrefix(t, "sws_flags=") {
+		if idx := strings.IndexByte(t, ';'); idx >= 0 {
+			g = t[idx+1:]
+		} else {
+			return
+		}
+	}
+
+	var inLabel, inQuotes bool
+	var name []byte
+	isName := true
+	for idx := 0; idx < len(g); idx++ {
+		c := g[idx]
+		switch {
+		case c == '\\':
+			idx++
+			continue
+		case c == '\'':
+			inQuotes = !inQuotes
+			continue
+		case inQuotes:
+			continue
+		case c == '[':
+			inLabel = true
+			continue
+		case c == ']':
+			inLabel = false
+			continue
+		case inLabel:
+			continue
+		case c == ',' || c == ';':
+			if len(name) > 0 {
+				names = append(names, string(name))
+			}
+			name = name[:0]
+			isName = true
+			continue
+		case c == '=' || c == '@':
+			isName = false
+			continue
+		}
+		if isName && c != ' ' && c != '\n' {
+			name = append(name, c)
+		}
+	}
+	if len(name) > 0 {
+		names = append(names, string(name))
+	}
+	return
+}
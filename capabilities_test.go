@@ -0,0 +1,53 @@
+package astiffmpeg
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseFilters(t *testing.T) {
+	m := parseFilters([]byte(`Filters:
+  T.. = Timeline support
+  .S. = Slice threading
+  ..C = Command support
+  A = Audio input/output
+  V = Video input/output
+  N = Dynamic number and/or type of input/output
+  | = Source or sink filter
+ T.C acompressor       A->A       Audio compressor.
+ ... abuffersink       A->|       Buffer audio frames, and make them available to the end of the filter graph.
+ .S. scale             V->V       Scale the input video size and/or convert the image format.
+`))
+	e := map[string]bool{"abuffersink": true, "acompressor": true, "scale": true}
+	if !reflect.DeepEqual(e, m) {
+		t.Errorf("expected %+v, got %+v", e, m)
+	}
+}
+
+func TestFilterNamesFromGraph(t *testing.T) {
+	e := []string{"scale", "drawtext", "split", "hflip"}
+	if g := filterNamesFromGraph(`[0:v]scale=w=1280:h=-1,drawtext@title=text='a,b;c=d'\,e[v];[v]split[a][b];[a]hflip`); !reflect.DeepEqual(e, g) {
+		t.Errorf("expected %+v, got %+v", e, g)
+	}
+	e = []string{"scale"}
+	if g := filterNamesFromGraph(`sws_flags=bicubic+accurate_rnd;[0:v]scale=w=1280:h=-1`); !reflect.DeepEqual(e, g) {
+		t.Errorf("expected %+v, got %+v", e, g)
+	}
+}
+
+func TestParseMuxers(t *testing.T) {
+	m := parseMuxers([]byte(`File formats:
+ D. = Demuxing supported
+ .E = Muxing supported
+ ..d = Is a device
+ ---
+  E 3g2             3GP2 (3GPP2 file format)
+ D  aac             raw ADTS AAC (Advanced Audio Coding)
+ DE matroska,webm   Matroska / WebM
+  E whip            WHIP(WebRTC-HTTP ingestion protocol) muxer
+`))
+	e := map[string]bool{"3g2": true, "matroska": true, "webm": true, "whip": true}
+	if !reflect.DeepEqual(e, m) {
+		t.Errorf("expected %+v, got %+v", e, m)
+	}
+}
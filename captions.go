@@ -0,0 +1,65 @@
+package astiffmpeg
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/asticode/go-astikit"
+)
+
+// Bitstream filters removing SEI NAL units, and therefore embedded A53/CEA-608 closed captions, while stream copying
+const (
+	BitstreamFilterRemoveH264SEI = "filter_units=remove_types=6"
+	BitstreamFilterRemoveHEVCSEI = "filter_units=remove_types=39|40"
+)
+
+// ExtractCaptions extracts closed captions embedded in the video of the input to a sidecar file whose format is
+// guessed from its extension (e.g. "captions.srt" or "captions.vtt")
+// Captions are read through the "subcc" output of the movie filter, which means input options are not supported.
+func (f *FFMpeg) ExtractCaptions(ctx context.Context, g GlobalOptions, inputPath, outputPath string) (err error) {
+	// Exec
+	if err = f.Exec(ctx, g, []Input{{
+		Options: &InputOptions{Format: "lavfi"},
+		Path:    GenericFilter{Name: "movie", Ordered: []KV{{Value: inputPath}}}.String() + "[out+subcc]",
+	}}, Output{
+		Options: &OutputOptions{Map: &MapOptions{{Stream: &StreamSpecifier{Type: StreamSpecifierTypeSubtitle}}}},
+		Path:    outputPath,
+	}); err != nil {
+		err = fmt.Errorf("astiffmpeg: executing failed: %w", err)
+		return
+	}
+	return
+}
+
+// ExtractTeletext decodes teletext subtitles of the specified pages (e.g. "888") to an SRT sidecar file
+// DVB bitmap subtitles can't be converted to text without OCR, they can only be stream copied or burnt in.
+func (f *FFMpeg) ExtractTeletext(ctx context.Context, g GlobalOptions, in Input, page, outputPath string) (err error) {
+	// Update input
+	o := &InputOptions{}
+	if in.Options != nil {
+		*o = *in.Options
+	}
+	d := &DecodingOptions{}
+	if o.Decoding != nil {
+		*d = *o.Decoding
+	}
+	d.Decoders = append(append([]StreamOption{}, d.Decoders...), StreamOption{Stream: &StreamSpecifier{Type: StreamSpecifierTypeSubtitle}, Value: CodecLibzvbiTeletext})
+	d.TeletextFormat = TeletextFormatText
+	d.TeletextPage = page
+	o.Decoding = d
+	in.Options = o
+
+	// Exec
+	if err = f.Exec(ctx, g, []Input{in}, Output{
+		Options: &OutputOptions{
+			Encoding: &EncodingOptions{Codec: []StreamOption{{Stream: &StreamSpecifier{Type: StreamSpecifierTypeSubtitle}, Value: CodecSRT}}},
+			Format:   "srt",
+			Map:      &MapOptions{{Stream: &StreamSpecifier{Index: astikit.IntPtr(0), Type: StreamSpecifierTypeSubtitle}}},
+		},
+		Path: outputPath,
+	}); err != nil {
+		err = fmt.Errorf("astiffmpeg: executing failed: %w", err)
+		return
+	}
+	return
+}
@@ -0,0 +1,86 @@
+package astiffmpeg
+
+import (
+	"context"
+	"fmt"
+)
+
+// CFR methods
+const (
+	// Frames are dropped or duplicated by the fps filter, which allows chaining other filters at the target frame
+	// rate. This is the default.
+	CFRMethodFPSFilter = "fps_filter"
+	// Frames are dropped or duplicated by the muxing logic with -fps_mode cfr and -r (ffmpeg >= 5.1)
+	CFRMethodFPSMode = "fps_mode"
+)
+
+// CFROptions represents CFR options
+type CFROptions struct {
+	// Defaults to the frame rate of the input
+	FPS Rational
+	// See CFRMethod constants
+	Method string
+	// Output options, which shouldn't contain audio or video filters. Defaults to libx264 and aac.
+	Options *OutputOptions
+}
+
+// ForceCFR converts a VFR input (e.g. phone or screen recordings) to a CFR output, which is what editors and most
+// ML pipelines expect
+// Audio is resampled to match its timestamps so that it stays in sync with the video. See DetectVFR to know whether
+// an input needs it.
+func (f *FFMpeg) ForceCFR(ctx context.Context, g GlobalOptions, in Input, o CFROptions, outputPath string) (err error) {
+	// Get frame rate
+	if o.FPS.IsZero() {
+		if o.FPS, err = f.FrameRate(ctx, in); err != nil {
+			err = fmt.Errorf("astiffmpeg: getting frame rate failed: %w", err)
+			return
+		}
+	}
+
+	// Exec
+	if err = f.Exec(ctx, g, []Input{in}, Output{
+		Options: cfrOutputOptions(o),
+		Path:    outputPath,
+	}); err != nil {
+		err = fmt.Errorf("astiffmpeg: executing failed: %w", err)
+		return
+	}
+	return
+}
+
+func cfrOutputOptions(o CFROptions) *OutputOptions {
+	// Copy options
+	oo := OutputOptions{}
+	if o.Options != nil {
+		oo = *o.Options
+	}
+	e := EncodingOptions{Codec: []StreamOption{
+		videoStreamOption(CodecLibx264),
+		audioStreamOption(CodecAAC),
+	}}
+	if oo.Encoding != nil {
+		e = *oo.Encoding
+	}
+
+	// Audio is stretched or squeezed to match its timestamps
+	e.Filters = append(append([]StreamOption{}, e.Filters...), audioStreamOption(FilterOptions{Generic: []GenericFilter{{
+		Args: map[string]string{"async": "1", "first_pts": "0"},
+		Name: "aresample",
+	}}}))
+
+	// Video
+	switch o.Method {
+	case CFRMethodFPSMode:
+		// Framerate is rounded to 3 decimals, which is not accurate enough for NTSC frame rates
+		e.Framerate = nil
+		oo.FPSMode = FPSModeCFR
+		oo.Raw = append([]string{"-r", o.FPS.String()}, oo.Raw...)
+	default:
+		e.Filters = append(e.Filters, videoStreamOption(FilterOptions{Generic: []GenericFilter{{
+			Args: map[string]string{"fps": o.FPS.String()},
+			Name: "fps",
+		}}}))
+	}
+	oo.Encoding = &e
+	return &oo
+}
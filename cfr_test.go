@@ -0,0 +1,27 @@
+package astiffmpeg
+
+import (
+	"os/exec"
+	"reflect"
+	"testing"
+)
+
+func TestCFROutputOptions(t *testing.T) {
+	cmd := exec.Command("ffmpeg")
+	if err := cfrOutputOptions(CFROptions{FPS: Rational{Den: 1, Num: 30}}).adaptCmd(cmd); err != nil {
+		t.Errorf("expected no error, got %s", err)
+	}
+	e := []string{"ffmpeg", "-codec:v", "libx264", "-codec:a", "aac", "-filter:a", "aresample=async=1:first_pts=0", "-filter:v", "fps=fps=30/1"}
+	if !reflect.DeepEqual(e, cmd.Args) {
+		t.Errorf("expected %+v, got %+v", e, cmd.Args)
+	}
+
+	cmd = exec.Command("ffmpeg")
+	if err := cfrOutputOptions(CFROptions{FPS: Rational{Den: 1001, Num: 30000}, Method: CFRMethodFPSMode}).adaptCmd(cmd); err != nil {
+		t.Errorf("expected no error, got %s", err)
+	}
+	e = []string{"ffmpeg", "-codec:v", "libx264", "-codec:a", "aac", "-filter:a", "aresample=async=1:first_pts=0", "-fps_mode", "cfr", "-r", "30000/1001"}
+	if !reflect.DeepEqual(e, cmd.Args) {
+		t.Errorf("expected %+v, got %+v", e, cmd.Args)
+	}
+}
@@ -0,0 +1,181 @@
+package astiffmpeg
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/asticode/go-astikit"
+)
+
+// Chapter represents a chapter of a cue list
+type Chapter struct {
+	End   time.Duration
+	Start time.Duration
+	Title string
+}
+
+// WriteFFMetadataFile writes chapters and global metadata in the ffmetadata format, which can then be used as an
+// input to create chapters (see OutputOptions.MapChapters)
+func WriteFFMetadataFile(path string, chapters []Chapter, metadata map[string]string) (err error) {
+	// Create content
+	var b []byte
+	if b, err = ffmetadata(chapters, metadata); err != nil {
+		err = fmt.Errorf("astiffmpeg: creating ffmetadata failed: %w", err)
+		return
+	}
+
+	// Write
+	if err = os.WriteFile(path, b, 0600); err != nil {
+		err = fmt.Errorf("astiffmpeg: writing ffmetadata to %s failed: %w", path, err)
+		return
+	}
+	return
+}
+
+func ffmetadata(chapters []Chapter, metadata map[string]string) (b []byte, err error) {
+	// Header
+	buf := &bytes.Buffer{}
+	buf.WriteString(";FFMETADATA1\n")
+
+	// Global metadata
+	var ks []string
+	for k := range metadata {
+		ks = append(ks, k)
+	}
+	sort.Strings(ks)
+	for _, k := range ks {
+		buf.WriteString(escapeFFMetadata(k) + "=" + escapeFFMetadata(metadata[k]) + "\n")
+	}
+
+	// Chapters
+	for idx, c := range chapters {
+		// Invalid chapter
+		if c.End <= c.Start {
+			err = fmt.Errorf("astiffmpeg: chapter #%d ends before it starts", idx)
+			return
+		}
+
+		// Write
+		buf.WriteString("\n[CHAPTER]\nTIMEBASE=1/1000\n")
+		buf.WriteString("START=" + strconv.FormatInt(c.Start.Milliseconds(), 10) + "\n")
+		buf.WriteString("END=" + strconv.FormatInt(c.End.Milliseconds(), 10) + "\n")
+		if len(c.Title) > 0 {
+			buf.WriteString("title=" + escapeFFMetadata(c.Title) + "\n")
+		}
+	}
+	b = buf.Bytes()
+	return
+}
+
+var ffmetadataReplacer = strings.NewReplacer(`\`, `\\`, "=", `\=`, ";", `\;`, "#", `\#`, "\n", "\\\n")
+
+func escapeFFMetadata(v string) string {
+	return ffmetadataReplacer.Replace(v)
+}
+
+// AddChapters copies the streams of the input into the output while replacing its chapters
+// Chapters are written to a temporary ffmetadata file which is used as a second input.
+func (f *FFMpeg) AddChapters(ctx context.Context, g GlobalOptions, in Input, chapters []Chapter, outputPath string) (err error) {
+	// Create temporary file
+	var tf *os.File
+	if tf, err = os.CreateTemp("", "astiffmpeg-chapters-*.txt"); err != nil {
+		err = fmt.Errorf("astiffmpeg: creating temporary file failed: %w", err)
+		return
+	}
+	tf.Close()
+	defer os.Remove(tf.Name())
+
+	// Write chapters
+	if err = WriteFFMetadataFile(tf.Name(), chapters, nil); err != nil {
+		err = fmt.Errorf("astiffmpeg: writing chapters failed: %w", err)
+		return
+	}
+
+	// Exec
+	if err = f.Exec(ctx, g, []Input{in, {
+		Options: &InputOptions{Format: "ffmetadata"},
+		Path:    tf.Name(),
+	}}, Output{
+		Options: addChaptersOutputOptions(),
+		Path:    outputPath,
+	}); err != nil {
+		err = fmt.Errorf("astiffmpeg: executing failed: %w", err)
+		return
+	}
+	return
+}
+
+func addChaptersOutputOptions() *OutputOptions {
+	return &OutputOptions{
+		Encoding:    &EncodingOptions{Codec: []StreamOption{{Value: CodecCopy}}},
+		Map:         &MapOptions{{InputFileID: 0}},
+		MapChapters: astikit.IntPtr(1),
+	}
+}
+
+// SplitChapters splits the input into one file per chapter with stream copy, in a single ffmpeg invocation, and
+// returns the produced paths
+// Output path must be a sequence pattern such as "chapter-%02d.mkv", starting at 1. Since streams are copied, cuts
+// are only accurate to the closest keyframe.
+func (f *FFMpeg) SplitChapters(ctx context.Context, g GlobalOptions, in Input, chapters []Chapter, outputPath string) (paths []string, err error) {
+	// Create outputs
+	var outs []Output
+	if outs, err = splitChaptersOutputs(chapters, outputPath); err != nil {
+		err = fmt.Errorf("astiffmpeg: creating outputs failed: %w", err)
+		return
+	}
+
+	// Exec
+	if err = f.ExecWithOptions(ctx, g, []Input{in}, outs[0], ExecOptions{Outputs: outs[1:]}); err != nil {
+		err = fmt.Errorf("astiffmpeg: executing failed: %w", err)
+		return
+	}
+
+	// Get paths
+	for _, o := range outs {
+		paths = append(paths, o.Path)
+	}
+	return
+}
+
+func splitChaptersOutputs(chapters []Chapter, outputPath string) (outs []Output, err error) {
+	// Check output path
+	if !strings.Contains(outputPath, "%") {
+		err = errors.New("astiffmpeg: output path should be a sequence pattern")
+		return
+	}
+
+	// Create clips
+	var cs []Clip
+	for idx, c := range chapters {
+		cs = append(cs, Clip{
+			End:   c.End,
+			Path:  fmt.Sprintf(outputPath, idx+1),
+			Start: c.Start,
+		})
+	}
+
+	// Create outputs
+	if outs, err = clipsOutputs(cs, OutputOptions{
+		Encoding:    &EncodingOptions{Codec: []StreamOption{{Value: CodecCopy}}},
+		MapChapters: astikit.IntPtr(-1),
+	}); err != nil {
+		err = fmt.Errorf("astiffmpeg: creating clips outputs failed: %w", err)
+		return
+	}
+
+	// Add titles
+	for idx, c := range chapters {
+		if len(c.Title) > 0 {
+			outs[idx].Options.Metadata = map[string]string{"title": c.Title}
+		}
+	}
+	return
+}
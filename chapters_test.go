@@ -0,0 +1,53 @@
+package astiffmpeg
+
+import (
+	"os/exec"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFFMetadata(t *testing.T) {
+	if _, err := ffmetadata([]Chapter{{End: time.Second, Start: 2 * time.Second}}, nil); err == nil {
+		t.Error("expected error")
+	}
+	b, err := ffmetadata([]Chapter{
+		{End: 90 * time.Second, Title: "Intro"},
+		{End: 3 * time.Minute, Start: 90 * time.Second, Title: "Part 1; a=b"},
+	}, map[string]string{"title": "Show", "artist": "Me"})
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	e := ";FFMETADATA1\nartist=Me\ntitle=Show\n" +
+		"\n[CHAPTER]\nTIMEBASE=1/1000\nSTART=0\nEND=90000\ntitle=Intro\n" +
+		"\n[CHAPTER]\nTIMEBASE=1/1000\nSTART=90000\nEND=180000\ntitle=Part 1\\; a\\=b\n"
+	if string(b) != e {
+		t.Errorf("expected %q, got %q", e, b)
+	}
+}
+
+func TestSplitChaptersOutputs(t *testing.T) {
+	if _, err := splitChaptersOutputs([]Chapter{{End: time.Second}}, "chapter.mkv"); err == nil {
+		t.Error("expected error")
+	}
+	outs, err := splitChaptersOutputs([]Chapter{
+		{End: 90 * time.Second, Title: "Intro"},
+		{End: 3 * time.Minute, Start: 90 * time.Second},
+	}, "chapter-%02d.mkv")
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	cmd := exec.Command("ffmpeg")
+	for _, o := range outs {
+		if err = o.adaptCmd(cmd); err != nil {
+			t.Errorf("expected no error, got %s", err)
+		}
+	}
+	e := []string{"ffmpeg",
+		"-map_chapters", "-1", "-codec", "copy", "-t", "90.000", "-metadata", "title=Intro", "chapter-01.mkv",
+		"-map_chapters", "-1", "-codec", "copy", "-t", "90.000", "-ss", "90.000", "chapter-02.mkv",
+	}
+	if !reflect.DeepEqual(e, cmd.Args) {
+		t.Errorf("expected %+v, got %+v", e, cmd.Args)
+	}
+}
@@ -0,0 +1,63 @@
+package astiffmpeg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Clip represents a clip extracted from an input
+type Clip struct {
+	End   time.Duration
+	Path  string
+	Start time.Duration
+}
+
+// ExtractClips extracts several clips from the input in a single ffmpeg invocation, which is far faster than
+// spawning one process per clip since the input is read and decoded only once
+// Each clip is a separate output using output side seeking, and therefore is frame accurate when transcoding. When
+// stream copying (e.g. with CodecCopy), clips snap to keyframes: they start at the first keyframe following Start
+// since the frames before it can't be decoded. Output options are shared by all clips.
+func (f *FFMpeg) ExtractClips(ctx context.Context, g GlobalOptions, in Input, clips []Clip, o OutputOptions) (err error) {
+	// Create outputs
+	var outs []Output
+	if outs, err = clipsOutputs(clips, o); err != nil {
+		err = fmt.Errorf("astiffmpeg: creating outputs failed: %w", err)
+		return
+	}
+
+	// Exec
+	if err = f.ExecWithOptions(ctx, g, []Input{in}, outs[0], ExecOptions{Outputs: outs[1:]}); err != nil {
+		err = fmt.Errorf("astiffmpeg: executing failed: %w", err)
+		return
+	}
+	return
+}
+
+func clipsOutputs(clips []Clip, o OutputOptions) (outs []Output, err error) {
+	// No clips
+	if len(clips) == 0 {
+		err = errors.New("astiffmpeg: no clips provided")
+		return
+	}
+
+	// Loop through clips
+	for idx, c := range clips {
+		// Invalid clip
+		if c.End <= c.Start {
+			err = fmt.Errorf("astiffmpeg: clip #%d ends before it starts", idx)
+			return
+		}
+
+		// Create output
+		co := o
+		co.Duration = c.End - c.Start
+		co.Position = c.Start
+		outs = append(outs, Output{
+			Options: &co,
+			Path:    c.Path,
+		})
+	}
+	return
+}
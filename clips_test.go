@@ -0,0 +1,34 @@
+package astiffmpeg
+
+import (
+	"os/exec"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestClipsOutputs(t *testing.T) {
+	if _, err := clipsOutputs([]Clip{{End: time.Second, Start: 2 * time.Second}}, OutputOptions{}); err == nil {
+		t.Error("expected error")
+	}
+	outs, err := clipsOutputs([]Clip{
+		{End: 5 * time.Second, Path: "clip-1.mkv", Start: 2 * time.Second},
+		{End: time.Minute, Path: "clip-2.mkv", Start: 50 * time.Second},
+	}, OutputOptions{NoAudio: true})
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	cmd := exec.Command("ffmpeg")
+	for _, o := range outs {
+		if err = o.adaptCmd(cmd); err != nil {
+			t.Errorf("expected no error, got %s", err)
+		}
+	}
+	e := []string{"ffmpeg",
+		"-t", "3.000", "-an", "-ss", "2.000", "clip-1.mkv",
+		"-t", "10.000", "-an", "-ss", "50.000", "clip-2.mkv",
+	}
+	if !reflect.DeepEqual(e, cmd.Args) {
+		t.Errorf("expected %+v, got %+v", e, cmd.Args)
+	}
+}
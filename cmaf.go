@@ -0,0 +1,103 @@
+package astiffmpeg
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/asticode/go-astikit"
+)
+
+// CMAFOptions represents CMAF packaging options
+type CMAFOptions struct {
+	// Segments are encrypted when set
+	CENC *CENCOptions
+	// Defaults to H.264/AAC
+	Encoding *EncodingOptions
+	// Defaults to 4s
+	SegmentDuration time.Duration
+}
+
+// CMAF output names
+const (
+	CMAFHLSMasterName = "master.m3u8"
+	CMAFMPDName       = "manifest.mpd"
+)
+
+// PackageCMAF encodes the input once into fMP4 segments referenced by both a DASH MPD (CMAFMPDName) and an HLS
+// master playlist (CMAFHLSMasterName), written in the output directory
+// Keyframes are forced at segment boundaries so that segments are aligned.
+func (f *FFMpeg) PackageCMAF(ctx context.Context, g GlobalOptions, in Input, o CMAFOptions, outputDir string) (err error) {
+	// Check encryption
+	if o.CENC != nil {
+		if err = o.CENC.validate(); err != nil {
+			err = fmt.Errorf("astiffmpeg: validating cenc options failed: %w", err)
+			return
+		}
+	}
+
+	// Create output directory
+	if err = os.MkdirAll(outputDir, 0755); err != nil {
+		err = fmt.Errorf("astiffmpeg: mkdirall %s failed: %w", outputDir, err)
+		return
+	}
+
+	// Exec
+	if err = f.Exec(ctx, g, []Input{in}, Output{
+		Options: cmafOutputOptions(o),
+		Path:    filepath.Join(outputDir, CMAFMPDName),
+	}); err != nil {
+		err = fmt.Errorf("astiffmpeg: executing failed: %w", err)
+		return
+	}
+	return
+}
+
+func cmafOutputOptions(o CMAFOptions) *OutputOptions {
+	// Default options
+	if o.SegmentDuration <= 0 {
+		o.SegmentDuration = 4 * time.Second
+	}
+	e := copyEncodingOptions(o.Encoding)
+	if o.Encoding == nil {
+		e.Codec = []StreamOption{
+			videoStreamOption(CodecLibx264),
+			audioStreamOption(CodecAAC),
+		}
+		e.PixelFormat = PixelFormatYUV420P
+	}
+
+	// Align segments
+	d := strconv.FormatFloat(o.SegmentDuration.Seconds(), 'f', 3, 64)
+	e.ForceKeyFrames = "expr:gte(t,n_forced*" + d + ")"
+	e.SCThreshold = astikit.IntPtr(0)
+
+	// Create dash options
+	// Adaptation sets are not set so that the dash muxer creates one per mapped stream, which works for video only
+	// or audio only inputs as well
+	do := &DASHOptions{
+		HLSMasterName: CMAFHLSMasterName,
+		HLSPlaylist:   true,
+		InitSegName:   "init-$RepresentationID$.m4s",
+		MediaSegName:  "chunk-$RepresentationID$-$Number%05d$.m4s",
+		SegDuration:   astikit.DurationPtr(o.SegmentDuration),
+		UseTemplate:   astikit.BoolPtr(true),
+		UseTimeline:   astikit.BoolPtr(true),
+	}
+	if o.CENC != nil {
+		do.FormatOptions = map[string]string{
+			"encryption_key":    hex.EncodeToString(o.CENC.Key),
+			"encryption_kid":    hex.EncodeToString(o.CENC.KeyID),
+			"encryption_scheme": o.CENC.scheme(),
+		}
+	}
+	return &OutputOptions{
+		Encoding: e,
+		Format:   "dash",
+		Muxing:   &MuxingOptions{DASH: do},
+	}
+}
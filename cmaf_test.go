@@ -0,0 +1,29 @@
+package astiffmpeg
+
+import (
+	"bytes"
+	"os/exec"
+	"reflect"
+	"testing"
+)
+
+func TestCMAFOutputOptions(t *testing.T) {
+	cmd := &exec.Cmd{}
+	if err := cmafOutputOptions(CMAFOptions{CENC: &CENCOptions{
+		Key:   bytes.Repeat([]byte{1}, 16),
+		KeyID: bytes.Repeat([]byte{2}, 16),
+	}}).adaptCmd(cmd); err != nil {
+		t.Fatal(err)
+	}
+	e := []string{
+		"-codec:v", "libx264", "-codec:a", "aac", "-force_key_frames", "expr:gte(t,n_forced*4.000)", "-pix_fmt", "yuv420p", "-sc_threshold", "0",
+		"-format_options", "encryption_key=01010101010101010101010101010101:encryption_kid=02020202020202020202020202020202:encryption_scheme=cenc-aes-ctr",
+		"-hls_master_name", "master.m3u8", "-hls_playlist", "1",
+		"-init_seg_name", "init-$RepresentationID$.m4s", "-media_seg_name", "chunk-$RepresentationID$-$Number%05d$.m4s",
+		"-seg_duration", "4.000", "-use_template", "1", "-use_timeline", "1",
+		"-f", "dash",
+	}
+	if !reflect.DeepEqual(e, cmd.Args) {
+		t.Errorf("expected %+v, got %+v", e, cmd.Args)
+	}
+}
@@ -0,0 +1,228 @@
+package astiffmpeg
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Codec names, as opposed to encoder names (e.g. "h264" is the codec name of the libx264 encoder)
+const (
+	CodecNameAAC    = "aac"
+	CodecNameAC3    = "ac3"
+	CodecNameALAC   = "alac"
+	CodecNameAV1    = "av1"
+	CodecNameEAC3   = "eac3"
+	CodecNameFLAC   = "flac"
+	CodecNameH264   = "h264"
+	CodecNameHEVC   = "hevc"
+	CodecNameMP2    = "mp2"
+	CodecNameMP3    = "mp3"
+	CodecNameOpus   = "opus"
+	CodecNamePCM    = "pcm"
+	CodecNameProRes = "prores"
+	CodecNameVorbis = "vorbis"
+	CodecNameVP8    = "vp8"
+	CodecNameVP9    = "vp9"
+)
+
+var codecNamesByEncoder = map[string]string{
+	CodecAAC:        CodecNameAAC,
+	"ac3":           CodecNameAC3,
+	"alac":          CodecNameALAC,
+	"eac3":          CodecNameEAC3,
+	CodecFLAC:       CodecNameFLAC,
+	CodecH264NVENC:  CodecNameH264,
+	CodecHEVCNVENC:  CodecNameHEVC,
+	CodecLibaomAV1:  CodecNameAV1,
+	CodecLibmp3lame: CodecNameMP3,
+	CodecLibopus:    CodecNameOpus,
+	CodecLibvorbis:  CodecNameVorbis,
+	CodecLibx264:    CodecNameH264,
+	CodecLibx265:    CodecNameHEVC,
+	"libdav1d":      CodecNameAV1,
+	"libsvtav1":     CodecNameAV1,
+	"libvpx":        CodecNameVP8,
+	"libvpx-vp9":    CodecNameVP9,
+	"mp2":           CodecNameMP2,
+	"opus":          CodecNameOpus,
+	"prores_ks":     CodecNameProRes,
+	"vorbis":        CodecNameVorbis,
+}
+
+var videoCodecNames = map[string]bool{
+	CodecNameAV1:    true,
+	CodecNameH264:   true,
+	CodecNameHEVC:   true,
+	CodecNameProRes: true,
+	CodecNameVP8:    true,
+	CodecNameVP9:    true,
+}
+
+// codecName returns the codec name of an encoder, or false if the encoder is unknown
+func codecName(encoder string) (string, bool) {
+	if strings.HasPrefix(encoder, "pcm_") {
+		return CodecNamePCM, true
+	}
+	n, ok := codecNamesByEncoder[encoder]
+	return n, ok
+}
+
+// codecCompatibility represents whether a codec can be muxed into a container
+type codecCompatibility struct {
+	caveat    string
+	supported bool
+}
+
+// Containers missing from this table (e.g. matroska, nut or segment) accept any codec or are not checked
+var containerCompatibilities = map[string]map[string]codecCompatibility{
+	"adts": {
+		CodecNameAAC: {supported: true},
+	},
+	"flv": {
+		CodecNameAAC:  {supported: true},
+		CodecNameAV1:  {caveat: "av1 in flv requires enhanced rtmp support from both ffmpeg (>= 6.1) and the server", supported: true},
+		CodecNameH264: {supported: true},
+		CodecNameHEVC: {caveat: "hevc in flv requires enhanced rtmp support from both ffmpeg (>= 6.1) and the server", supported: true},
+		CodecNameMP3:  {supported: true},
+		CodecNameVP9:  {caveat: "vp9 in flv requires enhanced rtmp support from both ffmpeg (>= 6.1) and the server", supported: true},
+	},
+	"mov": {
+		CodecNameAAC:    {supported: true},
+		CodecNameAC3:    {supported: true},
+		CodecNameALAC:   {supported: true},
+		CodecNameAV1:    {supported: true},
+		CodecNameEAC3:   {supported: true},
+		CodecNameFLAC:   {supported: true},
+		CodecNameH264:   {supported: true},
+		CodecNameHEVC:   {caveat: "hevc should be tagged as hvc1 (see EncodingOptions.Tags) to be played by Apple devices", supported: true},
+		CodecNameMP2:    {supported: true},
+		CodecNameMP3:    {supported: true},
+		CodecNameOpus:   {supported: true},
+		CodecNamePCM:    {supported: true},
+		CodecNameProRes: {supported: true},
+		CodecNameVP9:    {supported: true},
+	},
+	"mp4": {
+		CodecNameAAC:  {supported: true},
+		CodecNameAC3:  {supported: true},
+		CodecNameALAC: {supported: true},
+		CodecNameAV1:  {supported: true},
+		CodecNameEAC3: {supported: true},
+		CodecNameFLAC: {caveat: "flac in mp4 is not supported by all players", supported: true},
+		CodecNameH264: {supported: true},
+		CodecNameHEVC: {caveat: "hevc should be tagged as hvc1 (see EncodingOptions.Tags) to be played by Apple devices", supported: true},
+		CodecNameMP2:  {supported: true},
+		CodecNameMP3:  {supported: true},
+		CodecNameOpus: {caveat: "opus in mp4 is not supported by all players", supported: true},
+		CodecNameVP9:  {caveat: "vp9 in mp4 is not supported by all players, webm is safer", supported: true},
+	},
+	"mpegts": {
+		CodecNameAAC:  {supported: true},
+		CodecNameAC3:  {supported: true},
+		CodecNameAV1:  {caveat: "av1 in mpegts requires ffmpeg >= 6.1 and is not supported by all players", supported: true},
+		CodecNameEAC3: {supported: true},
+		CodecNameH264: {supported: true},
+		CodecNameHEVC: {supported: true},
+		CodecNameMP2:  {supported: true},
+		CodecNameMP3:  {supported: true},
+		CodecNameOpus: {caveat: "opus in mpegts is not supported by all players", supported: true},
+	},
+	"ogg": {
+		CodecNameFLAC:   {supported: true},
+		CodecNameOpus:   {supported: true},
+		CodecNameVorbis: {supported: true},
+		CodecNameVP8:    {supported: true},
+	},
+	"wav": {
+		CodecNamePCM: {supported: true},
+	},
+	"webm": {
+		CodecNameAV1:    {supported: true},
+		CodecNameOpus:   {supported: true},
+		CodecNameVorbis: {supported: true},
+		CodecNameVP8:    {supported: true},
+		CodecNameVP9:    {supported: true},
+	},
+}
+
+func init() {
+	containerCompatibilities["ipod"] = containerCompatibilities["mp4"]
+}
+
+// CodecCompatibility returns whether the codec (see CodecName constants) can be muxed into the container (e.g.
+// "mp4") as well as caveats, if any
+// Unknown containers are considered compatible with any codec.
+func CodecCompatibility(container, codec string) (supported bool, caveat string) {
+	cs, ok := containerCompatibilities[container]
+	if !ok {
+		return true, ""
+	}
+	c := cs[codec]
+	return c.supported, c.caveat
+}
+
+// compatibleContainers returns the checked containers supporting the codec
+func compatibleContainers(codec string) (containers []string) {
+	for container, cs := range containerCompatibilities {
+		if c := cs[codec]; c.supported && container != "ipod" {
+			containers = append(containers, container)
+		}
+	}
+	sort.Strings(containers)
+	return
+}
+
+// Validate rejects impossible combinations of codecs and containers
+// It's run before each execution when Configuration.ValidateOutputs is true. The container is either the format or the one inferred from the path extension. Only known encoders are checked.
+func (o Output) Validate() error {
+	// Get container
+	var container string
+	if o.Options != nil && len(o.Options.Format) > 0 {
+		container = o.Options.Format
+	} else {
+		container = outputFormatsByExtension[strings.ToLower(filepath.Ext(o.Path))]
+	}
+	cs, ok := containerCompatibilities[container]
+	if !ok || o.Options == nil || o.Options.Encoding == nil {
+		return nil
+	}
+
+	// Loop through codecs
+	for _, so := range o.Options.Encoding.Codec {
+		// Get codec name
+		e, ok := so.Value.(string)
+		if !ok {
+			continue
+		}
+		n, ok := codecName(e)
+		if !ok {
+			continue
+		}
+
+		// Codec is supported
+		if cs[n].supported {
+			continue
+		}
+
+		// Create error
+		msg := fmt.Sprintf("astiffmpeg: %s is not supported by %s", e, container)
+		if ss := compatibleContainers(n); len(ss) > 0 {
+			msg += ", use one of " + strings.Join(ss, ", ") + " or matroska instead"
+		} else {
+			msg += ", use matroska instead"
+		}
+		var es []string
+		for c, cc := range cs {
+			if cc.supported && videoCodecNames[c] == videoCodecNames[n] {
+				es = append(es, c)
+			}
+		}
+		sort.Strings(es)
+		msg += ", or transcode to one of " + strings.Join(es, ", ")
+		return errors.New(msg)
+	}
+	return nil
+}
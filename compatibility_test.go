@@ -0,0 +1,46 @@
+package astiffmpeg
+
+import "testing"
+
+func TestOutputValidate(t *testing.T) {
+	if err := (Output{Path: "out.mkv", Options: &OutputOptions{Encoding: &EncodingOptions{Codec: []StreamOption{audioStreamOption("pcm_s16le")}}}}).Validate(); err != nil {
+		t.Errorf("expected no error, got %s", err)
+	}
+	if err := (Output{Path: "out.mp4", Options: &OutputOptions{Encoding: &EncodingOptions{Codec: []StreamOption{videoStreamOption(CodecLibx264), audioStreamOption(CodecAAC)}}}}).Validate(); err != nil {
+		t.Errorf("expected no error, got %s", err)
+	}
+	err := (Output{Path: "out.mp4", Options: &OutputOptions{Encoding: &EncodingOptions{Codec: []StreamOption{audioStreamOption("pcm_s16le")}}}}).Validate()
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if e := "astiffmpeg: pcm_s16le is not supported by mp4, use one of mov, wav or matroska instead, or transcode to one of aac, ac3, alac, eac3, flac, mp2, mp3, opus"; err.Error() != e {
+		t.Errorf("expected %s, got %s", e, err)
+	}
+	if err = (Output{Path: "out", Options: &OutputOptions{Encoding: &EncodingOptions{Codec: []StreamOption{videoStreamOption(CodecLibx264)}}, Format: "webm"}}).Validate(); err == nil {
+		t.Error("expected error")
+	}
+	if ok, caveat := CodecCompatibility("mp4", CodecNameVP9); !ok || len(caveat) == 0 {
+		t.Errorf("expected supported with caveat, got %v %q", ok, caveat)
+	}
+	for _, c := range []string{CodecNameAV1, CodecNameHEVC, CodecNameVP9} {
+		if ok, caveat := CodecCompatibility("flv", c); !ok || len(caveat) == 0 {
+			t.Errorf("expected %s supported with caveat, got %v %q", c, ok, caveat)
+		}
+	}
+}
+
+func TestCodecCompatibility(t *testing.T) {
+	for _, v := range []struct {
+		codec     string
+		container string
+	}{
+		{codec: CodecNameAV1, container: "mpegts"},
+		{codec: CodecNameMP2, container: "mov"},
+		{codec: CodecNameMP2, container: "mp4"},
+		{codec: CodecNameVP8, container: "ogg"},
+	} {
+		if ok, _ := CodecCompatibility(v.container, v.codec); !ok {
+			t.Errorf("expected %s to be supported by %s", v.codec, v.container)
+		}
+	}
+}
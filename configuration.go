@@ -4,17 +4,29 @@ import "flag"
 
 // Flags
 var (
-	BinaryPath = flag.String("ffmpeg-binary-path", "", "the FFMpeg binary path")
+	BinaryPath      = flag.String("ffmpeg-binary-path", "", "the FFMpeg binary path")
+	CheckFilters    = flag.Bool("ffmpeg-check-filters", false, "if true, filters are checked against the FFMpeg build before execution")
+	ProbeBinaryPath = flag.String("ffprobe-binary-path", "", "the FFProbe binary path")
+	ValidateOutputs = flag.Bool("ffmpeg-validate-outputs", false, "if true, outputs codecs are checked against their container before execution")
 )
 
 // Configuration represents the ffmpeg configuration
 type Configuration struct {
 	BinaryPath string `toml:"binary_path"`
+	// Before execution, checks that every filter used is available in the ffmpeg build
+	CheckFilters bool `toml:"check_filters"`
+	// Defaults to the ffprobe binary located next to the ffmpeg binary
+	ProbeBinaryPath string `toml:"probe_binary_path"`
+	// Before execution, rejects outputs whose codecs can't be muxed into their container (see Output.Validate)
+	ValidateOutputs bool `toml:"validate_outputs"`
 }
 
 // FlagConfig generates a Configuration based on flags
 func FlagConfig() Configuration {
 	return Configuration{
-		BinaryPath: *BinaryPath,
+		BinaryPath:      *BinaryPath,
+		CheckFilters:    *CheckFilters,
+		ProbeBinaryPath: *ProbeBinaryPath,
+		ValidateOutputs: *ValidateOutputs,
 	}
 }
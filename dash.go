@@ -0,0 +1,120 @@
+package astiffmpeg
+
+import (
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DASHOptions represents options of the dash muxer
+type DASHOptions struct {
+	// Groups streams in adaptation sets (e.g. "id=0,streams=v id=1,streams=a")
+	AdaptationSets string
+	// Duration of fragments when FragType is DASHFragTypeDuration
+	FragDuration *time.Duration
+	// How segments are split into fragments, see DASHFragType constants
+	FragType string
+	// Options of the underlying segment muxer (e.g. "movflags" or CENC options)
+	FormatOptions map[string]string
+	// Name of the HLS master playlist, written when HLSPlaylist is true
+	HLSMasterName string
+	// Writes HLS playlists referencing the same segments as the MPD
+	HLSPlaylist bool
+	// Enables low latency DASH, which requires Streaming
+	LDash bool
+	// Enables low latency HLS (experimental): the upcoming segment is advertised in the HLS playlists with
+	// #EXT-X-PREFETCH so that players can load it while it's being written. Requires HLSPlaylist and Streaming.
+	LHLS bool
+	// Pattern of the init segments name (e.g. "init-$RepresentationID$.m4s")
+	InitSegName string
+	// Pattern of the media segments name (e.g. "chunk-$RepresentationID$-$Number%05d$.m4s")
+	MediaSegName string
+	// Target segment duration. Segments are only cut on keyframes.
+	SegDuration *time.Duration
+	// Every frame is a moof/mdat pair so that segments can be delivered while being written
+	Streaming bool
+	// Target latency advertised in the MPD
+	TargetLatency *time.Duration
+	UseTemplate   *bool
+	UseTimeline   *bool
+	// Number of segments kept in the manifests, 0 means all segments
+	WindowSize *int
+}
+
+// DASH frag types
+const (
+	DASHFragTypeDuration   = "duration"
+	DASHFragTypeEveryFrame = "every_frame"
+	DASHFragTypeNone       = "none"
+	DASHFragTypePFrames    = "pframes"
+)
+
+func (o DASHOptions) adaptCmd(cmd *exec.Cmd) {
+	if len(o.AdaptationSets) > 0 {
+		cmd.Args = append(cmd.Args, "-adaptation_sets", o.AdaptationSets)
+	}
+	if o.FragDuration != nil {
+		cmd.Args = append(cmd.Args, "-frag_duration", strconv.FormatFloat(o.FragDuration.Seconds(), 'f', 3, 64))
+	}
+	if len(o.FragType) > 0 {
+		cmd.Args = append(cmd.Args, "-frag_type", o.FragType)
+	}
+	if len(o.FormatOptions) > 0 {
+		var ks []string
+		for k := range o.FormatOptions {
+			ks = append(ks, k)
+		}
+		sort.Strings(ks)
+		var ss []string
+		for _, k := range ks {
+			ss = append(ss, k+"="+o.FormatOptions[k])
+		}
+		cmd.Args = append(cmd.Args, "-format_options", strings.Join(ss, ":"))
+	}
+	if len(o.HLSMasterName) > 0 {
+		cmd.Args = append(cmd.Args, "-hls_master_name", o.HLSMasterName)
+	}
+	if o.HLSPlaylist {
+		cmd.Args = append(cmd.Args, "-hls_playlist", "1")
+	}
+	if o.LDash {
+		cmd.Args = append(cmd.Args, "-ldash", "1")
+	}
+	if o.LHLS {
+		cmd.Args = append(cmd.Args, "-lhls", "1")
+	}
+	if len(o.InitSegName) > 0 {
+		cmd.Args = append(cmd.Args, "-init_seg_name", o.InitSegName)
+	}
+	if len(o.MediaSegName) > 0 {
+		cmd.Args = append(cmd.Args, "-media_seg_name", o.MediaSegName)
+	}
+	if o.SegDuration != nil {
+		cmd.Args = append(cmd.Args, "-seg_duration", strconv.FormatFloat(o.SegDuration.Seconds(), 'f', 3, 64))
+	}
+	if o.Streaming {
+		cmd.Args = append(cmd.Args, "-streaming", "1")
+	}
+	if o.TargetLatency != nil {
+		cmd.Args = append(cmd.Args, "-target_latency", strconv.FormatFloat(o.TargetLatency.Seconds(), 'f', 3, 64))
+	}
+	if o.UseTemplate != nil {
+		v := "0"
+		if *o.UseTemplate {
+			v = "1"
+		}
+		cmd.Args = append(cmd.Args, "-use_template", v)
+	}
+	if o.UseTimeline != nil {
+		v := "0"
+		if *o.UseTimeline {
+			v = "1"
+		}
+		cmd.Args = append(cmd.Args, "-use_timeline", v)
+	}
+	if o.WindowSize != nil {
+		cmd.Args = append(cmd.Args, "-window_size", strconv.Itoa(*o.WindowSize))
+	}
+}
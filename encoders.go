@@ -0,0 +1,145 @@
+package astiffmpeg
+
+import "fmt"
+
+// Presets (h264_nvenc and hevc_nvenc), from fastest to slowest
+const (
+	PresetNVENCP1 = "p1"
+	PresetNVENCP2 = "p2"
+	PresetNVENCP3 = "p3"
+	PresetNVENCP4 = "p4"
+	PresetNVENCP5 = "p5"
+	PresetNVENCP6 = "p6"
+	PresetNVENCP7 = "p7"
+)
+
+// Profiles (aac)
+const (
+	ProfileAACLow      = "aac_low"
+	ProfileAACLTP      = "aac_ltp"
+	ProfileAACMain     = "aac_main"
+	ProfileAACMPEG2Low = "mpeg2_aac_low"
+)
+
+// Profiles (libx265 and hevc_nvenc)
+const (
+	ProfileHEVCMain       = "main"
+	ProfileHEVCMain10     = "main10"
+	ProfileHEVCMain422x10 = "main422-10"
+	ProfileHEVCMain444x8  = "main444-8"
+	ProfileHEVCMain444x10 = "main444-10"
+	ProfileHEVCRext       = "rext" // hevc_nvenc only
+)
+
+// Profiles (h264_nvenc)
+const (
+	ProfileNVENCHigh444P = "high444p"
+)
+
+// Profiles (prores_ks)
+const (
+	ProfileProResProxy    = "proxy"
+	ProfileProResLT       = "lt"
+	ProfileProResStandard = "standard"
+	ProfileProResHQ       = "hq"
+	ProfileProRes4444     = "4444"
+	ProfileProRes4444XQ   = "4444xq"
+)
+
+// Speed levels (libvpx-vp9 and libaom-av1), lower is slower and better
+const (
+	SpeedLevelAV1Fastest = 8
+	SpeedLevelAV1Slowest = 0
+	SpeedLevelVP9Fastest = 8
+	SpeedLevelVP9Slowest = 0
+)
+
+type encoderValues struct {
+	maxSpeedLevel int
+	minSpeedLevel int
+	presets       map[string]bool
+	profiles      map[string]bool
+}
+
+func valuesSet(vs ...string) map[string]bool {
+	m := make(map[string]bool)
+	for _, v := range vs {
+		m[v] = true
+	}
+	return m
+}
+
+var (
+	nvencPresets = valuesSet(PresetNVENCP1, PresetNVENCP2, PresetNVENCP3, PresetNVENCP4, PresetNVENCP5, PresetNVENCP6,
+		PresetNVENCP7, "default", "slow", "medium", "fast", "hp", "hq", "bd", "ll", "llhq", "llhp", "lossless",
+		"losslesshp")
+	x26xPresets = valuesSet(PresetUltrafast, PresetSuperfast, PresetVeryfast, PresetFaster, PresetFast, PresetMedium,
+		PresetSlow, PresetSlower, PresetVeryslow, "placebo")
+)
+
+// Encoders missing from this table are not validated
+var encodersValues = map[string]encoderValues{
+	CodecAAC: {profiles: valuesSet(ProfileAACLow, ProfileAACLTP, ProfileAACMain, ProfileAACMPEG2Low)},
+	CodecH264NVENC: {
+		presets:  nvencPresets,
+		profiles: valuesSet(ProfileBaseline, ProfileMain, ProfileHigh, ProfileNVENCHigh444P),
+	},
+	CodecHEVCNVENC: {
+		presets:  nvencPresets,
+		profiles: valuesSet(ProfileHEVCMain, ProfileHEVCMain10, ProfileHEVCRext),
+	},
+	CodecLibaomAV1: {maxSpeedLevel: SpeedLevelAV1Fastest, minSpeedLevel: SpeedLevelAV1Slowest},
+	CodecLibx264: {
+		presets:  x26xPresets,
+		profiles: valuesSet(ProfileBaseline, ProfileMain, ProfileHigh, ProfileHigh10, ProfileHigh422, ProfileHigh444),
+	},
+	CodecLibx265: {
+		presets:  x26xPresets,
+		profiles: valuesSet(ProfileHEVCMain, ProfileHEVCMain10, ProfileHEVCMain422x10, ProfileHEVCMain444x8, ProfileHEVCMain444x10),
+	},
+	// libvpx-vp9 accepts negative speed levels as well, which behave like their absolute value
+	"libvpx-vp9": {maxSpeedLevel: SpeedLevelVP9Fastest, minSpeedLevel: -SpeedLevelVP9Fastest},
+	"prores_ks": {profiles: valuesSet(ProfileProResProxy, ProfileProResLT, ProfileProResStandard, ProfileProResHQ,
+		ProfileProRes4444, ProfileProRes4444XQ)},
+}
+
+// validate makes sure preset, profile and speed level are supported by at least one of the encoders, which
+// prevents passing libx264 values to other encoders silently
+// Since those options are not stream specific, a value only needs to be supported by one of the known encoders.
+func (o EncodingOptions) validate() error {
+	// Get known encoders
+	var es []string
+	for _, so := range o.Codec {
+		if e, ok := so.Value.(string); ok {
+			if _, ok = encodersValues[e]; ok {
+				es = append(es, e)
+			}
+		}
+	}
+	if len(es) == 0 {
+		return nil
+	}
+
+	// Validate
+	if len(o.Preset) > 0 && !encodersSupport(es, func(v encoderValues) bool { return v.presets[o.Preset] }) {
+		return fmt.Errorf("astiffmpeg: preset %s is not supported by %v", o.Preset, es)
+	}
+	if len(o.Profile) > 0 && !encodersSupport(es, func(v encoderValues) bool { return v.profiles[o.Profile] }) {
+		return fmt.Errorf("astiffmpeg: profile %s is not supported by %v", o.Profile, es)
+	}
+	if o.CPUUsed != nil && !encodersSupport(es, func(v encoderValues) bool {
+		return v.maxSpeedLevel > v.minSpeedLevel && *o.CPUUsed >= v.minSpeedLevel && *o.CPUUsed <= v.maxSpeedLevel
+	}) {
+		return fmt.Errorf("astiffmpeg: speed level %d is not supported by %v", *o.CPUUsed, es)
+	}
+	return nil
+}
+
+func encodersSupport(es []string, fn func(v encoderValues) bool) bool {
+	for _, e := range es {
+		if fn(encodersValues[e]) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,36 @@
+package astiffmpeg
+
+import (
+	"os/exec"
+	"testing"
+
+	"github.com/asticode/go-astikit"
+)
+
+func TestEncodingOptionsValidate(t *testing.T) {
+	for _, v := range []struct {
+		err bool
+		o   EncodingOptions
+	}{
+		{o: EncodingOptions{Codec: []StreamOption{videoStreamOption(CodecLibx264)}, Preset: PresetSlow, Profile: ProfileHigh}},
+		{o: EncodingOptions{Codec: []StreamOption{videoStreamOption(CodecLibx264), audioStreamOption(CodecAAC)}, Profile: ProfileHigh}},
+		{o: EncodingOptions{Codec: []StreamOption{videoStreamOption("libunknown")}, Preset: "whatever"}},
+		{o: EncodingOptions{Codec: []StreamOption{videoStreamOption(CodecH264NVENC)}, Preset: PresetNVENCP5}},
+		{err: true, o: EncodingOptions{Codec: []StreamOption{videoStreamOption(CodecH264NVENC)}, Preset: PresetVeryfast}},
+		{err: true, o: EncodingOptions{Codec: []StreamOption{videoStreamOption(CodecLibx264)}, Preset: PresetNVENCP5}},
+		{err: true, o: EncodingOptions{Codec: []StreamOption{videoStreamOption("prores_ks")}, Profile: ProfileHigh}},
+		{o: EncodingOptions{Codec: []StreamOption{videoStreamOption("libvpx-vp9")}, CPUUsed: astikit.IntPtr(4)}},
+		{o: EncodingOptions{Codec: []StreamOption{videoStreamOption("libvpx-vp9")}, CPUUsed: astikit.IntPtr(-8)}},
+		{err: true, o: EncodingOptions{Codec: []StreamOption{videoStreamOption("libvpx-vp9")}, CPUUsed: astikit.IntPtr(-9)}},
+		{err: true, o: EncodingOptions{Codec: []StreamOption{videoStreamOption(CodecLibaomAV1)}, CPUUsed: astikit.IntPtr(-1)}},
+		{err: true, o: EncodingOptions{Codec: []StreamOption{videoStreamOption(CodecLibaomAV1)}, CPUUsed: astikit.IntPtr(12)}},
+		{err: true, o: EncodingOptions{Codec: []StreamOption{videoStreamOption(CodecLibx264)}, CPUUsed: astikit.IntPtr(4)}},
+	} {
+		err := v.o.adaptCmd(exec.Command("ffmpeg"))
+		if v.err && err == nil {
+			t.Errorf("%+v: expected error", v.o)
+		} else if !v.err && err != nil {
+			t.Errorf("%+v: expected no error, got %s", v.o, err)
+		}
+	}
+}
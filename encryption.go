@@ -0,0 +1,94 @@
+package astiffmpeg
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Encryption schemes
+const (
+	EncryptionSchemeCENCAESCTR = "cenc-aes-ctr"
+)
+
+// CENCOptions represents common encryption options of the mov/mp4 muxer, used for DASH and CMAF outputs
+type CENCOptions struct {
+	Key    []byte // 16 bytes
+	KeyID  []byte // 16 bytes
+	Scheme string // Defaults to EncryptionSchemeCENCAESCTR
+}
+
+func (o CENCOptions) scheme() string {
+	if len(o.Scheme) == 0 {
+		return EncryptionSchemeCENCAESCTR
+	}
+	return o.Scheme
+}
+
+func (o CENCOptions) validate() error {
+	if len(o.Key) != 16 {
+		return errors.New("astiffmpeg: key should be 16 bytes long")
+	}
+	if len(o.KeyID) != 16 {
+		return errors.New("astiffmpeg: key id should be 16 bytes long")
+	}
+	return nil
+}
+
+func (o CENCOptions) args() (args []string, err error) {
+	// Validate
+	if err = o.validate(); err != nil {
+		return
+	}
+
+	// Create args
+	args = []string{
+		"-encryption_scheme", o.scheme(),
+		"-encryption_key", hex.EncodeToString(o.Key),
+		"-encryption_kid", hex.EncodeToString(o.KeyID),
+	}
+	return
+}
+
+// HLSKey represents an HLS AES-128 key
+// The hls muxer doesn't support SAMPLE-AES, segments are fully encrypted.
+type HLSKey struct {
+	// When empty, the segment sequence number is used as IV
+	IV  []byte // 16 bytes
+	Key []byte // 16 bytes
+	// Path where the key is written, and read by ffmpeg
+	Path string
+	// URI of the key written in the playlist, where players fetch it
+	URI string
+}
+
+// WriteHLSKeyInfoFile writes the key and the key info file expected by HLSOptions.KeyInfoFile
+func WriteHLSKeyInfoFile(path string, k HLSKey) (err error) {
+	// Check key
+	if len(k.Key) != 16 {
+		err = errors.New("astiffmpeg: key should be 16 bytes long")
+		return
+	}
+	if len(k.IV) > 0 && len(k.IV) != 16 {
+		err = errors.New("astiffmpeg: iv should be 16 bytes long")
+		return
+	}
+
+	// Write key
+	if err = os.WriteFile(k.Path, k.Key, 0600); err != nil {
+		err = fmt.Errorf("astiffmpeg: writing key to %s failed: %w", k.Path, err)
+		return
+	}
+
+	// Write key info file
+	c := k.URI + "\n" + k.Path + "\n"
+	if len(k.IV) > 0 {
+		c += hex.EncodeToString(k.IV) + "\n"
+	}
+	if err = os.WriteFile(path, []byte(c), 0600); err != nil {
+		err = fmt.Errorf("astiffmpeg: writing key info file to %s failed: %w", path, err)
+		return
+	}
+	return
+}
@@ -0,0 +1,55 @@
+package astiffmpeg
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestWriteHLSKeyInfoFile(t *testing.T) {
+	d := t.TempDir()
+	k := HLSKey{
+		IV:   bytes.Repeat([]byte{1}, 16),
+		Key:  bytes.Repeat([]byte{2}, 16),
+		Path: filepath.Join(d, "enc.key"),
+		URI:  "https://example.com/enc.key",
+	}
+	p := filepath.Join(d, "enc.keyinfo")
+	if err := WriteHLSKeyInfoFile(p, k); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := "https://example.com/enc.key\n" + k.Path + "\n01010101010101010101010101010101\n"; string(b) != e {
+		t.Errorf("expected %s, got %s", e, b)
+	}
+	if b, err = os.ReadFile(k.Path); err != nil || !bytes.Equal(k.Key, b) {
+		t.Errorf("expected key %x, got %x (%v)", k.Key, b, err)
+	}
+	if err = WriteHLSKeyInfoFile(p, HLSKey{Key: []byte{1}}); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestCENCOptions(t *testing.T) {
+	for _, o := range []CENCOptions{
+		{Key: bytes.Repeat([]byte{1}, 15), KeyID: bytes.Repeat([]byte{2}, 16)},
+		{Key: bytes.Repeat([]byte{1}, 16), KeyID: bytes.Repeat([]byte{2}, 32)},
+	} {
+		if err := (OutputOptions{Muxing: &MuxingOptions{CENC: &o}}).adaptCmd(exec.Command("ffmpeg")); err == nil {
+			t.Errorf("expected error for %+v", o)
+		}
+	}
+	cmd := exec.Command("ffmpeg")
+	if err := (OutputOptions{Muxing: &MuxingOptions{CENC: &CENCOptions{Key: bytes.Repeat([]byte{1}, 16), KeyID: bytes.Repeat([]byte{2}, 16)}}}).adaptCmd(cmd); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	if e := []string{"ffmpeg", "-encryption_scheme", "cenc-aes-ctr", "-encryption_key", "01010101010101010101010101010101", "-encryption_kid", "02020202020202020202020202020202"}; !reflect.DeepEqual(e, cmd.Args) {
+		t.Errorf("expected %+v, got %+v", e, cmd.Args)
+	}
+}
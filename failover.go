@@ -0,0 +1,144 @@
+package astiffmpeg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// FailoverOptions represents failover options
+type FailoverOptions struct {
+	// Inputs are used in order, the next one being used when the current one is lost. Once the last one is lost,
+	// the first one is used again.
+	Inputs []Input
+	// Duration without data after which an input is considered lost. Defaults to 5s.
+	InputTimeout time.Duration
+	// Maximum delay between restarts. Defaults to 30s.
+	MaxRestartDelay time.Duration
+	// Maximum number of switches, 0 means infinite
+	MaxSwitches int
+	// Executed when switching inputs, err being nil when the input has ended without error
+	OnSwitch func(from, to int, err error)
+	// Delay before restarting ffmpeg with the next input, which is doubled each time an input fails shortly after
+	// having been started, up to MaxRestartDelay. Defaults to 1s.
+	RestartDelay time.Duration
+	// By default, live inputs ending without error (e.g. EOF) are lost as well. When set, ExecFailover returns once
+	// an input ends without error instead, which is meant for finite inputs.
+	ReturnOnEnd bool
+	// When set, this input is sent to the output for SlateDuration between switches (e.g. SlateInput)
+	Slate         *Input
+	SlateDuration time.Duration
+}
+
+// ExecFailover executes the binary with the current input and, when it's lost, restarts it with the next input
+// while keeping the same output, which is meant to be a live target (e.g. an RTMP or SRT URL)
+// It returns once the context is done or the maximum number of switches is reached, or once an input ends without
+// error when ReturnOnEnd is set.
+func (f *FFMpeg) ExecFailover(ctx context.Context, g GlobalOptions, o FailoverOptions, out Output) (err error) {
+	// Check options
+	if len(o.Inputs) == 0 {
+		err = errors.New("astiffmpeg: no inputs provided")
+		return
+	}
+
+	// Default options
+	if o.InputTimeout <= 0 {
+		o.InputTimeout = 5 * time.Second
+	}
+	if o.MaxRestartDelay <= 0 {
+		o.MaxRestartDelay = 30 * time.Second
+	}
+	if o.RestartDelay <= 0 {
+		o.RestartDelay = time.Second
+	}
+
+	// Loop
+	var delay time.Duration
+	for idx, switches := 0, 0; ; switches++ {
+		// Exec
+		n := time.Now()
+		if err = f.Exec(ctx, g, []Input{failoverInput(o.Inputs[idx], o.InputTimeout)}, out); err == nil && o.ReturnOnEnd {
+			return
+		}
+		ran := time.Since(n)
+
+		// Context is done or maximum number of switches is reached
+		if ctx.Err() != nil {
+			err = fmt.Errorf("astiffmpeg: context error: %w", ctx.Err())
+			return
+		}
+		if o.MaxSwitches > 0 && switches >= o.MaxSwitches {
+			if err != nil {
+				err = fmt.Errorf("astiffmpeg: maximum number of switches reached, last error: %w", err)
+			} else {
+				err = errors.New("astiffmpeg: maximum number of switches reached")
+			}
+			return
+		}
+
+		// Switch
+		next := (idx + 1) % len(o.Inputs)
+		if o.OnSwitch != nil {
+			o.OnSwitch(idx, next, err)
+		}
+		idx = next
+
+		// Slate
+		if o.Slate != nil && o.SlateDuration > 0 {
+			if err = f.Exec(ctx, g, []Input{failoverSlateInput(*o.Slate, o.SlateDuration)}, out); err != nil && ctx.Err() != nil {
+				err = fmt.Errorf("astiffmpeg: context error: %w", ctx.Err())
+				return
+			}
+		}
+
+		// Wait
+		delay = failoverDelay(delay, ran, o)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			err = fmt.Errorf("astiffmpeg: context error: %w", ctx.Err())
+			return
+		}
+	}
+}
+
+// failoverDelay doubles the previous delay up to the maximum, unless the input has been running long enough to be
+// considered healthy, in which case the delay is reset
+func failoverDelay(previous, ran time.Duration, o FailoverOptions) time.Duration {
+	if previous <= 0 || ran >= o.MaxRestartDelay {
+		return o.RestartDelay
+	}
+	if d := 2 * previous; d < o.MaxRestartDelay {
+		return d
+	}
+	return o.MaxRestartDelay
+}
+
+// failoverInput makes sure ffmpeg exits when the input stops sending data
+func failoverInput(i Input, timeout time.Duration) Input {
+	o := &InputOptions{}
+	if i.Options != nil {
+		*o = *i.Options
+	}
+	if o.Timeout == nil {
+		o.Timeout = &timeout
+	}
+	i.Options = o
+	return i
+}
+
+func failoverSlateInput(i Input, d time.Duration) Input {
+	o := &InputOptions{}
+	if i.Options != nil {
+		*o = *i.Options
+	}
+	do := &DecodingOptions{}
+	if o.Decoding != nil {
+		*do = *o.Decoding
+	}
+	do.Duration = d
+	o.Decoding = do
+	i.Options = o
+	return i
+}
@@ -0,0 +1,25 @@
+package astiffmpeg
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFailoverDelay(t *testing.T) {
+	o := FailoverOptions{MaxRestartDelay: 5 * time.Second, RestartDelay: time.Second}
+	for _, v := range []struct {
+		e        time.Duration
+		previous time.Duration
+		ran      time.Duration
+	}{
+		{e: time.Second},
+		{e: 2 * time.Second, previous: time.Second},
+		{e: 4 * time.Second, previous: 2 * time.Second},
+		{e: 5 * time.Second, previous: 4 * time.Second},
+		{e: time.Second, previous: 5 * time.Second, ran: time.Minute},
+	} {
+		if g := failoverDelay(v.previous, v.ran, o); v.e != g {
+			t.Errorf("expected %s, got %s", v.e, g)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package astiffmpeg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/asticode/go-astikit"
+)
+
+// Rendition represents an output of a fan-out
+type Rendition struct {
+	// Encoding options of the rendition, which shouldn't contain filters
+	Options *OutputOptions
+	Path    string
+	// When not set, the video is not scaled
+	Scale *Scale
+}
+
+// FanOut decodes the input once and encodes it into several renditions in a single process, which halves CPU
+// usage compared to running one job per rendition
+// The first video stream is split with the split filter and each branch is scaled separately. The first audio
+// stream, if any, is mapped to every rendition. Unlike loopback decoders (-dec, ffmpeg >= 7.0), the split filter
+// works with every ffmpeg version.
+func (f *FFMpeg) FanOut(ctx context.Context, g GlobalOptions, in Input, renditions []Rendition) (err error) {
+	// Create outputs
+	var outs []Output
+	if outs, err = fanOutOutputs(renditions); err != nil {
+		err = fmt.Errorf("astiffmpeg: creating outputs failed: %w", err)
+		return
+	}
+
+	// Exec
+	if err = f.ExecWithOptions(ctx, g, []Input{in}, outs[0], ExecOptions{Outputs: outs[1:]}); err != nil {
+		err = fmt.Errorf("astiffmpeg: executing failed: %w", err)
+		return
+	}
+	return
+}
+
+func fanOutOutputs(renditions []Rendition) (outs []Output, err error) {
+	// No renditions
+	if len(renditions) == 0 {
+		err = errors.New("astiffmpeg: no renditions provided")
+		return
+	}
+
+	// Split
+	var splits []StreamSpecifier
+	for idx := range renditions {
+		splits = append(splits, StreamSpecifier{Name: "s" + strconv.Itoa(idx)})
+	}
+	cfs := []ComplexFilterOption{{
+		Filters:       []string{"split=" + strconv.Itoa(len(renditions))},
+		InputStreams:  []StreamSpecifier{{Name: "0:v:0"}},
+		OutputStreams: splits,
+	}}
+
+	// Loop through renditions
+	for idx, r := range renditions {
+		// Scale
+		label := splits[idx].Name
+		if r.Scale != nil {
+			label = "v" + strconv.Itoa(idx)
+			cfs = append(cfs, ComplexFilterOption{
+				Filters:       []string{"scale=" + r.Scale.string()},
+				InputStreams:  []StreamSpecifier{splits[idx]},
+				OutputStreams: []StreamSpecifier{{Name: label}},
+			})
+		}
+
+		// Copy options
+		o := copyOutputOptions(r.Options)
+
+		// Map
+		o.Map = &MapOptions{
+			{Label: label},
+			{Optional: true, Stream: &StreamSpecifier{Index: astikit.IntPtr(0), Type: StreamSpecifierTypeAudio}},
+		}
+		outs = append(outs, Output{
+			Options: o,
+			Path:    r.Path,
+		})
+	}
+
+	// Filter graph is global and is added to the first output only
+	e := copyEncodingOptions(outs[0].Options.Encoding)
+	e.ComplexFilters = cfs
+	outs[0].Options.Encoding = e
+	return
+}
@@ -0,0 +1,39 @@
+package astiffmpeg
+
+import (
+	"os/exec"
+	"reflect"
+	"testing"
+
+	"github.com/asticode/go-astikit"
+)
+
+func TestFanOutOutputs(t *testing.T) {
+	if _, err := fanOutOutputs(nil); err == nil {
+		t.Error("expected error")
+	}
+	e := &OutputOptions{Encoding: &EncodingOptions{Codec: []StreamOption{videoStreamOption(CodecLibx264)}}}
+	outs, err := fanOutOutputs([]Rendition{
+		{Options: e, Path: "1080p.mp4"},
+		{Options: e, Path: "720p.ts", Scale: &Scale{Height: astikit.IntPtr(720)}},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	cmd := exec.Command("ffmpeg")
+	for _, o := range outs {
+		if err = o.adaptCmd(cmd); err != nil {
+			t.Errorf("expected no error, got %s", err)
+		}
+	}
+	ea := []string{"ffmpeg",
+		"-map", "[s0]", "-map", "0:a:0?", "-codec:v", "libx264", "-filter_complex", "[0:v:0]split=2[s0][s1];[s1]scale=h=720:w=-1[v1]", "-movflags", "+faststart", "1080p.mp4",
+		"-map", "[v1]", "-map", "0:a:0?", "-codec:v", "libx264", "720p.ts",
+	}
+	if !reflect.DeepEqual(ea, cmd.Args) {
+		t.Errorf("expected %+v, got %+v", ea, cmd.Args)
+	}
+	if e.Encoding.ComplexFilters != nil {
+		t.Error("expected options not to be modified")
+	}
+}
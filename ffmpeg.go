@@ -1,75 +1,455 @@
 package astiffmpeg
 
 import (
-	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
-	"strings"
-	"time"
+	"strconv"
+	"sync"
+
+	"github.com/asticode/go-astikit"
 )
 
 // FFMpeg represents an entity capable of running an FFMpeg binary
 // https://ffmpeg.org/ffmpeg.html
+// It's safe to execute concurrent jobs with the same FFMpeg: each job has its own state and buffers. However
+// stderr parsers keeping state (e.g. CompositeStdErrParser or NewHealthMonitor) should not be shared between
+// concurrent jobs and should be provided per job using ExecOptions.StdErrParser instead.
 type FFMpeg struct {
-	binaryPath   string
-	stdErrParser StdErrParser
+	binaryPath      string
+	checkFilters    bool
+	filters         *capabilities
+	m               *sync.Mutex // Locks stdErrParser
+	muxers          *capabilities
+	probeBinaryPath string
+	stdErrParser    StdErrParser
+	validateOutputs bool
 }
 
 // New creates a new FFMpeg
 func New(c Configuration) *FFMpeg {
-	return &FFMpeg{binaryPath: c.BinaryPath}
+	return &FFMpeg{
+		binaryPath:      c.BinaryPath,
+		checkFilters:    c.CheckFilters,
+		filters:         newCapabilities("-filters", parseFilters),
+		m:               &sync.Mutex{},
+		muxers:          newCapabilities("-muxers", parseMuxers),
+		probeBinaryPath: probeBinaryPath(c),
+		validateOutputs: c.ValidateOutputs,
+	}
 }
 
 // SetStdErrParser sets the stderr parser
 func (f *FFMpeg) SetStdErrParser(s StdErrParser) {
+	f.m.Lock()
+	defer f.m.Unlock()
 	f.stdErrParser = s
 }
 
+// AddStdErrParser adds a stderr parser to the ones already set, see CompositeStdErrParser
+func (f *FFMpeg) AddStdErrParser(s StdErrParser) {
+	f.m.Lock()
+	defer f.m.Unlock()
+	if f.stdErrParser == nil {
+		f.stdErrParser = s
+		return
+	}
+	if c, ok := f.stdErrParser.(*compositeStdErrParser); ok {
+		f.stdErrParser = CompositeStdErrParser(append(append([]StdErrParser{}, c.ps...), s)...)
+		return
+	}
+	f.stdErrParser = CompositeStdErrParser(f.stdErrParser, s)
+}
+
 // Exec executes the binary with the specified options
 // ffmpeg [global_options] {[input_file_options] -i input_url} ... [output_file_options] output_url
 func (f *FFMpeg) Exec(ctx context.Context, g GlobalOptions, in []Input, out Output) (err error) {
+	// Start job
+	var j *Job
+	if j, err = f.ExecAsync(ctx, g, in, out); err != nil {
+		return
+	}
+
+	// Wait
+	err = j.Wait()
+	return
+}
+
+// ExecAsync starts the binary with the specified options and returns without waiting for it to exit
+func (f *FFMpeg) ExecAsync(ctx context.Context, g GlobalOptions, in []Input, out Output) (j *Job, err error) {
+	return f.ExecAsyncWithOptions(ctx, g, in, out, ExecOptions{})
+}
+
+// Args returns the args the binary would be executed with by Exec, starting with the binary path, without executing
+// it, which allows logging, auditing or testing commands, or executing them elsewhere
+// See ArgsWithOptions for the differences with what is actually executed.
+func (f *FFMpeg) Args(g GlobalOptions, in []Input, out Output) ([]string, error) {
+	return f.ArgsWithOptions(g, in, out, ExecOptions{})
+}
+
+// ArgsWithOptions returns the args the binary would be executed with by ExecWithOptions, starting with the binary
+// path, without executing it
+// Args are built the same way, except for what only makes sense for a local execution, so that nothing they
+// reference is created or removed behind the caller's back:
+//   - BeforeStart is not executed and ProgressHandler is ignored
+//   - atomic, in place and sink outputs are rendered with their path instead of a temporary one
+//   - filter graphs and concat lists exceeding command line limits are not spilled to temporary files
+//   - filters are not checked, even if Configuration.CheckFilters is true
+func (f *FFMpeg) ArgsWithOptions(g GlobalOptions, in []Input, out Output, o ExecOptions) (args []string, err error) {
+	// Check binary path
+	if err = checkBinaryPath(f.binaryPath); err != nil {
+		return
+	}
+
+	// Build cmd
+	var c *preparedCmd
+	if c, err = f.buildCmd(context.Background(), g, in, out, o, true); err != nil {
+		return
+	}
+	args = c.cmd.Args
+	return
+}
+
+// ExecOptions represents options specific to one execution, which allows running concurrent jobs with different
+// parsers, hooks or writers using the same FFMpeg
+type ExecOptions struct {
+	// Executed right before the cmd is started, which allows adapting it
+	BeforeStart func(cmd *exec.Cmd)
+	// Executed once the job has exited, with its error if any
+	OnExit func(err error)
+	// Connects a pipe to stdin, unless it's used by an input or Stdin, so that Job.Stop can ask ffmpeg to quit by
+	// writing "q". Since ffmpeg would then wait for an answer to its overwrite prompt, GlobalOptions.Overwrite
+	// defaults to false.
+	GracefulStop bool
+	// Files inherited by the process, referenced in inputs and outputs using ExtraFilePath (e.g. pipes created with
+	// os.Pipe). Not supported on Windows.
+	ExtraFiles []*os.File
+	// Additional outputs written by the same process after the main one, which allows decoding the inputs once
+	Outputs []Output
+	// Receives progress written by ffmpeg with -progress, which is more reliable than parsing stderr periodically.
+	// It can't be used with GlobalOptions.Progress.
+	ProgressHandler ProgressHandler
+	// Overrides the stderr parser set on the FFMpeg for this job only
+	StdErrParser StdErrParser
+	// Receives a copy of stderr
+	Stderr io.Writer
+	// Read by ffmpeg when an input path is "pipe:0"
+	Stdin io.Reader
+	// Receives what ffmpeg writes when an output path is "pipe:1" or "-"
+	Stdout io.Writer
+}
+
+// ExtraFilePath returns the path of the extra file at the specified index of ExecOptions.ExtraFiles, which allows
+// e.g. writing progress to pipe:3 while the output is written to stdout
+func ExtraFilePath(idx int) string {
+	// File descriptors 0, 1 and 2 are stdin, stdout and stderr
+	return "pipe:" + strconv.Itoa(idx+3)
+}
+
+// ExecWithOptions executes the binary with the specified options and execution options
+func (f *FFMpeg) ExecWithOptions(ctx context.Context, g GlobalOptions, in []Input, out Output, o ExecOptions) (err error) {
+	// Start job
+	var j *Job
+	if j, err = f.ExecAsyncWithOptions(ctx, g, in, out, o); err != nil {
+		return
+	}
+
+	// Wait
+	err = j.Wait()
+	return
+}
+
+// ExecAsyncWithOptions starts the binary with the specified options and execution options and returns without
+// waiting for it to exit
+func (f *FFMpeg) ExecAsyncWithOptions(ctx context.Context, g GlobalOptions, in []Input, out Output, o ExecOptions) (j *Job, err error) {
+	// Check binary path
+	if err = checkBinaryPath(f.binaryPath); err != nil {
+		return
+	}
+
+	// Build cmd
+	var c *preparedCmd
+	if c, err = f.buildCmd(ctx, g, in, out, o, false); err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			c.close()
+		}
+	}()
+	cmd := c.cmd
+
+	// Create on exit
+	var onExit func(err error) error
+	if len(c.onExits) > 0 || o.OnExit != nil {
+		onExit = func(errExit error) (err error) {
+			err = errExit
+			for _, fn := range c.onExits {
+				if errFn := fn(errExit); errFn != nil && err == nil {
+					err = errFn
+				}
+			}
+			if o.OnExit != nil {
+				o.OnExit(err)
+			}
+			return err
+		}
+	}
+
+	// Custom adaptation
+	if o.BeforeStart != nil {
+		o.BeforeStart(cmd)
+	}
+
+	// Stdin is used to stop the job gracefully when it's not used by inputs
+	var stdin io.Writer
+	if o.GracefulStop && cmd.Stdin == nil {
+		if stdin, err = cmd.StdinPipe(); err != nil {
+			err = fmt.Errorf("astiffmpeg: creating stdin pipe failed: %w", err)
+			return
+		}
+	}
+
+	// Start cmd
+	if err = cmd.Start(); err != nil {
+		err = fmt.Errorf("astiffmpeg: starting %s failed: %w", cmd.String(), err)
+		return
+	}
+
+	// Platform specific adaptation
+	if err = afterStart(cmd); err != nil {
+		cmd.Process.Kill()
+		cmd.Wait()
+		err = fmt.Errorf("astiffmpeg: adapting started cmd failed: %w", err)
+		return
+	}
+
+	// Read progress
+	if c.pr != nil {
+		c.pr.start(o.ProgressHandler)
+	}
+
+	// Send outputs to sinks
+	for _, s := range c.sinks {
+		s.start(ctx)
+	}
+
+	// Get stderr parser
+	f.m.Lock()
+	p := f.stdErrParser
+	f.m.Unlock()
+	if o.StdErrParser != nil {
+		p = o.StdErrParser
+	}
+
+	// Get output paths
+	ps := []string{out.path()}
+	for _, out := range o.Outputs {
+		ps = append(ps, out.path())
+	}
+
+	// Create job, which makes sure the process is reaped even if Wait is never called, and parses stderr
+	j = newJob(ctx, cmd, c.bufErr, ps, onExit, p)
+	j.stdin = stdin
+
+	// Kill the whole process group on cancellation
+	go func() {
+		select {
+		case <-ctx.Done():
+			j.killProcessGroup()
+		case <-j.exited:
+		}
+	}()
+
+	return
+}
+
+// preparedCmd represents a cmd ready to be started, along with what must be done once it has exited
+type preparedCmd struct {
+	bufErr  *syncBuffer
+	cmd     *exec.Cmd
+	onExits []func(err error) error
+	pr      *progressReader
+	scripts []string
+	sinks   []*outputSink
+}
+
+// close releases the resources of a cmd that won't be started
+func (c *preparedCmd) close() {
+	if c.pr != nil {
+		c.pr.close()
+	}
+	for _, s := range c.sinks {
+		s.close()
+	}
+	removeFiles(c.scripts)
+}
+
+// buildCmd builds the cmd executing the binary with the specified options, which is shared by execs and args so that
+// args are what is executed
+// In dry run mode, nothing that only makes sense for a local execution is created (temporary files, pipes, etc.).
+func (f *FFMpeg) buildCmd(ctx context.Context, g GlobalOptions, in []Input, out Output, o ExecOptions, dryRun bool) (c *preparedCmd, err error) {
 	// Create cmd
 	var cmd = exec.CommandContext(ctx, f.binaryPath)
 	cmd.Env = os.Environ()
+	c = &preparedCmd{cmd: cmd}
+	defer func() {
+		if err != nil {
+			c.close()
+		}
+	}()
 
 	// Output is redirected in stderr only
-	var bufErr = &bytes.Buffer{}
-	cmd.Stderr = bufErr
+	c.bufErr = &syncBuffer{}
+	cmd.Stderr = c.bufErr
+	if o.Stderr != nil {
+		cmd.Stderr = io.MultiWriter(c.bufErr, o.Stderr)
+	}
+	cmd.Stdin = o.Stdin
+	cmd.Stdout = o.Stdout
+	if len(o.ExtraFiles) > 0 {
+		if !extraFilesSupported {
+			err = fmt.Errorf("astiffmpeg: extra files: %w", ErrNotSupported)
+			return
+		}
+		cmd.ExtraFiles = o.ExtraFiles
+	}
+
+	// Progress
+	if o.ProgressHandler != nil && !dryRun {
+		if len(g.Progress) > 0 {
+			err = errors.New("astiffmpeg: progress handler can't be used with GlobalOptions.Progress")
+			return
+		}
+		if c.pr, err = newProgressReader(); err != nil {
+			err = fmt.Errorf("astiffmpeg: creating progress reader failed: %w", err)
+			return
+		}
+		g.Progress = c.pr.adaptCmd(cmd)
+	}
+
+	// ffmpeg must not prompt on stdin when it's connected to a pipe
+	if o.GracefulStop && g.Overwrite == nil {
+		g.Overwrite = astikit.BoolPtr(false)
+	}
 
 	// Global options
-	g.adaptCmd(cmd)
-
-	// Parse stderr
-	if f.stdErrParser != nil {
-		t := time.NewTicker(f.stdErrParser.Period())
-		defer t.Stop()
-		go func() {
-			for t := range t.C {
-				f.stdErrParser.Process(t, bufErr)
-			}
-		}()
+	if err = g.adaptCmd(cmd); err != nil {
+		err = fmt.Errorf("astiffmpeg: adapting cmd for global options failed: %w", err)
+		return
 	}
 
 	// Inputs
 	for idx, i := range in {
+		// Reader
+		if i.Reader != nil {
+			if cmd.Stdin != nil {
+				err = fmt.Errorf("astiffmpeg: input #%d: stdin is already used", idx)
+				return
+			}
+			cmd.Stdin = i.Reader
+		}
+
+		// Input
 		if err = i.adaptCmd(cmd); err != nil {
 			err = fmt.Errorf("astiffmpeg: adapting cmd for input #%d failed: %w", idx, err)
 			return
 		}
 	}
 
-	// Output
-	if err = out.adaptCmd(cmd); err != nil {
-		err = fmt.Errorf("astiffmpeg: adapting cmd for output failed: %w", err)
+	// Loop through outputs
+	for idx, out := range append([]Output{out}, o.Outputs...) {
+		// Sink
+		if out.Sink != nil && dryRun {
+			out.Sink = nil
+		} else if out.Sink != nil {
+			var s *outputSink
+			if out, s, err = newOutputSink(out); err != nil {
+				err = fmt.Errorf("astiffmpeg: creating sink for output #%d failed: %w", idx, err)
+				return
+			}
+			c.sinks = append(c.sinks, s)
+			c.onExits = append(c.onExits, func(err error) error { return s.wait(ctx, err) })
+		}
+
+		// Writer
+		if out.Writer != nil {
+			if cmd.Stdout != nil {
+				err = fmt.Errorf("astiffmpeg: output #%d: stdout is already used", idx)
+				return
+			}
+			cmd.Stdout = out.Writer
+			out.Path = out.path()
+		}
+
+		// Prepare output
+		var prepared Output
+		var onExit func(err error) error
+		if prepared, onExit, err = prepareOutput(in, out); err != nil {
+			err = fmt.Errorf("astiffmpeg: preparing output #%d failed: %w", idx, err)
+			return
+		}
+		if !dryRun {
+			out = prepared
+			if onExit != nil {
+				c.onExits = append(c.onExits, onExit)
+			}
+		}
+
+		// Validate
+		if f.validateOutputs {
+			if err = out.Validate(); err != nil {
+				err = fmt.Errorf("astiffmpeg: validating output #%d failed: %w", idx, err)
+				return
+			}
+		}
+
+		// Output
+		if err = out.adaptCmd(cmd); err != nil {
+			err = fmt.Errorf("astiffmpeg: adapting cmd for output #%d failed: %w", idx, err)
+			return
+		}
+	}
+
+	// Wait for progress to be fully read
+	if c.pr != nil {
+		pr := c.pr
+		c.onExits = append(c.onExits, func(error) error {
+			pr.wait()
+			return nil
+		})
+	}
+
+	// Nothing else is needed in dry run mode
+	if dryRun {
 		return
 	}
 
-	// Run cmd
-	if err = cmd.Run(); err != nil {
-		err = fmt.Errorf("astiffmpeg: running %s failed with stderr %s: %w", strings.Join(cmd.Args, " "), bufErr.Bytes(), err)
+	// Check filters
+	if f.checkFilters {
+		if err = f.checkFiltersAvailable(ctx, cmd.Args); err != nil {
+			err = fmt.Errorf("astiffmpeg: checking filters failed: %w", err)
+			return
+		}
+	}
+
+	// Spill args exceeding command line limits to temporary files
+	if cmd.Args, c.scripts, err = spillArgs(cmd.Args, maxArgLength, maxArgsLength); err != nil {
+		err = fmt.Errorf("astiffmpeg: spilling args failed: %w", err)
 		return
 	}
+	if len(c.scripts) > 0 {
+		scripts := c.scripts
+		c.onExits = append(c.onExits, func(error) error {
+			removeFiles(scripts)
+			return nil
+		})
+	}
+
+	// Platform specific adaptation
+	prepareCmd(cmd)
 	return
 }
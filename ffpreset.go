@@ -0,0 +1,119 @@
+package astiffmpeg
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Only options set on the encoder can be written to an ffpreset file, as opposed to options handled by the ffmpeg
+// CLI (e.g. -q, -pix_fmt or -r)
+var ffpresetOptions = map[string]bool{
+	"-a53cc":             true,
+	"-abr":               true,
+	"-ac":                true,
+	"-application":       true,
+	"-ar":                true,
+	"-b":                 true,
+	"-b_strategy":        true,
+	"-bf":                true,
+	"-bufsize":           true,
+	"-codec":             true,
+	"-coder":             true,
+	"-compression_level": true,
+	"-cpu-used":          true,
+	"-cq":                true,
+	"-crf":               true,
+	"-flags":             true,
+	"-g":                 true,
+	"-keyint_min":        true,
+	"-level":             true,
+	"-lossless":          true,
+	"-maxrate":           true,
+	"-minrate":           true,
+	"-nal-hrd":           true,
+	"-preset":            true,
+	"-profile":           true,
+	"-quality":           true,
+	"-rc":                true,
+	"-sc_threshold":      true,
+	"-still-picture":     true,
+	"-strict":            true,
+	"-threads":           true,
+	"-tune":              true,
+	"-vbr":               true,
+	"-x265-params":       true,
+}
+
+// WriteFFPreset writes encoding options as an ffpreset file, which can then be used with EncodingOptions.PresetFiles
+// by legacy workflows
+// Since an ffpreset file applies to a single encoder, stream specifiers are dropped.
+func WriteFFPreset(path string, o EncodingOptions) (err error) {
+	// Create content
+	var b []byte
+	if b, err = ffpreset(o); err != nil {
+		err = fmt.Errorf("astiffmpeg: creating ffpreset failed: %w", err)
+		return
+	}
+
+	// Write
+	if err = os.WriteFile(path, b, 0644); err != nil {
+		err = fmt.Errorf("astiffmpeg: writing ffpreset to %s failed: %w", path, err)
+		return
+	}
+	return
+}
+
+func ffpreset(o EncodingOptions) (b []byte, err error) {
+	// Get args
+	cmd := &exec.Cmd{}
+	if err = o.adaptCmd(cmd); err != nil {
+		err = fmt.Errorf("astiffmpeg: adapting cmd failed: %w", err)
+		return
+	}
+
+	// Loop through options
+	buf := &bytes.Buffer{}
+	values := make(map[string]string)
+	for _, a := range parseOptionArgs(cmd.Args) {
+		// Option is not supported
+		if !ffpresetOptions[a.base()] || len(a.value) == 0 {
+			err = fmt.Errorf("astiffmpeg: option %s can't be written to an ffpreset file", a.name)
+			return
+		}
+
+		// Get key
+		k := strings.TrimPrefix(a.base(), "-")
+		if k == "codec" {
+			// Codec can only be set per media type
+			switch strings.TrimPrefix(a.canonicalName(), a.base()) {
+			case ":a":
+				k = "acodec"
+			case ":s":
+				k = "scodec"
+			case ":v":
+				k = "vcodec"
+			default:
+				err = fmt.Errorf("astiffmpeg: option %s can't be written to an ffpreset file", a.name)
+				return
+			}
+		}
+
+		// Check conflicts
+		if v, ok := values[k]; ok {
+			if v != a.value[0] {
+				err = fmt.Errorf("astiffmpeg: option %s is set several times with different values (%s and %s)", k, v, a.value[0])
+				return
+			}
+			continue
+		}
+		values[k] = a.value[0]
+
+		// Write
+		buf.WriteString(k + "=" + a.value[0] + "\n")
+	}
+	b = buf.Bytes()
+	return
+}
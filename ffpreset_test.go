@@ -0,0 +1,46 @@
+package astiffmpeg
+
+import (
+	"os/exec"
+	"reflect"
+	"testing"
+
+	"github.com/asticode/go-astikit"
+)
+
+func TestFFPreset(t *testing.T) {
+	b, err := ffpreset(EncodingOptions{
+		Bitrate: []StreamOption{videoStreamOption(Megabits(2))},
+		Codec:   []StreamOption{videoStreamOption(CodecLibx264)},
+		GOP:     astikit.IntPtr(50),
+		Preset:  PresetSlow,
+		Profile: ProfileHigh,
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	if e := "b=2M\nvcodec=libx264\ng=50\npreset=slow\nprofile=high\n"; string(b) != e {
+		t.Errorf("expected %q, got %q", e, b)
+	}
+	for _, o := range []EncodingOptions{
+		{Filters: []StreamOption{videoStreamOption("null")}},
+		{PixelFormat: PixelFormatYUV420P},
+		{Quality: []StreamOption{audioStreamOption(2)}},
+	} {
+		if _, err = ffpreset(o); err == nil {
+			t.Errorf("expected error for %+v", o)
+		}
+	}
+
+	cmd := exec.Command("ffmpeg")
+	if err = (EncodingOptions{
+		Codec:       []StreamOption{videoStreamOption(CodecLibx264)},
+		PresetFiles: []StreamOption{videoStreamOption("/presets/web.ffpreset")},
+		PresetNames: []StreamOption{audioStreamOption("voice")},
+	}).adaptCmd(cmd); err != nil {
+		t.Errorf("expected no error, got %s", err)
+	}
+	if e := []string{"ffmpeg", "-codec:v", "libx264", "-fpre:v", "/presets/web.ffpreset", "-pre:a", "voice"}; !reflect.DeepEqual(e, cmd.Args) {
+		t.Errorf("expected %+v, got %+v", e, cmd.Args)
+	}
+}
@@ -0,0 +1,275 @@
+package astiffmpeg
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/asticode/go-astikit"
+)
+
+// probeBinaryPath returns the ffprobe binary path, which defaults to the ffprobe binary located next to the ffmpeg
+// binary
+func probeBinaryPath(c Configuration) string {
+	if len(c.ProbeBinaryPath) > 0 {
+		return c.ProbeBinaryPath
+	}
+	if len(c.BinaryPath) == 0 {
+		return "ffprobe"
+	}
+	return filepath.Join(filepath.Dir(c.BinaryPath), strings.Replace(filepath.Base(c.BinaryPath), "ffmpeg", "ffprobe", 1))
+}
+
+// ProbePacket represents a packet as reported by ffprobe
+type ProbePacket struct {
+	CodecType   string
+	DTS         *time.Duration
+	Duration    *time.Duration
+	Flags       string
+	Keyframe    bool
+	PTS         *time.Duration
+	Size        int // bytes
+	StreamIndex int
+}
+
+// ProbeFrame represents a frame as reported by ffprobe
+type ProbeFrame struct {
+	Height      *int
+	Keyframe    bool
+	MediaType   string
+	PictureType string
+	PTS         *time.Duration
+	Size        int // Size of the packet the frame was decoded from, in bytes
+	StreamIndex int
+	Width       *int
+}
+
+// ProbeStream represents a stream as reported by ffprobe
+type ProbeStream struct {
+	// Average frame rate, computed from timestamps
+	AvgFrameRate *Rational
+	Channels     *int
+	CodecName    string
+	CodecType    string
+	// Only set when the stream carries a Dolby Vision configuration record
+	DolbyVisionProfile *int
+	Index              int
+	Level              *int // As reported by ffprobe, e.g. 41 for H.264 level 4.1 or 123 for HEVC level 4.1
+	PixelFormat        string
+	Profile            string
+	// Lowest frame rate with which all timestamps can be represented, usually the nominal frame rate
+	RFrameRate *Rational
+	SampleRate *int
+}
+
+// ProbeStreamOptions represents probe stream options
+type ProbeStreamOptions struct {
+	// Only reads the specified intervals (e.g. "30%+10" or "%+#100"), see ffprobe's -read_intervals
+	ReadIntervals string
+	// Only shows the specified streams
+	Stream *StreamSpecifier
+}
+
+// StreamPackets runs "ffprobe -show_packets" and delivers packets over the returned channel while they're read,
+// which means the whole output is never loaded in memory
+// The channel is closed once ffprobe has exited, the returned job can then be used to retrieve its error. The
+// channel must be drained until it's closed, or the context cancelled, otherwise ffprobe blocks.
+func (f *FFMpeg) StreamPackets(ctx context.Context, path string, o ProbeStreamOptions) (<-chan ProbePacket, *Job, error) {
+	ch := make(chan ProbePacket)
+	j, err := f.streamProbe(ctx, path, "-show_packets", o, func(m map[string]string) {
+		select {
+		case ch <- newProbePacket(m):
+		case <-ctx.Done():
+		}
+	}, func() { close(ch) })
+	return ch, j, err
+}
+
+// StreamFrames runs "ffprobe -show_frames" and delivers frames over the returned channel while they're read, which
+// means the whole output is never loaded in memory
+// The channel is closed once ffprobe has exited, the returned job can then be used to retrieve its error. The
+// channel must be drained until it's closed, or the context cancelled, otherwise ffprobe blocks.
+func (f *FFMpeg) StreamFrames(ctx context.Context, path string, o ProbeStreamOptions) (<-chan ProbeFrame, *Job, error) {
+	ch := make(chan ProbeFrame)
+	j, err := f.streamProbe(ctx, path, "-show_frames", o, func(m map[string]string) {
+		select {
+		case ch <- newProbeFrame(m):
+		case <-ctx.Done():
+		}
+	}, func() { close(ch) })
+	return ch, j, err
+}
+
+// ProbeStreams runs "ffprobe -show_streams" and returns the input's streams
+func (f *FFMpeg) ProbeStreams(ctx context.Context, path string) (ss []ProbeStream, err error) {
+	// Stream
+	var j *Job
+	done := make(chan struct{})
+	if j, err = f.streamProbe(ctx, path, "-show_streams", ProbeStreamOptions{}, func(m map[string]string) {
+		ss = append(ss, newProbeStream(m))
+	}, func() { close(done) }); err != nil {
+		err = fmt.Errorf("astiffmpeg: streaming probe failed: %w", err)
+		return
+	}
+
+	// Wait
+	err = j.Wait()
+	<-done
+	if err != nil {
+		err = fmt.Errorf("astiffmpeg: waiting failed: %w", err)
+		return
+	}
+	return
+}
+
+func (f *FFMpeg) streamProbe(ctx context.Context, path, show string, o ProbeStreamOptions, fn func(m map[string]string), done func()) (j *Job, err error) {
+	// Check binary path
+	if err = checkBinaryPath(f.probeBinaryPath); err != nil {
+		return
+	}
+
+	// Create cmd
+	var cmd = exec.CommandContext(ctx, f.probeBinaryPath, "-hide_banner", "-loglevel", "error", show, "-print_format", "compact")
+	cmd.Env = os.Environ()
+	var bufErr = &syncBuffer{}
+	cmd.Stderr = bufErr
+
+	// Options
+	if len(o.ReadIntervals) > 0 {
+		cmd.Args = append(cmd.Args, "-read_intervals", o.ReadIntervals)
+	}
+	if o.Stream != nil {
+		cmd.Args = append(cmd.Args, "-select_streams", o.Stream.string())
+	}
+	cmd.Args = append(cmd.Args, path)
+
+	// Platform specific adaptation
+	prepareCmd(cmd)
+
+	// Stdout is read through a pipe so that cmd.Wait returns only once everything has been read
+	pr, pw := io.Pipe()
+	cmd.Stdout = pw
+
+	// Start cmd
+	if err = cmd.Start(); err != nil {
+		err = fmt.Errorf("astiffmpeg: starting %s failed: %w", cmd.String(), err)
+		return
+	}
+
+	// Create job
+	j = newJob(ctx, cmd, bufErr, nil, nil, nil)
+
+	// Close pipe once cmd has exited
+	go func() {
+		j.Wait()
+		pw.Close()
+	}()
+
+	// Read
+	go func() {
+		defer done()
+		s := bufio.NewScanner(pr)
+		s.Buffer(make([]byte, 64*1024), 1024*1024)
+		for s.Scan() {
+			if m := parseProbeCompactLine(s.Text()); m != nil {
+				fn(m)
+			}
+		}
+		// Make sure ffprobe is not blocked writing to the pipe
+		io.Copy(io.Discard, pr)
+	}()
+	return
+}
+
+// packet|codec_type=video|stream_index=0|pts=0|pts_time=0.000000|dts=-1024|dts_time=-0.080000|flags=K__
+func parseProbeCompactLine(l string) (m map[string]string) {
+	ps := strings.Split(l, "|")
+	if len(ps) < 2 {
+		return
+	}
+	m = make(map[string]string)
+	for _, p := range ps[1:] {
+		if idx := strings.Index(p, "="); idx > -1 {
+			m[p[:idx]] = p[idx+1:]
+		}
+	}
+	return
+}
+
+func probeDuration(v string) *time.Duration {
+	if f, err := strconv.ParseFloat(v, 64); err == nil {
+		return astikit.DurationPtr(time.Duration(f * float64(time.Second)))
+	}
+	return nil
+}
+
+func newProbePacket(m map[string]string) (p ProbePacket) {
+	p = ProbePacket{
+		CodecType: m["codec_type"],
+		DTS:       probeDuration(m["dts_time"]),
+		Duration:  probeDuration(m["duration_time"]),
+		Flags:     m["flags"],
+		PTS:       probeDuration(m["pts_time"]),
+	}
+	p.Keyframe = strings.HasPrefix(p.Flags, "K")
+	p.Size, _ = strconv.Atoi(m["size"])
+	p.StreamIndex, _ = strconv.Atoi(m["stream_index"])
+	return
+}
+
+func newProbeStream(m map[string]string) (s ProbeStream) {
+	s = ProbeStream{
+		CodecName:   m["codec_name"],
+		CodecType:   m["codec_type"],
+		PixelFormat: m["pix_fmt"],
+		Profile:     m["profile"],
+	}
+	s.AvgFrameRate = parseRational(m["avg_frame_rate"])
+	s.Index, _ = strconv.Atoi(m["index"])
+	s.RFrameRate = parseRational(m["r_frame_rate"])
+	if v, err := strconv.Atoi(m["channels"]); err == nil {
+		s.Channels = astikit.IntPtr(v)
+	}
+	if v, err := strconv.Atoi(m["dv_profile"]); err == nil {
+		s.DolbyVisionProfile = astikit.IntPtr(v)
+	}
+	if v, err := strconv.Atoi(m["level"]); err == nil && v > 0 {
+		s.Level = astikit.IntPtr(v)
+	}
+	if v, err := strconv.Atoi(m["sample_rate"]); err == nil {
+		s.SampleRate = astikit.IntPtr(v)
+	}
+	return
+}
+
+func newProbeFrame(m map[string]string) (f ProbeFrame) {
+	f = ProbeFrame{
+		Keyframe:    m["key_frame"] == "1",
+		MediaType:   m["media_type"],
+		PictureType: m["pict_type"],
+		PTS:         probeDuration(m["pts_time"]),
+	}
+	if f.PTS == nil {
+		// Older ffprobe versions
+		f.PTS = probeDuration(m["pkt_pts_time"])
+	}
+	if f.PTS == nil {
+		f.PTS = probeDuration(m["best_effort_timestamp_time"])
+	}
+	f.Size, _ = strconv.Atoi(m["pkt_size"])
+	f.StreamIndex, _ = strconv.Atoi(m["stream_index"])
+	if v, err := strconv.Atoi(m["height"]); err == nil {
+		f.Height = astikit.IntPtr(v)
+	}
+	if v, err := strconv.Atoi(m["width"]); err == nil {
+		f.Width = astikit.IntPtr(v)
+	}
+	return
+}
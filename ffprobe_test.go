@@ -0,0 +1,48 @@
+package astiffmpeg
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/asticode/go-astikit"
+)
+
+func TestNewProbePacket(t *testing.T) {
+	if m := parseProbeCompactLine("invalid"); m != nil {
+		t.Errorf("expected nil, got %+v", m)
+	}
+	p := newProbePacket(parseProbeCompactLine("packet|codec_type=video|stream_index=0|pts=0|pts_time=0.040000|dts=-1024|dts_time=-0.080000|duration=512|duration_time=0.040000|size=12345|pos=48|flags=K__"))
+	e := ProbePacket{
+		CodecType: "video",
+		DTS:       astikit.DurationPtr(-80 * time.Millisecond),
+		Duration:  astikit.DurationPtr(40 * time.Millisecond),
+		Flags:     "K__",
+		Keyframe:  true,
+		PTS:       astikit.DurationPtr(40 * time.Millisecond),
+		Size:      12345,
+	}
+	if !reflect.DeepEqual(e, p) {
+		t.Errorf("expected %+v, got %+v", e, p)
+	}
+}
+
+func TestNewProbeStream(t *testing.T) {
+	s := newProbeStream(parseProbeCompactLine("stream|index=1|codec_name=h264|profile=High|codec_type=video|width=1920|height=1080|pix_fmt=yuv420p|level=41|r_frame_rate=24000/1001|avg_frame_rate=0/0|disposition:default=1"))
+	e := ProbeStream{
+		CodecName:   "h264",
+		CodecType:   "video",
+		Index:       1,
+		Level:       astikit.IntPtr(41),
+		PixelFormat: "yuv420p",
+		Profile:     "High",
+		RFrameRate:  &Rational{Den: 1001, Num: 24000},
+	}
+	if !reflect.DeepEqual(e, s) {
+		t.Errorf("expected %+v, got %+v", e, s)
+	}
+	s = newProbeStream(parseProbeCompactLine("stream|index=0|codec_name=hevc|codec_type=video|side_data|side_data_type=DOVI configuration record|dv_version_major=1|dv_profile=8"))
+	if s.DolbyVisionProfile == nil || *s.DolbyVisionProfile != 8 {
+		t.Errorf("expected dolby vision profile 8, got %v", s.DolbyVisionProfile)
+	}
+}
@@ -0,0 +1,58 @@
+package astiffmpeg
+
+import (
+	"fmt"
+	"os"
+)
+
+// FIFO represents a named pipe that can be used as an input or output path, which allows composing multi-process
+// pipelines (e.g. ffmpeg → packager) without filling disks
+// On Unix it's a FIFO created with mkfifo in the temporary directory, and both ends can be opened by other processes.
+// On Windows it's a named pipe (\\.\pipe\<name>) whose server end is owned by this process and therefore must be
+// opened with Open, ffmpeg opening the client end.
+type FIFO struct {
+	fifo
+	path string
+}
+
+// NewFIFO creates a named pipe
+func NewFIFO(name string) (f *FIFO, err error) {
+	f = &FIFO{}
+	if err = f.create(name); err != nil {
+		err = fmt.Errorf("astiffmpeg: creating fifo failed: %w", err)
+		return
+	}
+	return
+}
+
+// Path returns the path of the named pipe
+func (f *FIFO) Path() string {
+	return f.path
+}
+
+// Input creates an input reading from the named pipe. Format should be set since it can't be probed from the path.
+func (f *FIFO) Input(o *InputOptions) Input {
+	return Input{
+		Options: o,
+		Path:    f.path,
+	}
+}
+
+// Output creates an output writing to the named pipe. Format should be set since it can't be inferred from the path.
+func (f *FIFO) Output(o *OutputOptions) Output {
+	return Output{
+		Options: o,
+		Path:    f.path,
+	}
+}
+
+// Open opens the end of the named pipe used from Go, with flag being either os.O_RDONLY or os.O_WRONLY
+// It blocks until the other end is opened.
+func (f *FIFO) Open(flag int) (*os.File, error) {
+	return f.open(flag)
+}
+
+// Close removes the named pipe
+func (f *FIFO) Close() error {
+	return f.close()
+}
@@ -0,0 +1,40 @@
+//go:build !windows
+// +build !windows
+
+package astiffmpeg
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+type fifo struct {
+	dir string
+}
+
+func (f *FIFO) create(name string) (err error) {
+	// Create dir so that names can't collide
+	if f.dir, err = os.MkdirTemp("", "astiffmpeg-fifo-"); err != nil {
+		err = fmt.Errorf("astiffmpeg: creating temp dir failed: %w", err)
+		return
+	}
+
+	// Make fifo
+	f.path = filepath.Join(f.dir, name)
+	if err = syscall.Mkfifo(f.path, 0600); err != nil {
+		os.RemoveAll(f.dir)
+		err = fmt.Errorf("astiffmpeg: mkfifo %s failed: %w", f.path, err)
+		return
+	}
+	return
+}
+
+func (f *FIFO) open(flag int) (*os.File, error) {
+	return os.OpenFile(f.path, flag, 0)
+}
+
+func (f *FIFO) close() error {
+	return os.RemoveAll(f.dir)
+}
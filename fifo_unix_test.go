@@ -0,0 +1,43 @@
+//go:build !windows
+// +build !windows
+
+package astiffmpeg
+
+import (
+	"io"
+	"os"
+	"testing"
+)
+
+func TestFIFO(t *testing.T) {
+	f, err := NewFIFO("test")
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	go func() {
+		w, err := os.OpenFile(f.Path(), os.O_WRONLY, 0)
+		if err != nil {
+			return
+		}
+		defer w.Close()
+		w.Write([]byte("test"))
+	}()
+	r, err := f.Open(os.O_RDONLY)
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	b, err := io.ReadAll(r)
+	r.Close()
+	if err != nil {
+		t.Errorf("expected no error, got %s", err)
+	}
+	if e, g := "test", string(b); e != g {
+		t.Errorf("expected %s, got %s", e, g)
+	}
+	if err = f.Close(); err != nil {
+		t.Errorf("expected no error, got %s", err)
+	}
+	if _, err = os.Stat(f.Path()); !os.IsNotExist(err) {
+		t.Error("expected fifo to be removed")
+	}
+}
@@ -0,0 +1,75 @@
+//go:build windows
+// +build windows
+
+package astiffmpeg
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	procConnectNamedPipe = kernel32.NewProc("ConnectNamedPipe")
+	procCreateNamedPipeW = kernel32.NewProc("CreateNamedPipeW")
+)
+
+const (
+	errorPipeConnected = syscall.Errno(535)
+	pipeAccessDuplex   = 0x00000003
+	pipeTypeByte       = 0x00000000
+	pipeWait           = 0x00000000
+)
+
+type fifo struct {
+	h      syscall.Handle
+	opened bool
+}
+
+func (f *FIFO) create(name string) (err error) {
+	// Get path
+	f.path = `\\.\pipe\` + name
+	var p *uint16
+	if p, err = syscall.UTF16PtrFromString(f.path); err != nil {
+		err = fmt.Errorf("astiffmpeg: converting %s failed: %w", f.path, err)
+		return
+	}
+
+	// Create named pipe right away so that ffmpeg can open it as soon as it's started
+	h, _, errCreate := procCreateNamedPipeW.Call(uintptr(unsafe.Pointer(p)), pipeAccessDuplex, pipeTypeByte|pipeWait, 1, 1<<16, 1<<16, 0, 0)
+	if syscall.Handle(h) == syscall.InvalidHandle {
+		err = fmt.Errorf("astiffmpeg: creating named pipe %s failed: %w", f.path, errCreate)
+		return
+	}
+	f.h = syscall.Handle(h)
+	return
+}
+
+func (f *FIFO) open(flag int) (fl *os.File, err error) {
+	// Named pipe can only be opened once
+	if f.opened {
+		err = errors.New("astiffmpeg: named pipe is already opened")
+		return
+	}
+
+	// Wait for the client to connect
+	if r, _, errConnect := procConnectNamedPipe.Call(uintptr(f.h), 0); r == 0 && !errors.Is(errConnect, errorPipeConnected) {
+		err = fmt.Errorf("astiffmpeg: connecting named pipe %s failed: %w", f.path, errConnect)
+		return
+	}
+
+	// Handle is now owned by the file
+	f.opened = true
+	fl = os.NewFile(uintptr(f.h), f.path)
+	return
+}
+
+func (f *FIFO) close() error {
+	// Named pipe is removed once all its handles are closed
+	if f.opened {
+		return nil
+	}
+	return syscall.CloseHandle(f.h)
+}
@@ -0,0 +1,66 @@
+package astiffmpeg
+
+import (
+	"sort"
+	"strings"
+)
+
+// KV represents a key/value pair
+type KV struct {
+	Key   string
+	Value string
+}
+
+// GenericFilter represents any filter, including the ones that are not typed yet
+// Values are escaped so that they can safely contain special characters such as colons, commas, quotes or
+// backslashes
+type GenericFilter struct {
+	// Arguments whose order doesn't matter. They're added after ordered arguments, sorted by key.
+	Args map[string]string
+	Name string
+	// Arguments whose order matters. When the key is empty, only the value is added which allows providing
+	// positional arguments.
+	Ordered []KV
+}
+
+// String returns the filter description, escaped so that it can be used in a filter graph such as
+// ComplexFilterOption.Filters
+func (f GenericFilter) String() string {
+	// Add ordered arguments
+	var ss []string
+	for _, kv := range f.Ordered {
+		v := escapeFilterValue(kv.Value)
+		if len(kv.Key) > 0 {
+			v = kv.Key + "=" + v
+		}
+		ss = append(ss, v)
+	}
+
+	// Add other arguments
+	var ks []string
+	for k := range f.Args {
+		ks = append(ks, k)
+	}
+	sort.Strings(ks)
+	for _, k := range ks {
+		ss = append(ss, k+"="+escapeFilterValue(f.Args[k]))
+	}
+
+	// No arguments
+	if len(ss) == 0 {
+		return f.Name
+	}
+	return f.Name + "=" + strings.Join(ss, ":")
+}
+
+var (
+	// Escapes special characters of the filter arguments
+	filterValueReplacer = strings.NewReplacer(`\`, `\\`, `'`, `\'`, `:`, `\:`)
+	// Escapes special characters of the filter graph
+	filterGraphReplacer = strings.NewReplacer(`\`, `\\`, `'`, `\'`, `[`, `\[`, `]`, `\]`, `,`, `\,`, `;`, `\;`)
+)
+
+// https://ffmpeg.org/ffmpeg-filters.html#Notes-on-filtergraph-escaping
+func escapeFilterValue(v string) string {
+	return filterGraphReplacer.Replace(filterValueReplacer.Replace(v))
+}
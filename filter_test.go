@@ -0,0 +1,24 @@
+package astiffmpeg
+
+import "testing"
+
+func TestGenericFilter(t *testing.T) {
+	f := GenericFilter{
+		Args: map[string]string{
+			"y":         "10",
+			"x":         "(w-text_w)/2",
+			"fontcolor": "white",
+		},
+		Name: "drawtext",
+		Ordered: []KV{
+			{Key: "text", Value: "this is a 'string': may contain one, or more, special characters"},
+		},
+	}
+	e := `drawtext=text=this is a \\\'string\\\'\\: may contain one\, or more\, special characters:fontcolor=white:x=(w-text_w)/2:y=10`
+	if g := f.String(); g != e {
+		t.Errorf("expected %s, got %s", e, g)
+	}
+	if e, g := "hflip", (GenericFilter{Name: "hflip"}).String(); g != e {
+		t.Errorf("expected %s, got %s", e, g)
+	}
+}
@@ -0,0 +1,176 @@
+package astiffmpeg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FrameRange represents cut points expressed in frames, which is what editors work with
+// Start is inclusive and End is exclusive. An End of 0 means the end of the input.
+type FrameRange struct {
+	End   int
+	Start int
+}
+
+// Frames returns the number of frames of the range, 0 meaning until the end of the input
+func (r FrameRange) Frames() int {
+	if r.End <= r.Start {
+		return 0
+	}
+	return r.End - r.Start
+}
+
+// Position returns the position of the first frame of the range
+// Half a frame is removed so that rounding can't make the seek skip the first frame
+func (r FrameRange) Position(fps float64) time.Duration {
+	if r.Start <= 0 || fps <= 0 {
+		return 0
+	}
+	return time.Duration(math.Round((float64(r.Start) - 0.5) / fps * float64(time.Second)))
+}
+
+// Duration returns the duration of the range, 0 meaning until the end of the input
+func (r FrameRange) Duration(fps float64) time.Duration {
+	if fps <= 0 {
+		return 0
+	}
+	return time.Duration(math.Round(float64(r.Frames()) / fps * float64(time.Second)))
+}
+
+// Select returns a select filter expression matching the frames of the range by frame number, which doesn't require
+// knowing the frame rate but decodes the input from its beginning. It should be followed by setpts=N/FRAME_RATE/TB.
+func (r FrameRange) Select() string {
+	if r.Frames() == 0 {
+		return "'gte(n," + strconv.Itoa(r.Start) + ")'"
+	}
+	return "'between(n," + strconv.Itoa(r.Start) + "," + strconv.Itoa(r.End-1) + ")'"
+}
+
+// Rational represents a rational number such as a frame rate (e.g. 24000/1001)
+type Rational struct {
+	Den int
+	Num int
+}
+
+func parseRational(s string) (r *Rational) {
+	ps := strings.Split(s, "/")
+	if len(ps) != 2 {
+		return
+	}
+	num, err := strconv.Atoi(ps[0])
+	if err != nil {
+		return
+	}
+	den, err := strconv.Atoi(ps[1])
+	if err != nil || den <= 0 || num <= 0 {
+		return
+	}
+	return &Rational{Den: den, Num: num}
+}
+
+// IsZero checks whether the rational is not set
+func (r Rational) IsZero() bool {
+	return r.Den == 0 || r.Num == 0
+}
+
+// Float64 returns the value of the rational, 0 when it's not set
+func (r Rational) Float64() float64 {
+	if r.IsZero() {
+		return 0
+	}
+	return float64(r.Num) / float64(r.Den)
+}
+
+// String returns the rational as "num/den", which ffmpeg options and filters accept without loss of precision
+func (r Rational) String() string {
+	return strconv.Itoa(r.Num) + "/" + strconv.Itoa(r.Den)
+}
+
+// FrameRate probes the frame rate of the first video stream of the specified input with ffprobe, and returns it as
+// a ratio since frame rates printed by ffmpeg are rounded (e.g. 23.98 instead of 24000/1001) which makes frame
+// accurate computations drift
+func (f *FFMpeg) FrameRate(ctx context.Context, in Input) (r Rational, err error) {
+	// Check input
+	if err = in.checkProbable(); err != nil {
+		return
+	}
+
+	// Probe
+	var ss []ProbeStream
+	if ss, err = f.ProbeStreams(ctx, in.Path); err != nil {
+		err = fmt.Errorf("astiffmpeg: probing streams failed: %w", err)
+		return
+	}
+
+	// Loop through streams
+	for _, s := range ss {
+		if s.CodecType != "video" {
+			continue
+		}
+		// The real base frame rate is more accurate than the average one, which is computed from timestamps, but it
+		// may not be set
+		if s.RFrameRate != nil {
+			r = *s.RFrameRate
+			return
+		} else if s.AvgFrameRate != nil {
+			r = *s.AvgFrameRate
+			return
+		}
+	}
+	err = errors.New("astiffmpeg: no frame rate found")
+	return
+}
+
+// TrimFrames extracts the specified frame range of the input
+// The input frame rate is probed first and the range is converted into an input position and a number of video
+// frames, which is frame accurate as long as the video is transcoded
+func (f *FFMpeg) TrimFrames(ctx context.Context, g GlobalOptions, in Input, r FrameRange, o OutputOptions, outputPath string) (err error) {
+	// Probe frame rate
+	var fr Rational
+	if fr, err = f.FrameRate(ctx, in); err != nil {
+		err = fmt.Errorf("astiffmpeg: probing frame rate failed: %w", err)
+		return
+	}
+
+	// Exec
+	in, o = frameRangeOptions(in, o, r, fr.Float64())
+	if err = f.Exec(ctx, g, []Input{in}, Output{
+		Options: &o,
+		Path:    outputPath,
+	}); err != nil {
+		err = fmt.Errorf("astiffmpeg: executing failed: %w", err)
+		return
+	}
+	return
+}
+
+func frameRangeOptions(in Input, o OutputOptions, r FrameRange, fps float64) (Input, OutputOptions) {
+	// Seek input without altering provided options
+	io := InputOptions{}
+	if in.Options != nil {
+		io = *in.Options
+	}
+	do := DecodingOptions{}
+	if io.Decoding != nil {
+		do = *io.Decoding
+	}
+	do.Position = r.Position(fps)
+	io.Decoding = &do
+	in.Options = &io
+
+	// Limit output
+	if n := r.Frames(); n > 0 {
+		eo := copyEncodingOptions(o.Encoding)
+		eo.Frames = append(append([]StreamOption{}, eo.Frames...), videoStreamOption(n))
+		o.Encoding = eo
+
+		// Other streams are not limited by the number of video frames
+		o.Duration = r.Duration(fps)
+	}
+	return in, o
+}
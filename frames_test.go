@@ -0,0 +1,58 @@
+package astiffmpeg
+
+import (
+	"os/exec"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFrameRange(t *testing.T) {
+	r := FrameRange{End: 75, Start: 25}
+	if e, g := 50, r.Frames(); e != g {
+		t.Errorf("expected %d, got %d", e, g)
+	}
+	if e, g := 980*time.Millisecond, r.Position(25); e != g {
+		t.Errorf("expected %s, got %s", e, g)
+	}
+	if e, g := 2*time.Second, r.Duration(25); e != g {
+		t.Errorf("expected %s, got %s", e, g)
+	}
+	if e, g := "'between(n,25,74)'", r.Select(); e != g {
+		t.Errorf("expected %s, got %s", e, g)
+	}
+	if e, g := "'gte(n,25)'", (FrameRange{Start: 25}).Select(); e != g {
+		t.Errorf("expected %s, got %s", e, g)
+	}
+
+	in, o := frameRangeOptions(Input{Path: "input.mp4"}, OutputOptions{}, r, 25)
+	cmd := exec.Command("ffmpeg")
+	if err := in.adaptCmd(cmd); err != nil {
+		t.Errorf("expected no error, got %s", err)
+	}
+	if err := o.adaptCmd(cmd); err != nil {
+		t.Errorf("expected no error, got %s", err)
+	}
+	e := []string{"ffmpeg", "-ss", "0.980", "-i", "input.mp4", "-frames:v", "50", "-t", "2.000"}
+	if !reflect.DeepEqual(e, cmd.Args) {
+		t.Errorf("expected %+v, got %+v", e, cmd.Args)
+	}
+}
+
+func TestRational(t *testing.T) {
+	r := parseRational("24000/1001")
+	if r == nil {
+		t.Fatal("expected rational")
+	}
+	if e, g := "24000/1001", r.String(); e != g {
+		t.Errorf("expected %s, got %s", e, g)
+	}
+	if e, g := 24000.0/1001, r.Float64(); e != g {
+		t.Errorf("expected %v, got %v", e, g)
+	}
+	for _, s := range []string{"0/0", "25", "a/b"} {
+		if r = parseRational(s); r != nil {
+			t.Errorf("%s: expected nil, got %+v", s, r)
+		}
+	}
+}
@@ -2,4 +2,8 @@ module github.com/asticode/go-astiffmpeg
 
 go 1.16
 
-require github.com/asticode/go-astikit v0.21.0
+require (
+	github.com/asticode/go-astikit v0.21.0
+	github.com/fsnotify/fsnotify v1.6.0
+	golang.org/x/sys v0.7.0 // indirect
+)
@@ -0,0 +1,144 @@
+package astiffmpeg
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Hash algorithms
+const (
+	HashAlgorithmCRC32  = "CRC32"
+	HashAlgorithmMD5    = "MD5"
+	HashAlgorithmSHA256 = "SHA256"
+)
+
+// FrameHash represents the hash of a frame as written by the framehash muxer
+type FrameHash struct {
+	DTS         int64 // In stream time base
+	Duration    int64 // In stream time base
+	Hash        string
+	PTS         int64 // In stream time base
+	Size        int   // bytes
+	StreamIndex int
+}
+
+// ContentHash computes a hash of all decoded frames of the input, using the hash muxer
+// Since frames are decoded, it can be compared between outputs of different encoder versions to make sure they're
+// byte exact.
+func (f *FFMpeg) ContentHash(ctx context.Context, g GlobalOptions, in Input, algorithm string) (h string, err error) {
+	// Exec
+	var b []byte
+	if b, err = f.execHash(ctx, g, in, "hash", algorithm); err != nil {
+		err = fmt.Errorf("astiffmpeg: executing hash failed: %w", err)
+		return
+	}
+
+	// Parse
+	if h, err = parseContentHash(b); err != nil {
+		err = fmt.Errorf("astiffmpeg: parsing content hash failed: %w", err)
+		return
+	}
+	return
+}
+
+// FrameHashes computes a hash of each decoded frame of the input, using the framehash muxer
+func (f *FFMpeg) FrameHashes(ctx context.Context, g GlobalOptions, in Input, algorithm string) (hs []FrameHash, err error) {
+	// Exec
+	var b []byte
+	if b, err = f.execHash(ctx, g, in, "framehash", algorithm); err != nil {
+		err = fmt.Errorf("astiffmpeg: executing framehash failed: %w", err)
+		return
+	}
+
+	// Parse
+	if hs, err = parseFrameHashes(b); err != nil {
+		err = fmt.Errorf("astiffmpeg: parsing frame hashes failed: %w", err)
+		return
+	}
+	return
+}
+
+func (f *FFMpeg) execHash(ctx context.Context, g GlobalOptions, in Input, format, algorithm string) (b []byte, err error) {
+	// Start job
+	buf := &bytes.Buffer{}
+	var j *Job
+	if j, err = f.ExecAsyncWithOptions(ctx, g, []Input{in}, Output{
+		Options: &OutputOptions{
+			Format: format,
+			Muxing: &MuxingOptions{Hash: algorithm},
+		},
+		Path: "pipe:1",
+	}, ExecOptions{Stdout: buf}); err != nil {
+		err = fmt.Errorf("astiffmpeg: starting job failed: %w", err)
+		return
+	}
+
+	// Wait
+	if err = j.Wait(); err != nil {
+		err = fmt.Errorf("astiffmpeg: waiting for job failed: %w", err)
+		return
+	}
+	b = buf.Bytes()
+	return
+}
+
+// SHA256=8a3c...
+func parseContentHash(b []byte) (string, error) {
+	l := strings.TrimSpace(string(b))
+	idx := strings.Index(l, "=")
+	if idx == -1 {
+		return "", fmt.Errorf("astiffmpeg: invalid hash output %s", l)
+	}
+	return l[idx+1:], nil
+}
+
+// 0,          0,          0,        1,   115200, 2d6c...
+func parseFrameHashes(b []byte) (hs []FrameHash, err error) {
+	s := bufio.NewScanner(bytes.NewReader(b))
+	for s.Scan() {
+		// Skip headers
+		l := strings.TrimSpace(s.Text())
+		if len(l) == 0 || strings.HasPrefix(l, "#") {
+			continue
+		}
+
+		// Split
+		ps := strings.Split(l, ",")
+		if len(ps) != 6 {
+			err = fmt.Errorf("astiffmpeg: invalid framehash line %s", l)
+			return
+		}
+		for idx := range ps {
+			ps[idx] = strings.TrimSpace(ps[idx])
+		}
+
+		// Parse
+		h := FrameHash{Hash: ps[5]}
+		if h.StreamIndex, err = strconv.Atoi(ps[0]); err != nil {
+			err = fmt.Errorf("astiffmpeg: atoi %s failed: %w", ps[0], err)
+			return
+		}
+		if h.DTS, err = strconv.ParseInt(ps[1], 10, 64); err != nil {
+			err = fmt.Errorf("astiffmpeg: parsing int %s failed: %w", ps[1], err)
+			return
+		}
+		if h.PTS, err = strconv.ParseInt(ps[2], 10, 64); err != nil {
+			err = fmt.Errorf("astiffmpeg: parsing int %s failed: %w", ps[2], err)
+			return
+		}
+		if h.Duration, err = strconv.ParseInt(ps[3], 10, 64); err != nil {
+			err = fmt.Errorf("astiffmpeg: parsing int %s failed: %w", ps[3], err)
+			return
+		}
+		if h.Size, err = strconv.Atoi(ps[4]); err != nil {
+			err = fmt.Errorf("astiffmpeg: atoi %s failed: %w", ps[4], err)
+			return
+		}
+		hs = append(hs, h)
+	}
+	return
+}
@@ -0,0 +1,38 @@
+package astiffmpeg
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseContentHash(t *testing.T) {
+	h, err := parseContentHash([]byte("SHA256=8a3c1f\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := "8a3c1f"; h != e {
+		t.Errorf("expected %s, got %s", e, h)
+	}
+}
+
+func TestParseFrameHashes(t *testing.T) {
+	hs, err := parseFrameHashes([]byte(`#format: frame checksums
+#version: 2
+#hash: MD5
+#tb 0: 1/25
+#media_type 0: video
+#stream#, dts,        pts, duration,     size, hash
+0,          0,          0,        1,   115200, 2d6c1a
+0,          1,          1,        1,   115200, 3e7d2b
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := []FrameHash{
+		{Duration: 1, Hash: "2d6c1a", Size: 115200},
+		{DTS: 1, Duration: 1, Hash: "3e7d2b", PTS: 1, Size: 115200},
+	}
+	if !reflect.DeepEqual(e, hs) {
+		t.Errorf("expected %+v, got %+v", e, hs)
+	}
+}
@@ -0,0 +1,65 @@
+package astiffmpeg
+
+import (
+	"strings"
+)
+
+// HDRRemuxOutputOptions creates output options remuxing all streams of the first input without touching them, so
+// that Dolby Vision and HDR10+ metadata are preserved
+// For mp4 and mov, unofficial Dolby Vision boxes are allowed and, based on the probed video stream, HEVC video is
+// tagged dvh1 if it carries Dolby Vision and hvc1 otherwise (required by Apple players). Other codecs keep their tag.
+func HDRRemuxOutputOptions(format string, video ProbeStream) OutputOptions {
+	o := OutputOptions{
+		Encoding: &EncodingOptions{Codec: []StreamOption{{Value: CodecCopy}}},
+		Format:   format,
+		Map:      &MapOptions{{}},
+	}
+	if format == "mp4" || format == "mov" {
+		o.Encoding.Strict = StrictUnofficial
+		if video.CodecName == CodecNameHEVC {
+			if video.DolbyVisionProfile != nil {
+				o.Encoding.Tags = []StreamOption{videoStreamOption("dvh1")}
+			} else {
+				o.Encoding.Tags = []StreamOption{videoStreamOption("hvc1")}
+			}
+		}
+	}
+	return o
+}
+
+// HDRPassthroughWarnings returns the reasons why the output options would lose Dolby Vision or HDR10+ metadata
+func HDRPassthroughWarnings(o OutputOptions) (ws []string) {
+	// Get video codec and tag
+	var codec, tag string
+	if o.Encoding != nil {
+		codec = videoStreamOptionValue(o.Encoding.Codec)
+		tag = videoStreamOptionValue(o.Encoding.Tags)
+		if len(o.Encoding.Filters) > 0 || len(o.Encoding.ComplexFilter) > 0 || len(o.Encoding.ComplexFilters) > 0 {
+			ws = append(ws, "filters require re-encoding the video")
+		}
+	}
+
+	// Video is re-encoded
+	if codec != CodecCopy {
+		ws = append(ws, "re-encoding the video drops Dolby Vision RPUs and HDR10+ dynamic metadata")
+	}
+
+	// Video is not tagged properly
+	if (o.Format == "mp4" || o.Format == "mov") && tag != "hvc1" && tag != "dvh1" {
+		ws = append(ws, "HEVC video should be tagged hvc1 or dvh1 for Apple players")
+	}
+	return
+}
+
+// videoStreamOptionValue returns the value of the last string stream option applying to video streams
+func videoStreamOptionValue(sos []StreamOption) (v string) {
+	for _, so := range sos {
+		if so.Stream != nil && len(so.Stream.Name) == 0 && so.Stream.Type != StreamSpecifierTypeVideo && so.Stream.Type != StreamSpecifierTypeVideoAndNotThumbnail {
+			continue
+		}
+		if s, ok := so.Value.(string); ok {
+			v = strings.TrimSpace(s)
+		}
+	}
+	return
+}
@@ -0,0 +1,41 @@
+package astiffmpeg
+
+import (
+	"testing"
+
+	"github.com/asticode/go-astikit"
+)
+
+func TestHDRPassthroughWarnings(t *testing.T) {
+	hevc := ProbeStream{CodecName: CodecNameHEVC, CodecType: "video"}
+	if ws := HDRPassthroughWarnings(HDRRemuxOutputOptions("mp4", hevc)); len(ws) > 0 {
+		t.Errorf("expected no warnings, got %+v", ws)
+	}
+	if ws := HDRPassthroughWarnings(HDRRemuxOutputOptions("matroska", hevc)); len(ws) > 0 {
+		t.Errorf("expected no warnings, got %+v", ws)
+	}
+	if ws := HDRPassthroughWarnings(OutputOptions{
+		Encoding: &EncodingOptions{Codec: []StreamOption{
+			videoStreamOption(CodecLibx265),
+			audioStreamOption(CodecCopy),
+		}},
+		Format: "mp4",
+	}); len(ws) != 2 {
+		t.Errorf("expected 2 warnings, got %+v", ws)
+	}
+}
+
+func TestHDRRemuxOutputOptions(t *testing.T) {
+	for _, v := range []struct {
+		s   ProbeStream
+		tag string
+	}{
+		{s: ProbeStream{CodecName: CodecNameHEVC}, tag: "hvc1"},
+		{s: ProbeStream{CodecName: CodecNameHEVC, DolbyVisionProfile: astikit.IntPtr(8)}, tag: "dvh1"},
+		{s: ProbeStream{CodecName: CodecNameH264}},
+	} {
+		if tag := videoStreamOptionValue(HDRRemuxOutputOptions("mp4", v.s).Encoding.Tags); tag != v.tag {
+			t.Errorf("expected %q, got %q", v.tag, tag)
+		}
+	}
+}
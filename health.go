@@ -0,0 +1,130 @@
+package astiffmpeg
+
+import (
+	"bytes"
+	"strconv"
+	"time"
+)
+
+// Health event names
+const (
+	// Encoding is slower than realtime or frames are being dropped or duplicated
+	HealthEventNameDegraded = "degraded"
+	// Progress has stopped, which usually means the input is not sending data anymore
+	HealthEventNameInputLost = "input.lost"
+	// Job is healthy again
+	HealthEventNameRecovered = "recovered"
+	// Audio is silent, see NewSilenceMonitor
+	HealthEventNameSilence = "silence"
+)
+
+// HealthEvent represents a change in the health of a live job
+type HealthEvent struct {
+	Name    string
+	Reason  string
+	Results DefaultStdErrResults
+	Time    time.Time
+}
+
+// HealthMonitorOptions represents health monitor options
+type HealthMonitorOptions struct {
+	// Duration without progress after which the input is considered lost. Defaults to 10s.
+	InputTimeout time.Duration
+	// Speed under which the job is considered degraded. Defaults to 1.
+	MinSpeed float64
+	// Defaults to 1s
+	Period time.Duration
+}
+
+// NewHealthMonitor creates a stderr parser monitoring the health of long running live jobs, and executing the
+// callback whenever the health changes
+func NewHealthMonitor(o HealthMonitorOptions, fn func(e HealthEvent)) StdErrParser {
+	// Default options
+	if o.InputTimeout <= 0 {
+		o.InputTimeout = 10 * time.Second
+	}
+	if o.MinSpeed <= 0 {
+		o.MinSpeed = 1
+	}
+	if o.Period <= 0 {
+		o.Period = time.Second
+	}
+	return &healthMonitor{
+		fn:    fn,
+		o:     o,
+		state: HealthEventNameRecovered,
+	}
+}
+
+type healthMonitor struct {
+	fn             func(e HealthEvent)
+	lastDrop       int
+	lastDup        int
+	lastProgressAt time.Time
+	lastTime       time.Duration
+	o              HealthMonitorOptions
+	state          string
+}
+
+func (m *healthMonitor) Period() time.Duration {
+	return m.o.Period
+}
+
+func (m *healthMonitor) Process(t time.Time, b *bytes.Buffer) {
+	// Parse results
+	var r DefaultStdErrResults
+	if l, ok := lastProgressLine(b.Bytes()); ok {
+		r = defaultStdErrParser{}.parseResults(l)
+	}
+	m.process(t, r)
+}
+
+func (m *healthMonitor) process(t time.Time, r DefaultStdErrResults) {
+	// Update progress
+	if m.lastProgressAt.IsZero() || (r.Time != nil && *r.Time != m.lastTime) {
+		m.lastProgressAt = t
+		if r.Time != nil {
+			m.lastTime = *r.Time
+		}
+	}
+
+	// Get state
+	var name, reason string
+	if d := t.Sub(m.lastProgressAt); d >= m.o.InputTimeout {
+		name = HealthEventNameInputLost
+		reason = "no progress for " + d.String()
+	} else if r.Speed != nil && *r.Speed < m.o.MinSpeed {
+		name = HealthEventNameDegraded
+		reason = "speed is " + strconv.FormatFloat(*r.Speed, 'f', -1, 64) + "x"
+	} else if r.Drop != nil && *r.Drop > m.lastDrop {
+		name = HealthEventNameDegraded
+		reason = strconv.Itoa(*r.Drop-m.lastDrop) + " frames dropped"
+	} else if r.Dup != nil && *r.Dup > m.lastDup {
+		name = HealthEventNameDegraded
+		reason = strconv.Itoa(*r.Dup-m.lastDup) + " frames duplicated"
+	} else {
+		name = HealthEventNameRecovered
+	}
+
+	// Update counts
+	if r.Drop != nil {
+		m.lastDrop = *r.Drop
+	}
+	if r.Dup != nil {
+		m.lastDup = *r.Dup
+	}
+
+	// State has not changed
+	if name == m.state {
+		return
+	}
+	m.state = name
+
+	// Execute callback
+	m.fn(HealthEvent{
+		Name:    name,
+		Reason:  reason,
+		Results: r,
+		Time:    t,
+	})
+}
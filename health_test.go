@@ -0,0 +1,42 @@
+package astiffmpeg
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/asticode/go-astikit"
+)
+
+func TestHealthMonitor(t *testing.T) {
+	var es []string
+	m := NewHealthMonitor(HealthMonitorOptions{InputTimeout: 5 * time.Second}, func(e HealthEvent) { es = append(es, e.Name+":"+e.Reason) }).(*healthMonitor)
+	n := time.Unix(0, 0)
+	for idx, r := range []DefaultStdErrResults{
+		{Speed: astikit.Float64Ptr(1), Time: astikit.DurationPtr(time.Second)},
+		{Drop: astikit.IntPtr(3), Speed: astikit.Float64Ptr(1), Time: astikit.DurationPtr(2 * time.Second)},
+		{Drop: astikit.IntPtr(3), Speed: astikit.Float64Ptr(1), Time: astikit.DurationPtr(3 * time.Second)},
+		{Drop: astikit.IntPtr(3), Speed: astikit.Float64Ptr(0.8), Time: astikit.DurationPtr(4 * time.Second)},
+		{Drop: astikit.IntPtr(3), Speed: astikit.Float64Ptr(1), Time: astikit.DurationPtr(4 * time.Second)},
+		{Drop: astikit.IntPtr(3), Speed: astikit.Float64Ptr(1), Time: astikit.DurationPtr(4 * time.Second)},
+		{Drop: astikit.IntPtr(3), Speed: astikit.Float64Ptr(1), Time: astikit.DurationPtr(5 * time.Second)},
+	} {
+		step := time.Second
+		if idx == 5 {
+			step = 5 * time.Second
+		}
+		n = n.Add(step)
+		m.process(n, r)
+	}
+	e := []string{
+		"degraded:3 frames dropped",
+		"recovered:",
+		"degraded:speed is 0.8x",
+		"recovered:",
+		"input.lost:no progress for 6s",
+		"recovered:",
+	}
+	if !reflect.DeepEqual(e, es) {
+		t.Errorf("expected %+v, got %+v", e, es)
+	}
+}
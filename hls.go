@@ -0,0 +1,134 @@
+package astiffmpeg
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/asticode/go-astikit"
+)
+
+// HLS flags
+const (
+	HLSFlagDeleteSegments      = "delete_segments"
+	HLSFlagIndependentSegments = "independent_segments"
+	HLSFlagOmitEndlist         = "omit_endlist"
+	HLSFlagProgramDateTime     = "program_date_time"
+	HLSFlagTempFile            = "temp_file"
+)
+
+// HLS playlist types
+const (
+	HLSPlaylistTypeEvent = "event"
+	HLSPlaylistTypeVOD   = "vod"
+)
+
+// HLS segment types
+const (
+	HLSSegmentTypeFMP4   = "fmp4"
+	HLSSegmentTypeMPEGTS = "mpegts"
+)
+
+// HLSOptions represents options of the hls muxer
+type HLSOptions struct {
+	// Name of the fmp4 init segment
+	FMP4InitFilename string
+	// See HLSFlag constants
+	Flags []string
+	// Target duration of the segments of the initial playlist, which allows starting playback sooner
+	InitTime *time.Duration
+	// File describing how segments are encrypted using AES-128, see WriteHLSKeyInfoFile
+	KeyInfoFile string
+	// Maximum number of segments in the playlist, 0 means all segments
+	ListSize *int
+	// See HLSPlaylistType constants
+	PlaylistType string
+	// Pattern of the segments name (e.g. "segment-%05d.ts")
+	SegmentFilename string
+	// See HLSSegmentType constants
+	SegmentType string
+	// Target segment duration. Segments are only cut on keyframes.
+	Time *time.Duration
+}
+
+func (o HLSOptions) adaptCmd(cmd *exec.Cmd) {
+	if len(o.FMP4InitFilename) > 0 {
+		cmd.Args = append(cmd.Args, "-hls_fmp4_init_filename", o.FMP4InitFilename)
+	}
+	if len(o.Flags) > 0 {
+		cmd.Args = append(cmd.Args, "-hls_flags", "+"+strings.Join(o.Flags, "+"))
+	}
+	if o.InitTime != nil {
+		cmd.Args = append(cmd.Args, "-hls_init_time", strconv.FormatFloat(o.InitTime.Seconds(), 'f', 3, 64))
+	}
+	if len(o.KeyInfoFile) > 0 {
+		cmd.Args = append(cmd.Args, "-hls_key_info_file", o.KeyInfoFile)
+	}
+	if o.ListSize != nil {
+		cmd.Args = append(cmd.Args, "-hls_list_size", strconv.Itoa(*o.ListSize))
+	}
+	if len(o.PlaylistType) > 0 {
+		cmd.Args = append(cmd.Args, "-hls_playlist_type", o.PlaylistType)
+	}
+	if len(o.SegmentFilename) > 0 {
+		cmd.Args = append(cmd.Args, "-hls_segment_filename", o.SegmentFilename)
+	}
+	if len(o.SegmentType) > 0 {
+		cmd.Args = append(cmd.Args, "-hls_segment_type", o.SegmentType)
+	}
+	if o.Time != nil {
+		cmd.Args = append(cmd.Args, "-hls_time", strconv.FormatFloat(o.Time.Seconds(), 'f', 3, 64))
+	}
+}
+
+// LowLatencyHLSOutputOptions creates output options producing low latency HLS (and DASH) with fMP4 segments of the
+// specified duration, split into fragments of the specified part duration that are flushed as soon as they're
+// encoded
+// The hls muxer doesn't support partial segments, which is why the dash muxer is used with its LHLS mode. Video is
+// encoded with H.264 and a GOP aligned on segments.
+func LowLatencyHLSOutputOptions(segmentDuration, partDuration time.Duration, framerate float64) (o OutputOptions, err error) {
+	// Check input
+	if partDuration <= 0 || partDuration > segmentDuration {
+		err = errors.New("astiffmpeg: part duration should be > 0 and <= segment duration")
+		return
+	}
+
+	// Create encoding options
+	e := &EncodingOptions{
+		Codec: []StreamOption{
+			videoStreamOption(CodecLibx264),
+			audioStreamOption(CodecAAC),
+		},
+		PixelFormat: PixelFormatYUV420P,
+		Tune:        TuneZerolatency,
+	}
+	if err = e.StreamingGOP(segmentDuration, framerate); err != nil {
+		err = fmt.Errorf("astiffmpeg: setting streaming gop failed: %w", err)
+		return
+	}
+
+	// Create options
+	o = OutputOptions{
+		Encoding: e,
+		Format:   "dash",
+		// Adaptation sets are not set so that the dash muxer creates one per mapped stream, which works for video only
+		// or audio only inputs as well
+		Muxing: &MuxingOptions{DASH: &DASHOptions{
+			FragDuration:  astikit.DurationPtr(partDuration),
+			FragType:      DASHFragTypeDuration,
+			HLSPlaylist:   true,
+			LDash:         true,
+			LHLS:          true,
+			SegDuration:   astikit.DurationPtr(segmentDuration),
+			Streaming:     true,
+			TargetLatency: astikit.DurationPtr(3 * partDuration),
+			UseTemplate:   astikit.BoolPtr(true),
+			UseTimeline:   astikit.BoolPtr(false),
+			WindowSize:    astikit.IntPtr(5),
+		}},
+	}
+	return
+}
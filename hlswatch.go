@@ -0,0 +1,212 @@
+package astiffmpeg
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HLS event names
+const (
+	HLSEventNamePlaylistUpdated  = "playlist.updated"
+	HLSEventNameSegmentFinalized = "segment.finalized"
+)
+
+// HLSEvent represents an event of an HLS output being written
+type HLSEvent struct {
+	// See HLSEventName constants
+	Name string
+	// Path of the playlist or of the segment
+	Path string
+	// Only set for segment events
+	Segment *HLSSegment
+}
+
+// HLSSegment represents a segment listed in an HLS playlist
+type HLSSegment struct {
+	// 0 for the init segment
+	Duration time.Duration
+	// As listed in the playlist, relative to the playlist directory unless it's an absolute URL
+	URI string
+}
+
+// HLSWatchOptions represents HLS watch options
+type HLSWatchOptions struct {
+	OnEvent func(e HLSEvent)
+	// Defaults to 1s
+	PollPeriod time.Duration
+}
+
+// WatchHLS polls the playlist of an HLS output while ffmpeg is writing it, and emits an event when a segment is
+// finalized, which allows pushing segments to a CDN in near real time, followed by an event when the playlist is
+// updated. It returns once the context is done, after a last check so that cancelling the context once the job has
+// exited doesn't lose the last segments.
+// ffmpeg only lists segments in the playlist once they're complete. HLSFlagTempFile should be set so that neither
+// segments nor the playlist are read while being written.
+func WatchHLS(ctx context.Context, playlistPath string, o HLSWatchOptions) (err error) {
+	// Default options
+	if o.PollPeriod <= 0 {
+		o.PollPeriod = time.Second
+	}
+
+	// Loop
+	t := newHLSPlaylistTracker(playlistPath)
+	tk := time.NewTicker(o.PollPeriod)
+	defer tk.Stop()
+	for {
+		// Check
+		if err = t.emit(o.OnEvent); err != nil {
+			err = fmt.Errorf("astiffmpeg: checking playlist failed: %w", err)
+			return
+		}
+
+		// Wait
+		select {
+		case <-ctx.Done():
+			if err = t.emit(o.OnEvent); err != nil {
+				err = fmt.Errorf("astiffmpeg: checking playlist failed: %w", err)
+			}
+			return
+		case <-tk.C:
+		}
+	}
+}
+
+type hlsPlaylistTracker struct {
+	path     string
+	playlist []byte // Last playlist that has been handled
+	seen     map[string]bool
+}
+
+func newHLSPlaylistTracker(path string) *hlsPlaylistTracker {
+	return &hlsPlaylistTracker{
+		path: path,
+		seen: make(map[string]bool),
+	}
+}
+
+// check returns the segments listed in the playlist that have not been handled yet, and the playlist content if it
+// has changed since it was last handled
+// Segments and playlists are only considered handled once done and playlistDone have been called so that they're
+// returned again by the next check otherwise.
+func (t *hlsPlaylistTracker) check() (ss []HLSSegment, b []byte, err error) {
+	// Read playlist
+	if b, err = os.ReadFile(t.path); err != nil {
+		b = nil
+		if os.IsNotExist(err) {
+			err = nil
+			return
+		}
+		err = fmt.Errorf("astiffmpeg: reading %s failed: %w", t.path, err)
+		return
+	}
+
+	// Playlist has not changed
+	if bytes.Equal(b, t.playlist) {
+		b = nil
+		return
+	}
+
+	// Loop through segments
+	m := make(map[string]bool)
+	for _, s := range playlistSegments(b) {
+		m[s.URI] = true
+		if !t.seen[s.URI] {
+			ss = append(ss, s)
+		}
+	}
+
+	// Forget segments that have dropped out of the playlist so that live playlists don't grow memory
+	for u := range t.seen {
+		if !m[u] {
+			delete(t.seen, u)
+		}
+	}
+	return
+}
+
+func (t *hlsPlaylistTracker) done(s HLSSegment) {
+	t.seen[s.URI] = true
+}
+
+func (t *hlsPlaylistTracker) playlistDone(b []byte) {
+	t.playlist = b
+}
+
+func (t *hlsPlaylistTracker) emit(fn func(e HLSEvent)) (err error) {
+	// Check
+	var ss []HLSSegment
+	var b []byte
+	if ss, b, err = t.check(); err != nil || b == nil || fn == nil {
+		return
+	}
+
+	// Emit
+	for idx := range ss {
+		fn(HLSEvent{
+			Name:    HLSEventNameSegmentFinalized,
+			Path:    t.segmentPath(ss[idx]),
+			Segment: &ss[idx],
+		})
+		t.done(ss[idx])
+	}
+	fn(HLSEvent{
+		Name: HLSEventNamePlaylistUpdated,
+		Path: t.path,
+	})
+	t.playlistDone(b)
+	return
+}
+
+func (t *hlsPlaylistTracker) segmentPath(s HLSSegment) string {
+	if strings.Contains(s.URI, "://") {
+		return s.URI
+	}
+	return filepath.Join(filepath.Dir(t.path), filepath.FromSlash(s.URI))
+}
+
+// playlistSegments returns the segments listed in the playlist, including the init segment
+func playlistSegments(b []byte) (ss []HLSSegment) {
+	// The last line may not have been completely written
+	if i := bytes.LastIndexByte(b, '\n'); i >= 0 {
+		b = b[:i]
+	} else {
+		return
+	}
+
+	// Loop through lines
+	var d time.Duration
+	sc := bufio.NewScanner(bytes.NewReader(b))
+	for sc.Scan() {
+		l := strings.TrimSpace(sc.Text())
+		switch {
+		case strings.HasPrefix(l, "#EXT-X-MAP:"):
+			if i := strings.Index(l, `URI="`); i >= 0 {
+				if j := strings.Index(l[i+5:], `"`); j >= 0 {
+					ss = append(ss, HLSSegment{URI: l[i+5 : i+5+j]})
+				}
+			}
+		case strings.HasPrefix(l, "#EXTINF:"):
+			v := strings.TrimPrefix(l, "#EXTINF:")
+			if i := strings.Index(v, ","); i >= 0 {
+				v = v[:i]
+			}
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				d = time.Duration(f * float64(time.Second))
+			}
+		case len(l) > 0 && !strings.HasPrefix(l, "#"):
+			ss = append(ss, HLSSegment{
+				Duration: d,
+				URI:      l,
+			})
+			d = 0
+		}
+	}
+	return
+}
@@ -0,0 +1,90 @@
+package astiffmpeg
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPlaylistSegments(t *testing.T) {
+	ss := playlistSegments([]byte("#EXTM3U\n#EXT-X-MAP:URI=\"init.mp4\"\n#EXTINF:4.000,\nsegment-0.m4s\n#EXTINF:3.5,\nsegment-1.m4s\n#EXTINF:4.000,\nsegm"))
+	e := []HLSSegment{
+		{URI: "init.mp4"},
+		{Duration: 4 * time.Second, URI: "segment-0.m4s"},
+		{Duration: 3500 * time.Millisecond, URI: "segment-1.m4s"},
+	}
+	if !reflect.DeepEqual(e, ss) {
+		t.Errorf("expected %+v, got %+v", e, ss)
+	}
+}
+
+func TestWatchHLS(t *testing.T) {
+	// Write playlist
+	p := filepath.Join(t.TempDir(), "index.m3u8")
+	if err := os.WriteFile(p, []byte("#EXTM3U\n#EXTINF:4.000,\nsegment-0.ts\n"), 0644); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+
+	// Watch
+	var es []HLSEvent
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := WatchHLS(ctx, p, HLSWatchOptions{OnEvent: func(e HLSEvent) { es = append(es, e) }}); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	e := []HLSEvent{
+		{
+			Name:    HLSEventNameSegmentFinalized,
+			Path:    filepath.Join(filepath.Dir(p), "segment-0.ts"),
+			Segment: &HLSSegment{Duration: 4 * time.Second, URI: "segment-0.ts"},
+		},
+		{
+			Name: HLSEventNamePlaylistUpdated,
+			Path: p,
+		},
+	}
+	if !reflect.DeepEqual(e, es) {
+		t.Errorf("expected %+v, got %+v", e, es)
+	}
+}
+
+func TestHLSPlaylistTracker(t *testing.T) {
+	// Write playlist
+	p := filepath.Join(t.TempDir(), "index.m3u8")
+	if err := os.WriteFile(p, []byte("#EXTM3U\n#EXTINF:4.000,\nsegment-0.ts\n#EXTINF:4.000,\nsegment-1.ts\n"), 0644); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+
+	// Only handled segments are not returned again
+	tr := newHLSPlaylistTracker(p)
+	ss, b, err := tr.check()
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	if len(ss) != 2 || b == nil {
+		t.Fatalf("expected 2 segments and a playlist, got %+v and %s", ss, b)
+	}
+	tr.done(ss[0])
+	if ss, b, _ = tr.check(); len(ss) != 1 || ss[0].URI != "segment-1.ts" || b == nil {
+		t.Errorf("expected segment-1.ts and a playlist, got %+v and %s", ss, b)
+	}
+	tr.done(ss[0])
+	tr.playlistDone(b)
+	if ss, b, _ = tr.check(); len(ss) > 0 || b != nil {
+		t.Errorf("expected nothing, got %+v and %s", ss, b)
+	}
+
+	// Segments that have dropped out of the playlist are forgotten
+	if err = os.WriteFile(p, []byte("#EXTM3U\n#EXTINF:4.000,\nsegment-1.ts\n"), 0644); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	if ss, _, _ = tr.check(); len(ss) > 0 {
+		t.Errorf("expected no segments, got %+v", ss)
+	}
+	if e := map[string]bool{"segment-1.ts": true}; !reflect.DeepEqual(e, tr.seen) {
+		t.Errorf("expected %+v, got %+v", e, tr.seen)
+	}
+}
@@ -0,0 +1,40 @@
+package astiffmpeg
+
+import "github.com/asticode/go-astikit"
+
+// AnimatedWebPOutputOptions creates output options producing an infinitely looping animated WebP
+// Quality ranges from 0 to 100 and is ignored when lossless is true
+func AnimatedWebPOutputOptions(quality float64, lossless bool) OutputOptions {
+	return OutputOptions{
+		Encoding: &EncodingOptions{
+			Codec:        []StreamOption{videoStreamOption(CodecLibwebpAnim)},
+			ImageQuality: astikit.Float64Ptr(quality),
+			Lossless:     astikit.BoolPtr(lossless),
+		},
+		Format:  "webp",
+		Muxing:  &MuxingOptions{Loop: astikit.IntPtr(0)},
+		NoAudio: true,
+	}
+}
+
+// AVIFOutputOptions creates output options producing an AVIF image, or an infinitely looping AVIF animation
+// when animated is true
+// CRF ranges from 0 to 63, lower is better
+func AVIFOutputOptions(crf int, animated bool) OutputOptions {
+	o := OutputOptions{
+		Encoding: &EncodingOptions{
+			Codec:       []StreamOption{videoStreamOption(CodecLibaomAV1)},
+			CRF:         astikit.IntPtr(crf),
+			PixelFormat: PixelFormatYUV420P,
+		},
+		Format:  "avif",
+		NoAudio: true,
+	}
+	if animated {
+		o.Muxing = &MuxingOptions{Loop: astikit.IntPtr(0)}
+	} else {
+		o.Encoding.Frames = []StreamOption{videoStreamOption(1)}
+		o.Encoding.StillPicture = astikit.BoolPtr(true)
+	}
+	return o
+}
@@ -0,0 +1,178 @@
+package astiffmpeg
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNotSupported is returned when an operation is not supported on the current platform
+var ErrNotSupported = errors.New("astiffmpeg: operation not supported on this platform")
+
+// Job represents a running ffmpeg process
+type Job struct {
+	bufErr      *syncBuffer
+	cmd         *exec.Cmd
+	done        chan struct{}
+	endedAt     time.Time
+	err         error
+	exited      chan struct{}
+	m           *sync.Mutex // Locks waited
+	onExit      func(err error) error
+	outputPaths []string
+	parsed      chan struct{}
+	startedAt   time.Time
+	stdin       io.Writer
+	waited      bool
+}
+
+func newJob(ctx context.Context, cmd *exec.Cmd, bufErr *syncBuffer, outputPaths []string, onExit func(err error) error, p StdErrParser) (j *Job) {
+	j = &Job{
+		bufErr:      bufErr,
+		cmd:         cmd,
+		done:        make(chan struct{}),
+		exited:      make(chan struct{}),
+		m:           &sync.Mutex{},
+		onExit:      onExit,
+		outputPaths: outputPaths,
+		parsed:      make(chan struct{}),
+		startedAt:   time.Now(),
+	}
+	if p != nil {
+		go j.parseStdErr(ctx, p)
+	} else {
+		close(j.parsed)
+	}
+	go j.wait()
+	return
+}
+
+func (j *Job) wait() {
+	defer close(j.done)
+
+	// The process group must not be killed once the process has been reaped since its id may have been reused. When
+	// the process can't be waited on without being reaped, the process group is never killed.
+	waitExited(j.cmd)
+	j.m.Lock()
+	j.waited = true
+	j.m.Unlock()
+
+	// Reap
+	err := j.cmd.Wait()
+	close(j.exited)
+	if err != nil {
+		j.err = fmt.Errorf("astiffmpeg: running %s failed with stderr %s: %w", strings.Join(j.cmd.Args, " "), j.bufErr.Bytes(), err)
+	}
+
+	// Make sure the parser is not called once Wait has returned
+	<-j.parsed
+	if j.onExit != nil {
+		if err := j.onExit(j.err); err != nil && j.err == nil {
+			j.err = err
+		}
+	}
+	j.endedAt = time.Now()
+}
+
+// parseStdErr processes stderr periodically until either the process exits, in which case the parser is flushed so
+// that the final stats are not lost, or the context is cancelled
+func (j *Job) parseStdErr(ctx context.Context, p StdErrParser) {
+	defer close(j.parsed)
+	t := time.NewTicker(p.Period())
+	defer t.Stop()
+	for {
+		select {
+		case n := <-t.C:
+			// Parsers are provided with a snapshot so that they can't race with the cmd writing to stderr
+			p.Process(n, bytes.NewBuffer(j.bufErr.Bytes()))
+		case <-j.exited:
+			flushStdErrParser(p, time.Now(), bytes.NewBuffer(j.bufErr.Bytes()))
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Wait waits for the job to exit and returns its error, if any
+// It can be called several times
+func (j *Job) Wait() error {
+	<-j.done
+	return j.err
+}
+
+// InputInfos returns information about inputs parsed from the stderr banner
+// It blocks until the job has exited
+func (j *Job) InputInfos() []InputInfo {
+	<-j.done
+	return ParseInputInfos(j.bufErr.Bytes())
+}
+
+// Pause suspends the ffmpeg process without killing it so that encode progress is not lost
+// On Windows it returns ErrNotSupported
+func (j *Job) Pause() error {
+	if err := j.pause(); err != nil {
+		return fmt.Errorf("astiffmpeg: pausing failed: %w", err)
+	}
+	return nil
+}
+
+// Interrupt asks ffmpeg to stop gracefully, which lets it finalize the output (e.g. write the mp4 index), like
+// pressing ctrl+c would. Wait should then be called.
+func (j *Job) Interrupt() error {
+	if err := j.interrupt(); err != nil {
+		return fmt.Errorf("astiffmpeg: interrupting failed: %w", err)
+	}
+	return nil
+}
+
+// Stop asks ffmpeg to stop gracefully by writing "q" to its stdin, which lets the muxer finalize the output (e.g.
+// write the mp4 index), and kills it if it hasn't exited once the grace period is over. Stdin is only available
+// when the job has been started with ExecOptions.GracefulStop and is not used by an input, ffmpeg is interrupted
+// instead otherwise. It returns once the job has exited, with its error if any.
+func (j *Job) Stop(gracePeriod time.Duration) error {
+	// Ask ffmpeg to quit
+	// Errors are ignored since ffmpeg may have exited in the meantime, and it's killed once the grace period is
+	// over otherwise
+	if j.stdin != nil {
+		j.stdin.Write([]byte("q"))
+	} else {
+		j.interrupt()
+	}
+
+	// Wait
+	t := time.NewTimer(gracePeriod)
+	defer t.Stop()
+	select {
+	case <-j.exited:
+	case <-t.C:
+		j.killProcessGroup()
+		j.cmd.Process.Kill()
+	}
+	return j.Wait()
+}
+
+// killProcessGroup kills the process group unless the process is being reaped, in which case its id may have been
+// reused
+func (j *Job) killProcessGroup() {
+	j.m.Lock()
+	defer j.m.Unlock()
+	if !j.waited {
+		killProcessGroup(j.cmd)
+	}
+}
+
+// Resume resumes a paused ffmpeg process
+// On Windows it returns ErrNotSupported
+func (j *Job) Resume() error {
+	if err := j.resume(); err != nil {
+		return fmt.Errorf("astiffmpeg: resuming failed: %w", err)
+	}
+	return nil
+}
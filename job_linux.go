@@ -0,0 +1,24 @@
+//go:build linux
+// +build linux
+
+package astiffmpeg
+
+import (
+	"os/exec"
+	"syscall"
+	"unsafe"
+)
+
+const pPID = 1
+
+// waitExited blocks until the process has exited without reaping it, which makes sure its id is not reused until
+// cmd.Wait is called
+func waitExited(cmd *exec.Cmd) {
+	var siginfo [128]byte
+	for {
+		_, _, e := syscall.Syscall6(syscall.SYS_WAITID, pPID, uintptr(cmd.Process.Pid), uintptr(unsafe.Pointer(&siginfo[0])), syscall.WEXITED|syscall.WNOWAIT, 0, 0)
+		if e != syscall.EINTR {
+			return
+		}
+	}
+}
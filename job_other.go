@@ -0,0 +1,9 @@
+//go:build !linux
+// +build !linux
+
+package astiffmpeg
+
+import "os/exec"
+
+// The process can't be waited on without being reaped
+func waitExited(cmd *exec.Cmd) {}
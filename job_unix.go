@@ -0,0 +1,49 @@
+//go:build !windows
+// +build !windows
+
+package astiffmpeg
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+const extraFilesSupported = true
+
+// A single arg can't exceed MAX_ARG_STRLEN (128KiB on Linux), and all args and the environment share ARG_MAX
+// which can be as low as 256KiB on macOS
+const (
+	maxArgLength  = 128*1024 - 1
+	maxArgsLength = 128 * 1024
+)
+
+// Signals are sent to the whole process group so that helper processes spawned by ffmpeg are signaled as well
+func (j *Job) pause() error {
+	return syscall.Kill(-j.cmd.Process.Pid, syscall.SIGSTOP)
+}
+
+func (j *Job) resume() error {
+	return syscall.Kill(-j.cmd.Process.Pid, syscall.SIGCONT)
+}
+
+func (j *Job) interrupt() error {
+	return j.cmd.Process.Signal(syscall.SIGINT)
+}
+
+// ffmpeg runs in its own process group so that the whole group can be killed on cancellation. As a consequence,
+// it doesn't receive signals sent to the parent's process group, such as ctrl+c in a terminal.
+func prepareCmd(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+func killProcessGroup(cmd *exec.Cmd) {
+	syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
+
+func afterStart(cmd *exec.Cmd) error {
+	return nil
+}
+
+func platformPath(p string) string {
+	return p
+}
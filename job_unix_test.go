@@ -0,0 +1,264 @@
+//go:build !windows
+// +build !windows
+
+package astiffmpeg
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+)
+
+type bufferStdErrParser struct {
+	bs []string
+}
+
+func (p *bufferStdErrParser) Period() time.Duration { return time.Hour }
+func (p *bufferStdErrParser) Process(t time.Time, b *bytes.Buffer) {
+	p.bs = append(p.bs, b.String())
+}
+
+func TestJobParseStdErr(t *testing.T) {
+	cmd := exec.Command("sh", "-c", "printf test >&2")
+	b := &syncBuffer{}
+	cmd.Stderr = b
+	if err := cmd.Start(); err != nil {
+		t.Skipf("starting cmd failed: %s", err)
+	}
+	p := &bufferStdErrParser{}
+	j := newJob(context.Background(), cmd, b, nil, nil, p)
+	if err := j.Wait(); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	if len(p.bs) != 1 || p.bs[0] != "test" {
+		t.Errorf("expected one final parse of test, got %+v", p.bs)
+	}
+}
+
+func TestProgressHandler(t *testing.T) {
+	// Create fake binary writing progress to the path following -progress
+	p := filepath.Join(t.TempDir(), "ffmpeg")
+	if err := os.WriteFile(p, []byte("#!/bin/sh\nprintf 'frame=10\\nprogress=continue\\nframe=20\\nprogress=end\\n' >&3\n"), 0755); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+
+	// Exec
+	var ps []Progress
+	var args []string
+	if err := New(Configuration{BinaryPath: p}).ExecWithOptions(context.Background(), GlobalOptions{}, nil, Output{Path: "-"}, ExecOptions{
+		BeforeStart:     func(cmd *exec.Cmd) { args = cmd.Args },
+		ProgressHandler: func(p Progress) { ps = append(ps, p) },
+	}); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	if e := []string{p, "-hide_banner", "-progress", "pipe:3", "-"}; !reflect.DeepEqual(e, args) {
+		t.Errorf("expected %+v, got %+v", e, args)
+	}
+	if len(ps) != 2 || *ps[0].Frame != 10 || !ps[1].Ended {
+		t.Errorf("unexpected progress %+v", ps)
+	}
+}
+
+func TestInputReader(t *testing.T) {
+	// Create fake binary copying stdin to stdout
+	p := filepath.Join(t.TempDir(), "ffmpeg")
+	if err := os.WriteFile(p, []byte("#!/bin/sh\ncat\n"), 0755); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+
+	// Exec
+	f := New(Configuration{BinaryPath: p})
+	var args []string
+	stdout := &bytes.Buffer{}
+	in := Input{Options: &InputOptions{Format: "mpegts"}, Reader: bytes.NewBufferString("test")}
+	if err := f.ExecWithOptions(context.Background(), GlobalOptions{}, []Input{in}, Output{Path: "-"}, ExecOptions{
+		BeforeStart: func(cmd *exec.Cmd) { args = cmd.Args },
+		Stdout:      stdout,
+	}); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	if e := []string{p, "-hide_banner", "-f", "mpegts", "-i", "pipe:0", "-"}; !reflect.DeepEqual(e, args) {
+		t.Errorf("expected %+v, got %+v", e, args)
+	}
+	if e, g := "test", stdout.String(); e != g {
+		t.Errorf("expected %s, got %s", e, g)
+	}
+
+	// Stdin is already used
+	if err := f.ExecWithOptions(context.Background(), GlobalOptions{}, []Input{in}, Output{Path: "-"}, ExecOptions{Stdin: &bytes.Buffer{}}); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestOutputWriter(t *testing.T) {
+	// Create fake binary writing to stdout
+	p := filepath.Join(t.TempDir(), "ffmpeg")
+	if err := os.WriteFile(p, []byte("#!/bin/sh\nprintf test\n"), 0755); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+
+	// Exec
+	f := New(Configuration{BinaryPath: p})
+	var args []string
+	w := &bytes.Buffer{}
+	out := Output{Options: &OutputOptions{Format: "mpegts"}, Path: "out.ts", Writer: w}
+	if err := f.ExecWithOptions(context.Background(), GlobalOptions{}, []Input{{Path: "in.mp4"}}, out, ExecOptions{
+		BeforeStart: func(cmd *exec.Cmd) { args = cmd.Args },
+	}); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	if e := []string{p, "-hide_banner", "-i", "in.mp4", "-f", "mpegts", "pipe:1"}; !reflect.DeepEqual(e, args) {
+		t.Errorf("expected %+v, got %+v", e, args)
+	}
+	if e, g := "test", w.String(); e != g {
+		t.Errorf("expected %s, got %s", e, g)
+	}
+
+	// Stdout is already used
+	if err := f.ExecWithOptions(context.Background(), GlobalOptions{}, []Input{{Path: "in.mp4"}}, out, ExecOptions{Stdout: &bytes.Buffer{}}); err == nil {
+		t.Error("expected error")
+	}
+
+	// Format is mandatory
+	if err := f.ExecWithOptions(context.Background(), GlobalOptions{}, []Input{{Path: "in.mp4"}}, Output{Writer: w}, ExecOptions{}); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestOutputSink(t *testing.T) {
+	// Create fake binaries
+	dir := t.TempDir()
+	pp := filepath.Join(dir, "ffmpeg-pipe")
+	if err := os.WriteFile(pp, []byte("#!/bin/sh\nprintf test\n"), 0755); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	ph := filepath.Join(dir, "ffmpeg-hls")
+	if err := os.WriteFile(ph, []byte("#!/bin/sh\nfor a; do l=$a; done\nd=$(dirname \"$l\")\nprintf s0 > \"$d/segment-0.ts\"\nprintf '#EXTM3U\\n#EXTINF:4.000,\\nsegment-0.ts\\n' > \"$l\"\n"), 0755); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+
+	// Create sink
+	m := make(map[string]string)
+	s := OutputSinkFunc(func(ctx context.Context, name string, r io.Reader) error {
+		b, err := io.ReadAll(r)
+		m[name] = string(b)
+		return err
+	})
+
+	// Pipe
+	var args []string
+	if err := New(Configuration{BinaryPath: pp}).ExecWithOptions(context.Background(), GlobalOptions{}, []Input{{Path: "in.mp4"}}, Output{Path: "out/test.ts", Sink: s}, ExecOptions{
+		BeforeStart: func(cmd *exec.Cmd) { args = cmd.Args },
+	}); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	if e := []string{pp, "-hide_banner", "-i", "in.mp4", "-f", "mpegts", "pipe:1"}; !reflect.DeepEqual(e, args) {
+		t.Errorf("expected %+v, got %+v", e, args)
+	}
+	if e := map[string]string{"out/test.ts": "test"}; !reflect.DeepEqual(e, m) {
+		t.Errorf("expected %+v, got %+v", e, m)
+	}
+
+	// HLS
+	m = make(map[string]string)
+	if err := New(Configuration{BinaryPath: ph}).Exec(context.Background(), GlobalOptions{}, []Input{{Path: "in.mp4"}}, Output{Path: "out/index.m3u8", Sink: s}); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	if e := map[string]string{
+		"out/index.m3u8":   "#EXTM3U\n#EXTINF:4.000,\nsegment-0.ts\n",
+		"out/segment-0.ts": "s0",
+	}; !reflect.DeepEqual(e, m) {
+		t.Errorf("expected %+v, got %+v", e, m)
+	}
+}
+
+func TestJobStop(t *testing.T) {
+	// Create fake binaries
+	dir := t.TempDir()
+	pq := filepath.Join(dir, "ffmpeg-q")
+	if err := os.WriteFile(pq, []byte("#!/bin/sh\nhead -c 1\n"), 0755); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	ps := filepath.Join(dir, "ffmpeg-sleep")
+	if err := os.WriteFile(ps, []byte("#!/bin/sh\nexec sleep 10\n"), 0755); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+
+	// ffmpeg quits
+	w := &bytes.Buffer{}
+	var args []string
+	j, err := New(Configuration{BinaryPath: pq}).ExecAsyncWithOptions(context.Background(), GlobalOptions{}, nil, Output{Options: &OutputOptions{Format: "null"}, Writer: w}, ExecOptions{
+		BeforeStart:  func(cmd *exec.Cmd) { args = cmd.Args },
+		GracefulStop: true,
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	if err = j.Stop(5 * time.Second); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	if e, g := "q", w.String(); e != g {
+		t.Errorf("expected %s, got %s", e, g)
+	}
+	if e := []string{pq, "-hide_banner", "-n", "-f", "null", "pipe:1"}; !reflect.DeepEqual(e, args) {
+		t.Errorf("expected %+v, got %+v", e, args)
+	}
+
+	// Stdin is not connected to a pipe by default
+	w.Reset()
+	if err = New(Configuration{BinaryPath: pq}).Exec(context.Background(), GlobalOptions{}, nil, Output{Options: &OutputOptions{Format: "null"}, Writer: w}); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	if w.Len() > 0 {
+		t.Errorf("expected empty stdin, got %s", w.String())
+	}
+
+	// ffmpeg is killed once the grace period is over
+	if j, err = New(Configuration{BinaryPath: ps}).ExecAsync(context.Background(), GlobalOptions{}, nil, Output{Path: "-"}); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	n := time.Now()
+	if err = j.Stop(50 * time.Millisecond); err == nil {
+		t.Error("expected error")
+	}
+	if d := time.Since(n); d > 5*time.Second {
+		t.Errorf("expected job to be killed, took %s", d)
+	}
+}
+
+func TestJobKillProcessGroup(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("process group is only killed on linux")
+	}
+
+	// Create fake binary whose child keeps stderr open
+	p := filepath.Join(t.TempDir(), "ffmpeg")
+	if err := os.WriteFile(p, []byte("#!/bin/sh\nsleep 10 &\nexec sleep 10\n"), 0755); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+
+	// Child is killed on cancellation
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	n := time.Now()
+	j, err := New(Configuration{BinaryPath: p}).ExecAsync(ctx, GlobalOptions{}, nil, Output{Path: "-"})
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	if err = j.Wait(); err == nil {
+		t.Error("expected error")
+	}
+	if d := time.Since(n); d > 5*time.Second {
+		t.Errorf("expected process group to be killed, took %s", d)
+	}
+	if !j.waited {
+		t.Error("expected job to be waited")
+	}
+}
@@ -0,0 +1,162 @@
+//go:build windows
+// +build windows
+
+package astiffmpeg
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	kernel32                     = syscall.NewLazyDLL("kernel32.dll")
+	procAssignProcessToJobObject = kernel32.NewProc("AssignProcessToJobObject")
+	procCreateJobObjectW         = kernel32.NewProc("CreateJobObjectW")
+	procGenerateConsoleCtrlEvent = kernel32.NewProc("GenerateConsoleCtrlEvent")
+	procSetInformationJobObject  = kernel32.NewProc("SetInformationJobObject")
+)
+
+// os/exec doesn't support extra files on Windows
+const extraFilesSupported = false
+
+// The whole command line can't exceed 32767 characters
+const (
+	maxArgLength  = 32000
+	maxArgsLength = 32000
+)
+
+const (
+	createNewProcessGroup                  = 0x00000200
+	ctrlBreakEvent                         = 1
+	jobObjectExtendedLimitInformationClass = 9
+	jobObjectLimitKillOnJobClose           = 0x00002000
+	processSetQuota                        = 0x0100
+	processTerminate                       = 0x0001
+)
+
+type jobObjectBasicLimitInformation struct {
+	PerProcessUserTimeLimit int64
+	PerJobUserTimeLimit     int64
+	LimitFlags              uint32
+	MinimumWorkingSetSize   uintptr
+	MaximumWorkingSetSize   uintptr
+	ActiveProcessLimit      uint32
+	Affinity                uintptr
+	PriorityClass           uint32
+	SchedulingClass         uint32
+}
+
+type ioCounters struct {
+	ReadOperationCount  uint64
+	WriteOperationCount uint64
+	OtherOperationCount uint64
+	ReadTransferCount   uint64
+	WriteTransferCount  uint64
+	OtherTransferCount  uint64
+}
+
+type jobObjectExtendedLimitInformation struct {
+	BasicLimitInformation jobObjectBasicLimitInformation
+	IoInfo                ioCounters
+	ProcessMemoryLimit    uintptr
+	JobMemoryLimit        uintptr
+	PeakProcessMemoryUsed uintptr
+	PeakJobMemoryUsed     uintptr
+}
+
+// All processes are assigned to the same job object, which is closed by the OS when the parent process dies,
+// killing them
+var (
+	jobObject     syscall.Handle
+	jobObjectErr  error
+	jobObjectOnce sync.Once
+)
+
+func createJobObject() (h syscall.Handle, err error) {
+	// Create
+	r, _, e := procCreateJobObjectW.Call(0, 0)
+	if r == 0 {
+		err = fmt.Errorf("astiffmpeg: creating job object failed: %w", e)
+		return
+	}
+	h = syscall.Handle(r)
+
+	// Kill processes when the job object is closed
+	i := jobObjectExtendedLimitInformation{}
+	i.BasicLimitInformation.LimitFlags = jobObjectLimitKillOnJobClose
+	if r, _, e = procSetInformationJobObject.Call(uintptr(h), jobObjectExtendedLimitInformationClass, uintptr(unsafe.Pointer(&i)), unsafe.Sizeof(i)); r == 0 {
+		syscall.CloseHandle(h)
+		err = fmt.Errorf("astiffmpeg: setting job object information failed: %w", e)
+		return
+	}
+	return
+}
+
+func (j *Job) pause() error {
+	return ErrNotSupported
+}
+
+func (j *Job) resume() error {
+	return ErrNotSupported
+}
+
+// ffmpeg handles ctrl+break as ctrl+c, which requires the process to be in its own process group
+func (j *Job) interrupt() error {
+	if r, _, e := procGenerateConsoleCtrlEvent.Call(ctrlBreakEvent, uintptr(j.cmd.Process.Pid)); r == 0 {
+		return e
+	}
+	return nil
+}
+
+// Console window is hidden and the process is created in its own process group so that it can be interrupted
+// without interrupting the parent process
+func prepareCmd(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		CreationFlags: createNewProcessGroup,
+		HideWindow:    true,
+	}
+}
+
+// Child processes are handled by the job object
+func killProcessGroup(cmd *exec.Cmd) {}
+
+func afterStart(cmd *exec.Cmd) (err error) {
+	// Get job object
+	jobObjectOnce.Do(func() { jobObject, jobObjectErr = createJobObject() })
+	if jobObjectErr != nil {
+		err = jobObjectErr
+		return
+	}
+
+	// Open process
+	var h syscall.Handle
+	if h, err = syscall.OpenProcess(processSetQuota|processTerminate, false, uint32(cmd.Process.Pid)); err != nil {
+		err = fmt.Errorf("astiffmpeg: opening process failed: %w", err)
+		return
+	}
+	defer syscall.CloseHandle(h)
+
+	// Assign process to job object
+	if r, _, e := procAssignProcessToJobObject.Call(uintptr(jobObject), uintptr(h)); r == 0 {
+		err = fmt.Errorf("astiffmpeg: assigning process to job object failed: %w", e)
+		return
+	}
+	return
+}
+
+// Absolute paths longer than MAX_PATH are prefixed so that they're not truncated
+func platformPath(p string) string {
+	if len(p) < 260 || !filepath.IsAbs(p) || strings.HasPrefix(p, `\\?\`) {
+		return p
+	}
+	p = filepath.Clean(p)
+	if strings.HasPrefix(p, `\\`) {
+		return `\\?\UNC\` + p[2:]
+	}
+	return `\\?\` + p
+}
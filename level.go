@@ -0,0 +1,199 @@
+package astiffmpeg
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// LevelOptions represents the characteristics of a video stream its level is derived from
+type LevelOptions struct {
+	// Maximum bitrate of the stream. When not set, the bitrate is not taken into account.
+	Bitrate     Number
+	FPS         float64
+	Height      int
+	PixelFormat PixelFormat
+	Width       int
+}
+
+type level struct {
+	level float64
+	// Maximum bitrate in kbits/s, for baseline/main profiles (h264) or main tier (hevc)
+	maxBitrate float64
+	// Maximum frame size and maximum samples per second, in macroblocks (h264) or luma samples (hevc)
+	maxFrameSize  float64
+	maxSampleRate float64
+}
+
+// See table A-1 of ITU-T H.264
+var h264Levels = []level{
+	{1, 64, 99, 1485},
+	{1.1, 192, 396, 3000},
+	{1.2, 384, 396, 6000},
+	{1.3, 768, 396, 11880},
+	{2, 2000, 396, 11880},
+	{2.1, 4000, 792, 19800},
+	{2.2, 4000, 1620, 20250},
+	{3, 10000, 1620, 40500},
+	{3.1, 14000, 3600, 108000},
+	{3.2, 20000, 5120, 216000},
+	{4, 20000, 8192, 245760},
+	{4.1, 50000, 8192, 245760},
+	{4.2, 50000, 8704, 522240},
+	{5, 135000, 22080, 589824},
+	{5.1, 240000, 36864, 983040},
+	{5.2, 240000, 36864, 2073600},
+	{6, 240000, 139264, 4177920},
+	{6.1, 480000, 139264, 8355840},
+	{6.2, 800000, 139264, 16711680},
+}
+
+// See table A.8 of ITU-T H.265
+var hevcLevels = []level{
+	{1, 128, 36864, 552960},
+	{2, 1500, 122880, 3686400},
+	{2.1, 3000, 245760, 7372800},
+	{3, 6000, 552960, 16588800},
+	{3.1, 10000, 983040, 33177600},
+	{4, 12000, 2228224, 66846720},
+	{4.1, 20000, 2228224, 133693440},
+	{5, 25000, 8912896, 267386880},
+	{5.1, 40000, 8912896, 534773760},
+	{5.2, 60000, 8912896, 1069547520},
+	{6, 60000, 35651584, 1069547520},
+	{6.1, 120000, 35651584, 2139095040},
+	{6.2, 240000, 35651584, 4278190080},
+}
+
+// MinimalLevel returns the lowest level of the codec (CodecNameH264 or CodecNameHEVC) supporting the resolution,
+// frame rate and bitrate of the stream
+func MinimalLevel(codec string, o LevelOptions) (l float64, err error) {
+	// Check input
+	if o.FPS <= 0 || o.Height <= 0 || o.Width <= 0 {
+		err = errors.New("astiffmpeg: fps, height and width should be > 0")
+		return
+	}
+
+	// Get codec specific values
+	var ls []level
+	var frameSize, bitrateFactor float64
+	var maxDimension func(maxFrameSize float64) float64
+	switch codec {
+	case CodecNameH264:
+		// Sizes are expressed in 16x16 macroblocks
+		ls = h264Levels
+		mbWidth, mbHeight := math.Ceil(float64(o.Width)/16), math.Ceil(float64(o.Height)/16)
+		frameSize = mbWidth * mbHeight
+		maxDimension = func(maxFrameSize float64) float64 { return math.Sqrt(maxFrameSize*8) * 16 }
+
+		// High profiles allow higher bitrates
+		bitrateFactor = 1.25
+		if profile := h264Profile(o.PixelFormat); profile != ProfileHigh {
+			bitrateFactor = 3
+			if profile == ProfileHigh422 || profile == ProfileHigh444 {
+				bitrateFactor = 4
+			}
+		}
+	case CodecNameHEVC:
+		ls = hevcLevels
+		frameSize = float64(o.Width * o.Height)
+		maxDimension = func(maxFrameSize float64) float64 { return math.Sqrt(maxFrameSize * 8) }
+		bitrateFactor = 1
+	default:
+		err = fmt.Errorf("astiffmpeg: codec %s is not supported", codec)
+		return
+	}
+
+	// Loop through levels
+	for _, v := range ls {
+		if frameSize <= v.maxFrameSize &&
+			float64(o.Width) <= maxDimension(v.maxFrameSize) &&
+			float64(o.Height) <= maxDimension(v.maxFrameSize) &&
+			frameSize*o.FPS <= v.maxSampleRate &&
+			o.Bitrate.float64() <= v.maxBitrate*bitrateFactor*1000 {
+			l = v.level
+			return
+		}
+	}
+	err = fmt.Errorf("astiffmpeg: no %s level supports %dx%d@%s", codec, o.Width, o.Height, strconv.FormatFloat(o.FPS, 'f', -1, 64))
+	return
+}
+
+// Baseline and main profiles are not derived since they lack features most players support nowadays
+func h264Profile(pf PixelFormat) string {
+	p := string(pf)
+	switch {
+	case strings.HasPrefix(p, "yuv444"):
+		return ProfileHigh444
+	case strings.HasPrefix(p, "yuv422"):
+		return ProfileHigh422
+	case strings.HasSuffix(p, "10le") || strings.HasSuffix(p, "10be"):
+		return ProfileHigh10
+	}
+	return ProfileHigh
+}
+
+func hevcProfile(pf PixelFormat) string {
+	p := string(pf)
+	switch {
+	case strings.HasPrefix(p, "yuv444"):
+		if strings.Contains(p, "10") {
+			return ProfileHEVCMain444x10
+		}
+		return ProfileHEVCMain444x8
+	case strings.HasPrefix(p, "yuv422"):
+		return ProfileHEVCMain422x10
+	case strings.HasSuffix(p, "10le") || strings.HasSuffix(p, "10be"):
+		return ProfileHEVCMain10
+	}
+	return ProfileHEVCMain
+}
+
+// DeriveLevelAndProfile sets the lowest level and the profile supporting the stream, which avoids players
+// rejecting streams whose level is overstated
+// Supported codecs are CodecLibx264, CodecLibx265, CodecH264NVENC and CodecHEVCNVENC
+func (o *EncodingOptions) DeriveLevelAndProfile(codec string, lo LevelOptions) (err error) {
+	// Get codec name
+	var n string
+	switch codec {
+	case CodecLibx264, CodecH264NVENC:
+		n = CodecNameH264
+	case CodecLibx265, CodecHEVCNVENC:
+		n = CodecNameHEVC
+	default:
+		err = fmt.Errorf("astiffmpeg: codec %s is not supported", codec)
+		return
+	}
+
+	// Get level
+	var l float64
+	if l, err = MinimalLevel(n, lo); err != nil {
+		err = fmt.Errorf("astiffmpeg: getting minimal level failed: %w", err)
+		return
+	}
+
+	// Update options
+	switch codec {
+	case CodecLibx264, CodecH264NVENC:
+		o.Level = &l
+		o.Profile = h264Profile(lo.PixelFormat)
+	case CodecLibx265:
+		// libx265 ignores -level
+		ps := make(map[string]string)
+		for k, v := range o.X265Params {
+			ps[k] = v
+		}
+		ps["level-idc"] = strconv.FormatFloat(l, 'f', 1, 64)
+		o.X265Params = ps
+		o.Profile = hevcProfile(lo.PixelFormat)
+	case CodecHEVCNVENC:
+		o.Level = &l
+		o.Profile = hevcProfile(lo.PixelFormat)
+		if o.Profile != ProfileHEVCMain && o.Profile != ProfileHEVCMain10 {
+			o.Profile = ProfileHEVCRext
+		}
+	}
+	return
+}
@@ -0,0 +1,58 @@
+package astiffmpeg
+
+import (
+	"os/exec"
+	"reflect"
+	"testing"
+)
+
+func TestMinimalLevel(t *testing.T) {
+	for _, v := range []struct {
+		codec string
+		e     float64
+		o     LevelOptions
+	}{
+		{codec: CodecNameH264, e: 3, o: LevelOptions{FPS: 25, Height: 576, Width: 720}},
+		{codec: CodecNameH264, e: 3.1, o: LevelOptions{FPS: 30, Height: 720, Width: 1280}},
+		{codec: CodecNameH264, e: 4, o: LevelOptions{FPS: 30, Height: 1080, Width: 1920}},
+		{codec: CodecNameH264, e: 4.1, o: LevelOptions{Bitrate: Megabits(30), FPS: 30, Height: 1080, Width: 1920}},
+		{codec: CodecNameH264, e: 4.2, o: LevelOptions{FPS: 60, Height: 1080, Width: 1920}},
+		{codec: CodecNameH264, e: 5.1, o: LevelOptions{FPS: 30, Height: 2160, Width: 3840}},
+		{codec: CodecNameHEVC, e: 4, o: LevelOptions{FPS: 30, Height: 1080, Width: 1920}},
+		{codec: CodecNameHEVC, e: 4.1, o: LevelOptions{FPS: 60, Height: 1080, Width: 1920}},
+		{codec: CodecNameHEVC, e: 5.1, o: LevelOptions{FPS: 60, Height: 2160, Width: 3840}},
+	} {
+		l, err := MinimalLevel(v.codec, v.o)
+		if err != nil {
+			t.Errorf("expected no error, got %s", err)
+		}
+		if l != v.e {
+			t.Errorf("%s %+v: expected %v, got %v", v.codec, v.o, v.e, l)
+		}
+	}
+	if _, err := MinimalLevel(CodecNameH264, LevelOptions{FPS: 240, Height: 4320, Width: 7680}); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestDeriveLevelAndProfile(t *testing.T) {
+	o := EncodingOptions{}
+	if err := o.DeriveLevelAndProfile(CodecLibx264, LevelOptions{FPS: 30, Height: 1080, PixelFormat: PixelFormatYUV420P10LE, Width: 1920}); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	cmd := exec.Command("ffmpeg")
+	if err := o.adaptCmd(cmd); err != nil {
+		t.Errorf("expected no error, got %s", err)
+	}
+	e := []string{"ffmpeg", "-level", "4.0", "-profile", "high10"}
+	if !reflect.DeepEqual(e, cmd.Args) {
+		t.Errorf("expected %+v, got %+v", e, cmd.Args)
+	}
+	o = EncodingOptions{}
+	if err := o.DeriveLevelAndProfile(CodecLibx265, LevelOptions{FPS: 60, Height: 2160, Width: 3840}); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	if o.Level != nil || o.Profile != ProfileMain || o.X265Params["level-idc"] != "5.1" {
+		t.Errorf("unexpected options %+v", o)
+	}
+}
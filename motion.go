@@ -0,0 +1,349 @@
+package astiffmpeg
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// Motion event names
+// Freeze events are emitted when the picture stops changing at all, e.g. because the camera is stuck.
+const (
+	MotionEventNameEnd         = "end"
+	MotionEventNameFreezeEnd   = "freeze.end"
+	MotionEventNameFreezeStart = "freeze.start"
+	MotionEventNameStart       = "start"
+)
+
+// MotionEvent represents a motion event
+type MotionEvent struct {
+	// Only set for end events
+	End  time.Duration
+	Name string
+	// Segments containing the motion, only set for motion end events
+	Paths []string
+	Start time.Duration
+	Time  time.Time
+}
+
+// MotionRecordOptions represents motion record options
+type MotionRecordOptions struct {
+	// Directory where segments are written
+	Dir string
+	// The picture is considered as frozen once it hasn't changed during this duration. Defaults to 10s.
+	FreezeDuration time.Duration
+	// Executed when motion starts and ends, and when the picture freezes
+	OnEvent func(e MotionEvent)
+	// Motion ends once no motion has been detected during this duration. Defaults to 5s.
+	PostRoll time.Duration
+	// Segments starting up to this duration before the motion are kept. Defaults to the segment duration.
+	PreRoll time.Duration
+	// Scene change score (from 0 to 1) above which a frame is considered as containing motion. Defaults to 0.01.
+	SceneThreshold float64
+	// Defaults to 2s
+	SegmentDuration time.Duration
+}
+
+// MotionRecord monitors a live input with the scene and freezedetect filters and only keeps the recording around
+// motion events
+// The input is continuously recorded into short mpegts segments listed in Dir/segments.csv, and segments that don't
+// contain motion are deleted as the recording goes, which means no motion is lost while ffmpeg is started.
+// It returns once the input ends or the context is done.
+func (f *FFMpeg) MotionRecord(ctx context.Context, g GlobalOptions, in Input, o MotionRecordOptions) (err error) {
+	// Check options
+	if len(o.Dir) == 0 {
+		err = errors.New("astiffmpeg: no dir provided")
+		return
+	}
+
+	// Default options
+	if o.FreezeDuration <= 0 {
+		o.FreezeDuration = 10 * time.Second
+	}
+	if o.PostRoll <= 0 {
+		o.PostRoll = 5 * time.Second
+	}
+	if o.SceneThreshold <= 0 {
+		o.SceneThreshold = 0.01
+	}
+	if o.SegmentDuration <= 0 {
+		o.SegmentDuration = 2 * time.Second
+	}
+	if o.PreRoll <= 0 {
+		o.PreRoll = o.SegmentDuration
+	}
+
+	// Create recorder
+	r := newMotionRecorder(o)
+
+	// Exec
+	segments, analysis := motionRecordOutputs(o)
+	if err = f.ExecWithOptions(ctx, g, []Input{in}, segments, ExecOptions{
+		Outputs:      []Output{analysis},
+		StdErrParser: r,
+	}); err != nil {
+		err = fmt.Errorf("astiffmpeg: executing failed: %w", err)
+		return
+	}
+	return
+}
+
+func motionRecordOutputs(o MotionRecordOptions) (segments, analysis Output) {
+	segments = Output{
+		Options: &OutputOptions{
+			Encoding: &EncodingOptions{Codec: []StreamOption{{Value: CodecCopy}}},
+			Format:   "segment",
+			Muxing: &MuxingOptions{Segment: &SegmentOptions{
+				Format:   "mpegts",
+				List:     filepath.Join(o.Dir, "segments.csv"),
+				ListType: SegmentListTypeCSV,
+				Time:     &o.SegmentDuration,
+			}},
+		},
+		Path: filepath.Join(o.Dir, "segment-%06d.ts"),
+	}
+	analysis = Output{
+		Options: &OutputOptions{
+			// Freezes are detected before frames are selected so that all frames are analyzed
+			Encoding: &EncodingOptions{Filters: []StreamOption{videoStreamOption([]FilterOptions{
+				{Generic: []GenericFilter{{
+					Args: map[string]string{"d": strconv.FormatFloat(o.FreezeDuration.Seconds(), 'f', 3, 64)},
+					Name: "freezedetect",
+				}}},
+				{Select: "'gt(scene," + strconv.FormatFloat(o.SceneThreshold, 'f', 3, 64) + ")'"},
+				{Generic: []GenericFilter{{Name: "showinfo"}}},
+			})}},
+			Format:  "null",
+			Map:     &MapOptions{{Stream: &StreamSpecifier{Type: StreamSpecifierTypeVideo}}},
+			NoAudio: true,
+		},
+		Path: "-",
+	}
+	return
+}
+
+type motionSegment struct {
+	end   time.Duration
+	path  string
+	start time.Duration
+}
+
+type motionRecorder struct {
+	end          *time.Duration // End of the motion once post roll is over, waiting for its segments to be listed
+	freezeAt     *time.Duration // Time of the last freeze event that has been handled
+	frozen       bool
+	kept         map[string]bool // Segments of motions that have not been pruned yet
+	lastMotion   time.Duration
+	lastMotionAt time.Time
+	n            int // Number of the last frame logged by showinfo that has been handled
+	o            MotionRecordOptions
+	pruned       time.Duration // Segments ending before this have either been deleted or kept for good
+	start        *time.Duration
+}
+
+func newMotionRecorder(o MotionRecordOptions) *motionRecorder {
+	return &motionRecorder{
+		kept: make(map[string]bool),
+		n:    -1,
+		o:    o,
+	}
+}
+
+func (r *motionRecorder) Period() time.Duration {
+	return 500 * time.Millisecond
+}
+
+// [Parsed_showinfo_2 @ 0x55d5] n:   0 pts:  12800 pts_time:1       duration:    512 ...
+var showinfoPTSTimeRegexp = regexp.MustCompile(`\[Parsed_showinfo_\d+ @ [^\]]+\] n:\s*(\d+) pts:\s*-?\d+ pts_time:(-?[\d.]+)`)
+
+// [Parsed_freezedetect_0 @ 0x55d5] lavfi.freezedetect.freeze_start: 12.5
+var freezedetectRegexp = regexp.MustCompile(`\[Parsed_freezedetect_\d+ @ [^\]]+\] lavfi\.freezedetect\.freeze_(start|end): (-?[\d.]+)`)
+
+func (r *motionRecorder) Process(t time.Time, b *bytes.Buffer) {
+	// Only complete lines of frames that have not been handled yet are processed. Frames are identified by their
+	// number since stderr may only contain the latest logs.
+	bs := b.Bytes()
+	if idx := bytes.LastIndexByte(bs, '\n'); idx >= 0 {
+		for _, m := range showinfoPTSTimeRegexp.FindAllSubmatch(bs[:idx], -1) {
+			n, err := strconv.Atoi(string(m[1]))
+			if err != nil || n <= r.n {
+				continue
+			}
+			r.n = n
+			if v, err := strconv.ParseFloat(string(m[2]), 64); err == nil {
+				r.motion(t, time.Duration(v*float64(time.Second)))
+			}
+		}
+		for _, m := range freezedetectRegexp.FindAllSubmatch(bs[:idx], -1) {
+			if v, err := strconv.ParseFloat(string(m[2]), 64); err == nil {
+				r.freeze(t, string(m[1]) == "start", time.Duration(v*float64(time.Second)))
+			}
+		}
+	}
+
+	// Check
+	r.check(t, readMotionSegments(r.o.Dir), false)
+}
+
+// Flush ends the ongoing motion, if any, once ffmpeg has exited
+func (r *motionRecorder) Flush(t time.Time, b *bytes.Buffer) {
+	r.Process(t, b)
+	if r.start != nil && r.end == nil {
+		r.end = &r.lastMotion
+	}
+	r.check(t, readMotionSegments(r.o.Dir), true)
+}
+
+func (r *motionRecorder) motion(t time.Time, pts time.Duration) {
+	r.lastMotion = pts
+	r.lastMotionAt = t
+	if r.start != nil {
+		return
+	}
+	r.end = nil
+	r.start = &pts
+	if r.o.OnEvent != nil {
+		r.o.OnEvent(MotionEvent{
+			Name:  MotionEventNameStart,
+			Start: pts,
+			Time:  t,
+		})
+	}
+}
+
+// freeze handles freeze events, which are identified by their time since stderr may contain events that have
+// already been handled
+func (r *motionRecorder) freeze(t time.Time, start bool, pts time.Duration) {
+	// Event has already been handled
+	if start == r.frozen || (r.freezeAt != nil && pts <= *r.freezeAt) {
+		return
+	}
+
+	// Create event
+	e := MotionEvent{
+		Name:  MotionEventNameFreezeStart,
+		Start: pts,
+		Time:  t,
+	}
+	if !start {
+		e.End = pts
+		e.Name = MotionEventNameFreezeEnd
+		e.Start = *r.freezeAt
+	}
+	r.freezeAt = &pts
+	r.frozen = start
+
+	// Callback
+	if r.o.OnEvent != nil {
+		r.o.OnEvent(e)
+	}
+}
+
+func (r *motionRecorder) check(t time.Time, ss []motionSegment, final bool) {
+	// Post roll is over
+	if r.start != nil && r.end == nil && t.Sub(r.lastMotionAt) >= r.o.PostRoll {
+		end := r.lastMotion + r.o.PostRoll
+		r.end = &end
+	}
+
+	// Keep segments overlapping the motion
+	var last time.Duration
+	var ps []string
+	for _, s := range ss {
+		if s.end > last {
+			last = s.end
+		}
+		if s.end <= r.pruned {
+			continue
+		}
+		if r.start != nil && s.end > *r.start-r.o.PreRoll && (r.end == nil || s.start < *r.end) {
+			r.kept[s.path] = true
+			ps = append(ps, s.path)
+		}
+	}
+
+	// Motion has ended and all its segments have been listed
+	if r.start != nil && r.end != nil && (final || last >= *r.end) {
+		if r.o.OnEvent != nil {
+			r.o.OnEvent(MotionEvent{
+				End:   *r.end,
+				Name:  MotionEventNameEnd,
+				Paths: ps,
+				Start: *r.start,
+				Time:  t,
+			})
+		}
+		r.end = nil
+		r.start = nil
+	}
+
+	// Prune segments that can't be part of a motion anymore: they're either deleted or kept for good
+	cutoff := last - r.o.PreRoll
+	if final {
+		cutoff = last
+	}
+	if r.start != nil && *r.start-r.o.PreRoll < cutoff {
+		cutoff = *r.start - r.o.PreRoll
+	}
+	for _, s := range ss {
+		if s.end <= r.pruned || s.end > cutoff {
+			continue
+		}
+		if r.kept[s.path] {
+			delete(r.kept, s.path)
+		} else {
+			os.Remove(s.path)
+		}
+	}
+	if cutoff > r.pruned {
+		r.pruned = cutoff
+	}
+}
+
+// segment-000001.ts,2.002000,4.004000
+func readMotionSegments(dir string) (ss []motionSegment) {
+	// Open
+	f, err := os.Open(filepath.Join(dir, "segments.csv"))
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	return parseMotionSegments(dir, f)
+}
+
+func parseMotionSegments(dir string, r io.Reader) (ss []motionSegment) {
+	c := csv.NewReader(bufio.NewReader(r))
+	c.FieldsPerRecord = 3
+	for {
+		// Read
+		rs, err := c.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			continue
+		}
+
+		// Parse
+		start, errStart := strconv.ParseFloat(rs[1], 64)
+		end, errEnd := strconv.ParseFloat(rs[2], 64)
+		if errStart != nil || errEnd != nil {
+			continue
+		}
+		ss = append(ss, motionSegment{
+			end:   time.Duration(end * float64(time.Second)),
+			path:  filepath.Join(dir, rs[0]),
+			start: time.Duration(start * float64(time.Second)),
+		})
+	}
+	return
+}
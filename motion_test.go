@@ -0,0 +1,93 @@
+package astiffmpeg
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseMotionSegments(t *testing.T) {
+	e := []motionSegment{
+		{end: 2 * time.Second, path: filepath.Join("dir", "segment-000000.ts")},
+		{end: 4 * time.Second, path: filepath.Join("dir", "segment-000001.ts"), start: 2 * time.Second},
+	}
+	if g := parseMotionSegments("dir", strings.NewReader("segment-000000.ts,0.000000,2.000000\nsegment-000001.ts,2.000000,4.000000\nsegment-0000")); !reflect.DeepEqual(e, g) {
+		t.Errorf("expected %+v, got %+v", e, g)
+	}
+}
+
+func TestMotionRecorder(t *testing.T) {
+	// Create segments
+	dir := t.TempDir()
+	var ss []motionSegment
+	for idx := 0; idx < 6; idx++ {
+		s := motionSegment{
+			end:   time.Duration(idx+1) * 2 * time.Second,
+			path:  filepath.Join(dir, "segment-00000"+string(rune('0'+idx))+".ts"),
+			start: time.Duration(idx) * 2 * time.Second,
+		}
+		if err := os.WriteFile(s.path, []byte("test"), 0600); err != nil {
+			t.Fatalf("expected no error, got %s", err)
+		}
+		ss = append(ss, s)
+	}
+
+	// Process
+	var es []MotionEvent
+	r := newMotionRecorder(MotionRecordOptions{
+		Dir:      dir,
+		OnEvent:  func(e MotionEvent) { es = append(es, e) },
+		PostRoll: time.Second,
+		PreRoll:  2 * time.Second,
+	})
+	n := time.Unix(0, 0)
+	r.Process(n, bytes.NewBufferString("[Parsed_showinfo_1 @ 0x55d5] n:   0 pts:  64000 pts_time:5       duration:    512\n"))
+	r.Process(n.Add(500*time.Millisecond), bytes.NewBufferString("[Parsed_showinfo_1 @ 0x55d5] n:   0 pts:  64000 pts_time:5       duration:    512\n[Parsed_showinfo_1 @ 0x55d5] n:   1 pts:  76800 pts_time:6       duration:    512\n"))
+	r.check(n.Add(2*time.Second), ss, false)
+
+	// Assert
+	if e := []MotionEvent{
+		{Name: MotionEventNameStart, Start: 5 * time.Second, Time: n},
+		{End: 7 * time.Second, Name: MotionEventNameEnd, Paths: []string{ss[1].path, ss[2].path, ss[3].path}, Start: 5 * time.Second, Time: n.Add(2 * time.Second)},
+	}; !reflect.DeepEqual(e, es) {
+		t.Errorf("expected %+v, got %+v", e, es)
+	}
+	for idx, s := range ss {
+		_, err := os.Stat(s.path)
+		if e, g := idx >= 1 && idx != 4, err == nil; e != g {
+			t.Errorf("expected segment #%d existence to be %v, got %v", idx, e, g)
+		}
+	}
+
+	// Pruned segments are forgotten
+	if len(r.kept) > 0 {
+		t.Errorf("expected no kept segments, got %+v", r.kept)
+	}
+	if e, g := 10*time.Second, r.pruned; e != g {
+		t.Errorf("expected %s, got %s", e, g)
+	}
+}
+
+func TestMotionRecorderFreeze(t *testing.T) {
+	var es []MotionEvent
+	r := newMotionRecorder(MotionRecordOptions{
+		Dir:     t.TempDir(),
+		OnEvent: func(e MotionEvent) { es = append(es, e) },
+	})
+	n := time.Unix(0, 0)
+	l := "[Parsed_freezedetect_0 @ 0x55d5] lavfi.freezedetect.freeze_start: 5\n"
+	r.Process(n, bytes.NewBufferString(l))
+	l += "[Parsed_freezedetect_0 @ 0x55d5] lavfi.freezedetect.freeze_duration: 12.5\n[Parsed_freezedetect_0 @ 0x55d5] lavfi.freezedetect.freeze_end: 17.5\n"
+	r.Process(n.Add(time.Second), bytes.NewBufferString(l))
+	r.Process(n.Add(2*time.Second), bytes.NewBufferString(l))
+	if e := []MotionEvent{
+		{Name: MotionEventNameFreezeStart, Start: 5 * time.Second, Time: n},
+		{End: 17500 * time.Millisecond, Name: MotionEventNameFreezeEnd, Start: 5 * time.Second, Time: n.Add(time.Second)},
+	}; !reflect.DeepEqual(e, es) {
+		t.Errorf("expected %+v, got %+v", e, es)
+	}
+}
@@ -1,26 +1,69 @@
 package astiffmpeg
 
 import (
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
 	"math"
 	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/asticode/go-astikit"
 )
 
 // GlobalOptions represents global options
 type GlobalOptions struct {
+	// Show benchmarking information at the end of an encode
+	Benchmark bool
 	Log       *LogOptions
+	// Disables the stats line periodically printed to stderr, which keeps logs of very long jobs small. Since stderr
+	// parsers (e.g. the default one or health monitors) rely on it, Progress should be used instead to follow the
+	// job, see MachineReadableStats.
 	NoStats   bool
 	Overwrite *bool
+	// URL where machine readable progress is written: a file path, "pipe:1" for stdout, ExtraFilePath(0) for an
+	// extra file, or a network URL such as "tcp://127.0.0.1:1234" or "http://..."
+	// Unlike the stats line, progress is written even when NoStats is true.
+	Progress string
+	// Arguments appended as is after typed options, for options that are not supported yet. Options set by both
+	// typed and raw options with different values are rejected.
+	Raw []string
 	// Dump full command line and console output to a file named program-YYYYMMDD-HHMMSS.log in the current directory.
 	// This file can be useful for bug reports. It also implies -loglevel verbose.
 	Report bool
+	// How often stats and progress are updated (ffmpeg >= 4.4). Defaults to 500ms in ffmpeg. Increasing it avoids
+	// flooding logs and progress consumers during very long jobs.
+	StatsPeriod *time.Duration
+	// Exits after ffmpeg has used this much CPU user time. Unlike a context timeout, which kills the process from
+	// Go, it bounds runaway encodes inside ffmpeg itself, even if the Go supervisor dies. Since it's CPU time, it's
+	// not comparable to wall clock time on multithreaded encodes.
+	TimeLimit *time.Duration
+}
+
+// MachineReadableStats returns a copy of the global options where the stats line is disabled and progress is
+// written to the URL instead, which is the recommended setup for very long jobs
+// When period is <= 0, ffmpeg's default period is used.
+func (o GlobalOptions) MachineReadableStats(url string, period time.Duration) GlobalOptions {
+	o.NoStats = true
+	o.Progress = url
+	if period > 0 {
+		o.StatsPeriod = &period
+	}
+	return o
 }
 
-func (o GlobalOptions) adaptCmd(cmd *exec.Cmd) {
+func (o GlobalOptions) adaptCmd(cmd *exec.Cmd) (err error) {
+	start := len(cmd.Args)
 	cmd.Args = append(cmd.Args, "-hide_banner")
+	if o.Benchmark {
+		cmd.Args = append(cmd.Args, "-benchmark")
+	}
 	if o.Log != nil {
 		o.Log.adaptCmd(cmd)
 	}
@@ -34,9 +77,24 @@ func (o GlobalOptions) adaptCmd(cmd *exec.Cmd) {
 	if o.NoStats {
 		cmd.Args = append(cmd.Args, "-nostats")
 	}
+	if len(o.Progress) > 0 {
+		cmd.Args = append(cmd.Args, "-progress", o.Progress)
+	}
 	if o.Report {
 		cmd.Args = append(cmd.Args, "-report")
 	}
+	if o.StatsPeriod != nil {
+		cmd.Args = append(cmd.Args, "-stats_period", strconv.FormatFloat(o.StatsPeriod.Seconds(), 'f', 3, 64))
+	}
+	if o.TimeLimit != nil {
+		cmd.Args = append(cmd.Args, "-timelimit", strconv.FormatInt(int64(math.Ceil(o.TimeLimit.Seconds())), 10))
+	}
+	cmd.Args = append(cmd.Args, o.Raw...)
+	if err = normalizeCmdArgs(cmd, start); err != nil {
+		err = fmt.Errorf("astiffmpeg: normalizing options failed: %w", err)
+		return
+	}
+	return
 }
 
 // Log levels
@@ -96,7 +154,41 @@ type Number struct {
 	Value          interface{}
 }
 
+// Kilobits creates a number of kilobits
+func Kilobits(v float64) Number { return Number{Prefix: "k", Value: v} }
+
+// Megabits creates a number of megabits
+func Megabits(v float64) Number { return Number{Prefix: "M", Value: v} }
+
+// Gigabits creates a number of gigabits
+func Gigabits(v float64) Number { return Number{Prefix: "G", Value: v} }
+
+// Kilobytes creates a number of kilobytes
+func Kilobytes(v float64) Number { return Number{ByteMultiple: true, Prefix: "k", Value: v} }
+
+// Megabytes creates a number of megabytes
+func Megabytes(v float64) Number { return Number{ByteMultiple: true, Prefix: "M", Value: v} }
+
+// Kibibytes creates a number of kibibytes
+func Kibibytes(v float64) Number {
+	return Number{BinaryMultiple: true, ByteMultiple: true, Prefix: "k", Value: v}
+}
+
+// Mebibytes creates a number of mebibytes
+func Mebibytes(v float64) Number {
+	return Number{BinaryMultiple: true, ByteMultiple: true, Prefix: "M", Value: v}
+}
+
+// Gibibytes creates a number of gibibytes
+func Gibibytes(v float64) Number {
+	return Number{BinaryMultiple: true, ByteMultiple: true, Prefix: "G", Value: v}
+}
+
 func numberFromString(i string) (n Number, err error) {
+	if len(i) == 0 {
+		err = errors.New("astiffmpeg: empty number")
+		return
+	}
 	if strings.HasSuffix(i, "B") {
 		n.ByteMultiple = true
 		i = strings.TrimSuffix(i, "B")
@@ -113,18 +205,58 @@ func numberFromString(i string) (n Number, err error) {
 	return
 }
 
-func (n Number) float64() (o float64) {
-	// Get initial value
-	switch n.Value.(type) {
+// Float64 returns the number's value with its prefix applied (e.g. 1500000 for 1.5M, or 1536 for 1.5Ki), multiplied
+// by 8 when ByteMultiple is true
+func (n Number) Float64() float64 {
+	return n.float64()
+}
+
+// Compare returns -1, 0 or 1 whether the number's value with its prefix applied is lower, equal or greater than the
+// other's
+func (n Number) Compare(m Number) int {
+	switch a, b := n.float64(), m.float64(); {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// Mul multiplies the number's value by a factor, preserving its unit
+func (n Number) Mul(f float64) Number {
+	n.Value = n.value() * f
+	return n
+}
+
+// Add adds the other number to the number, the result being expressed in the number's unit
+func (n Number) Add(m Number) Number {
+	n.Value = n.value() + m.float64()/n.multiplier()
+	return n
+}
+
+// Sub subtracts the other number from the number, the result being expressed in the number's unit
+func (n Number) Sub(m Number) Number {
+	return n.Add(m.Mul(-1))
+}
+
+func (n Number) value() float64 {
+	switch v := n.Value.(type) {
 	case float64:
-		o = n.Value.(float64)
+		return v
 	case int:
-		o = float64(n.Value.(int))
-	default:
-		return
+		return float64(v)
 	}
+	return 0
+}
+
+func (n Number) float64() float64 {
+	return n.value() * n.multiplier()
+}
 
+func (n Number) multiplier() (o float64) {
 	// Get byte multiplier
+	o = 1
 	if n.ByteMultiple {
 		o *= 8
 	}
@@ -154,11 +286,8 @@ func (n Number) float64() (o float64) {
 func (n Number) string() (o string) {
 	switch n.Value.(type) {
 	case float64:
-		o = strconv.FormatFloat(n.Value.(float64), 'f', 3, 64)
-		for o[len(o)-1] == '0' {
-			o = o[:len(o)-1]
-		}
-		o = strings.TrimSuffix(o, ".")
+		// Shortest representation so that values round trip exactly
+		o = strconv.FormatFloat(n.Value.(float64), 'f', -1, 64)
 	case int:
 		o = strconv.Itoa(n.Value.(int))
 	default:
@@ -174,8 +303,29 @@ func (n Number) string() (o string) {
 	return
 }
 
+// Duration formats
+const (
+	DurationFormatSeconds     = "seconds"     // e.g. 3723.500
+	DurationFormatSexagesimal = "sexagesimal" // e.g. 01:02:03.500
+)
+
+// formatDuration formats the duration in the specified format, seconds being the default
+func formatDuration(d time.Duration, format string) string {
+	if format != DurationFormatSexagesimal {
+		return strconv.FormatFloat(d.Seconds(), 'f', 3, 64)
+	}
+	var s string
+	if d < 0 {
+		s = "-"
+		d = -d
+	}
+	d = d.Round(time.Millisecond)
+	return s + fmt.Sprintf("%02d:%02d:%02d.%03d", int(d/time.Hour), int(d%time.Hour/time.Minute), int(d%time.Minute/time.Second), int(d%time.Second/time.Millisecond))
+}
+
 // Stream specifier types
 const (
+	StreamSpecifierTypeAttachment           = "t"
 	StreamSpecifierTypeAudio                = "a"
 	StreamSpecifierTypeSubtitle             = "s"
 	StreamSpecifierTypeVideo                = "v"
@@ -186,45 +336,124 @@ const (
 type StreamSpecifier struct {
 	Index *int
 	Name  string
-	Type  string
+	// Only matches streams of the program with this id, which allows demuxing a multi-program transport stream
+	Program *int
+	Type    string
 }
 
-func (s StreamSpecifier) string() (o string) {
+func (s StreamSpecifier) string() string {
 	if len(s.Name) > 0 {
 		return s.Name
 	}
+	var ss []string
+	if s.Program != nil {
+		ss = append(ss, "p", strconv.Itoa(*s.Program))
+	}
 	if len(s.Type) > 0 {
-		o = s.Type
+		ss = append(ss, s.Type)
 	}
 	if s.Index != nil {
-		if len(s.Type) > 0 {
-			o += ":"
-		}
-		o += strconv.Itoa(*s.Index)
+		ss = append(ss, strconv.Itoa(*s.Index))
 	}
-	return
+	return strings.Join(ss, ":")
 }
 
 // Input represents an input
 type Input struct {
 	Options *InputOptions
 	Path    string
+	// When set, the input is read from stdin ("pipe:0") and Path is ignored, which allows reading in-memory buffers
+	// or network streams. Since most formats can't be probed without seeking, InputOptions.Format should be set.
+	// Only one input can have a reader.
+	Reader io.Reader
 }
 
 func (i Input) adaptCmd(cmd *exec.Cmd) (err error) {
 	if i.Options != nil {
+		start := len(cmd.Args)
 		if err = i.Options.adaptCmd(cmd); err != nil {
 			err = fmt.Errorf("astiffmpeg: adapting cmd for options failed: %w", err)
 			return
 		}
+		if err = normalizeCmdArgs(cmd, start); err != nil {
+			err = fmt.Errorf("astiffmpeg: normalizing options failed: %w", err)
+			return
+		}
 	}
-	cmd.Args = append(cmd.Args, "-i", i.Path)
+	p := i.Path
+	if i.Reader != nil {
+		p = "pipe:0"
+	}
+	cmd.Args = append(cmd.Args, "-i", p)
 	return
 }
 
 // InputOptions represents input options
 type InputOptions struct {
-	Decoding *DecodingOptions
+	// How long the input is analyzed to find stream information. The lower the faster the startup, but streams
+	// information may be incomplete.
+	AnalyzeDuration *time.Duration
+	// Number of channels and sample rate of raw PCM inputs
+	AudioChannels   *int
+	AudioSamplerate *int
+	Decoding        *DecodingOptions
+	// Key used to decrypt common encryption (mov/mp4 only)
+	DecryptionKey []byte
+	// Overrides the display matrix of the input video: horizontal flip, vertical flip and counterclockwise rotation
+	// in degrees are applied in that order (ffmpeg >= 7.0)
+	DisplayHFlip    bool
+	DisplayRotation *float64
+	DisplayVFlip    bool
+	// Forces the input format (e.g. "image2" for image sequences)
+	Format string
+	// Flags of the input format, see FormatFlag constants
+	FormatFlags []string
+	// Frame rate of image sequences
+	Framerate *float64
+	// Loops over the images of the input indefinitely (image2 only), usually combined with a decoding duration
+	Loop bool
+	// Video is not rotated according to its display matrix
+	NoAutoRotate bool
+	// How image2 interprets the input path, see PatternType constants
+	PatternType string
+	// Pixel format of rawvideo inputs
+	PixelFormat PixelFormat
+	// Number of bytes read to find stream information
+	ProbeSize *int
+	// Arguments appended as is after typed options, for options that are not supported yet. Options set by both
+	// typed and raw options with different values are rejected.
+	Raw []string
+	// Reconnects to HTTP inputs when the connection is lost, including for live streams
+	Reconnect bool
+	// Whether HTTP inputs are seekable, in which case seeking sends a range request instead of reading the whole
+	// input up to the position. By default, ffmpeg detects it from the server response.
+	Seekable *bool
+	// Index of the first image of a sequence pattern (e.g. img-%03d.jpg)
+	StartNumber *int
+	// Maximum duration of network reads and writes, after which ffmpeg fails
+	Timeout *time.Duration
+	// Size of rawvideo inputs (e.g. "1280x720")
+	VideoSize string
+}
+
+// Pattern types
+const (
+	// Path is a glob pattern (e.g. "photos/*.jpg"), not available on Windows
+	PatternTypeGlob = "glob"
+	// Path is a sequence pattern (e.g. "img-%03d.jpg")
+	PatternTypeSequence = "sequence"
+)
+
+// ImageSequenceInput creates an input reading images matching the specified glob pattern, in alphabetical order
+func ImageSequenceInput(pattern string, framerate float64) Input {
+	return Input{
+		Options: &InputOptions{
+			Format:      "image2",
+			Framerate:   &framerate,
+			PatternType: PatternTypeGlob,
+		},
+		Path: pattern,
+	}
 }
 
 func (o InputOptions) adaptCmd(cmd *exec.Cmd) (err error) {
@@ -234,6 +463,83 @@ func (o InputOptions) adaptCmd(cmd *exec.Cmd) (err error) {
 			return
 		}
 	}
+	if o.AudioChannels != nil {
+		cmd.Args = append(cmd.Args, "-ac", strconv.Itoa(*o.AudioChannels))
+	}
+	if o.AudioSamplerate != nil {
+		cmd.Args = append(cmd.Args, "-ar", strconv.Itoa(*o.AudioSamplerate))
+	}
+	if o.AnalyzeDuration != nil {
+		cmd.Args = append(cmd.Args, "-analyzeduration", strconv.FormatInt(o.AnalyzeDuration.Microseconds(), 10))
+	}
+	if len(o.DecryptionKey) > 0 {
+		cmd.Args = append(cmd.Args, "-decryption_key", hex.EncodeToString(o.DecryptionKey))
+	}
+	if o.DisplayHFlip {
+		cmd.Args = append(cmd.Args, "-display_hflip")
+	}
+	if o.DisplayRotation != nil {
+		cmd.Args = append(cmd.Args, "-display_rotation", strconv.FormatFloat(*o.DisplayRotation, 'f', -1, 64))
+	}
+	if o.DisplayVFlip {
+		cmd.Args = append(cmd.Args, "-display_vflip")
+	}
+	if o.Framerate != nil {
+		cmd.Args = append(cmd.Args, "-framerate", strconv.FormatFloat(*o.Framerate, 'f', -1, 64))
+	}
+	if o.Loop {
+		cmd.Args = append(cmd.Args, "-loop", "1")
+	}
+	if o.NoAutoRotate {
+		cmd.Args = append(cmd.Args, "-noautorotate")
+	}
+	if o.ProbeSize != nil {
+		cmd.Args = append(cmd.Args, "-probesize", strconv.Itoa(*o.ProbeSize))
+	}
+	if o.Reconnect {
+		cmd.Args = append(cmd.Args, "-reconnect", "1", "-reconnect_streamed", "1", "-reconnect_on_network_error", "1")
+	}
+	if len(o.FormatFlags) > 0 {
+		cmd.Args = append(cmd.Args, "-fflags", "+"+strings.Join(o.FormatFlags, "+"))
+	}
+	if len(o.PatternType) > 0 {
+		cmd.Args = append(cmd.Args, "-pattern_type", o.PatternType)
+	}
+	if o.Seekable != nil {
+		v := "0"
+		if *o.Seekable {
+			v = "1"
+		}
+		cmd.Args = append(cmd.Args, "-seekable", v)
+	}
+	if o.StartNumber != nil {
+		cmd.Args = append(cmd.Args, "-start_number", strconv.Itoa(*o.StartNumber))
+	}
+	if o.Timeout != nil {
+		cmd.Args = append(cmd.Args, "-rw_timeout", strconv.FormatInt(o.Timeout.Microseconds(), 10))
+	}
+	if len(o.PixelFormat) > 0 {
+		cmd.Args = append(cmd.Args, "-pixel_format", string(o.PixelFormat))
+	}
+	if len(o.VideoSize) > 0 {
+		cmd.Args = append(cmd.Args, "-video_size", o.VideoSize)
+	}
+	if len(o.Format) > 0 {
+		cmd.Args = append(cmd.Args, "-f", o.Format)
+	}
+	cmd.Args = append(cmd.Args, o.Raw...)
+	return
+}
+
+// FastAccurateSeek splits a position into an input side position, used to quickly seek to a point located margin
+// before the position, and an output side position, used to accurately decode up to the position from there
+// Margin should be greater than the input GOP duration so that the input side seek lands on a keyframe located
+// before the position.
+func FastAccurateSeek(position, margin time.Duration) (inputPosition, outputPosition time.Duration) {
+	if inputPosition = position - margin; inputPosition < 0 {
+		inputPosition = 0
+	}
+	outputPosition = position - inputPosition
 	return
 }
 
@@ -244,15 +550,60 @@ const (
 	DeinterlacingModeWeave    = "weave"
 )
 
+// Discard values
+const (
+	DiscardAll     = "all"
+	DiscardBidir   = "bidir"
+	DiscardDefault = "default"
+	DiscardNoIntra = "nointra"
+	DiscardNoKey   = "nokey"
+	DiscardNone    = "none"
+	DiscardNoRef   = "noref"
+)
+
+// Teletext formats
+const (
+	TeletextFormatASS    = "ass"
+	TeletextFormatBitmap = "bitmap"
+	TeletextFormatText   = "text"
+)
+
 // DecodingOptions represents decoding options
 type DecodingOptions struct {
-	Codec                      *StreamOption
-	DeinterlacingMode          string
-	DropSecondField            *bool
-	Duration                   time.Duration
+	// Deprecated: use Decoders instead
+	Codec *StreamOption
+	// Decoders used for the input streams, value should be a string (e.g. CodecH264CUVID)
+	Decoders          []StreamOption
+	DeinterlacingMode string
+	// Streams whose packets are discarded by the demuxer, which skips decoding unneeded streams. Value should be a
+	// string, see Discard constants.
+	Discard         []StreamOption
+	DropSecondField *bool
+	Duration        time.Duration
+	// How position, duration and offset are rendered, see DurationFormat constants. Defaults to seconds.
+	DurationFormat string
+	// Overrides Duration
+	DurationTimecode *Timecode
+	// Decoder flags, see CodecFlag constants
+	Flags                      []string
 	HardwareAcceleration       string
 	HardwareAccelerationDevice *int
-	Position                   time.Duration
+	// Decodes at 1/2 (1), 1/4 (2) or 1/8 (3) of the resolution, if supported by the decoder
+	LowRes *int
+	// Offset added to the input timestamps, which may be negative, e.g. to fix audio/video desync between inputs
+	Offset   time.Duration
+	Position time.Duration
+	// Overrides Position
+	PositionTimecode *Timecode
+	// Frames the decoder skips, see Discard constants (e.g. DiscardNoKey to decode keyframes only)
+	SkipFrame string
+	// Frames for which the decoder skips the loop filter, see Discard constants
+	SkipLoopFilter string
+	// Format of decoded teletext subtitles, see TeletextFormat constants (libzvbi_teletextdec only)
+	TeletextFormat string
+	// Teletext pages to decode, e.g. "888", "*" for all pages or "subtitle" for subtitle pages (libzvbi_teletextdec
+	// only)
+	TeletextPage string
 }
 
 func (o DecodingOptions) adaptCmd(cmd *exec.Cmd) (err error) {
@@ -265,11 +616,38 @@ func (o DecodingOptions) adaptCmd(cmd *exec.Cmd) (err error) {
 	if len(o.DeinterlacingMode) > 0 {
 		cmd.Args = append(cmd.Args, "-deint", o.DeinterlacingMode)
 	}
-	if o.Duration > 0 {
-		cmd.Args = append(cmd.Args, "-t", strconv.FormatFloat(o.Duration.Seconds(), 'f', 3, 64))
+	for idx, so := range o.Discard {
+		if err = so.adaptCmd(cmd, "-discard", func(i interface{}) (string, error) {
+			if v, ok := i.(string); ok {
+				return v, nil
+			}
+			return "", fmt.Errorf("astiffmpeg: value should be a string: %w", err)
+		}); err != nil {
+			err = fmt.Errorf("astiffmpeg: adapting cmd for -discard option #%d failed: %w", idx, err)
+			return
+		}
 	}
-	if o.Position > 0 {
-		cmd.Args = append(cmd.Args, "-ss", strconv.FormatFloat(o.Position.Seconds(), 'f', 3, 64))
+	if o.DurationTimecode != nil {
+		cmd.Args = append(cmd.Args, "-t", o.DurationTimecode.seconds())
+	} else if o.Duration > 0 {
+		cmd.Args = append(cmd.Args, "-t", formatDuration(o.Duration, o.DurationFormat))
+	}
+	if o.Offset != 0 {
+		cmd.Args = append(cmd.Args, "-itsoffset", formatDuration(o.Offset, o.DurationFormat))
+	}
+	if o.PositionTimecode != nil {
+		cmd.Args = append(cmd.Args, "-ss", o.PositionTimecode.seconds())
+	} else if o.Position > 0 {
+		cmd.Args = append(cmd.Args, "-ss", formatDuration(o.Position, o.DurationFormat))
+	}
+	if o.LowRes != nil {
+		cmd.Args = append(cmd.Args, "-lowres", strconv.Itoa(*o.LowRes))
+	}
+	if len(o.SkipFrame) > 0 {
+		cmd.Args = append(cmd.Args, "-skip_frame", o.SkipFrame)
+	}
+	if len(o.SkipLoopFilter) > 0 {
+		cmd.Args = append(cmd.Args, "-skip_loop_filter", o.SkipLoopFilter)
 	}
 	if o.DropSecondField != nil {
 		v := "0"
@@ -278,6 +656,15 @@ func (o DecodingOptions) adaptCmd(cmd *exec.Cmd) (err error) {
 		}
 		cmd.Args = append(cmd.Args, "-drop_second_field", v)
 	}
+	if len(o.TeletextFormat) > 0 {
+		cmd.Args = append(cmd.Args, "-txt_format", o.TeletextFormat)
+	}
+	if len(o.TeletextPage) > 0 {
+		cmd.Args = append(cmd.Args, "-txt_page", o.TeletextPage)
+	}
+	if len(o.Flags) > 0 {
+		cmd.Args = append(cmd.Args, "-flags", "+"+strings.Join(o.Flags, "+"))
+	}
 	if o.Codec != nil {
 		if err = o.Codec.adaptCmd(cmd, "-c", func(i interface{}) (string, error) {
 			if v, ok := i.(string); ok {
@@ -289,26 +676,198 @@ func (o DecodingOptions) adaptCmd(cmd *exec.Cmd) (err error) {
 			return
 		}
 	}
+	for idx, so := range o.Decoders {
+		if err = so.adaptCmd(cmd, "-c", func(i interface{}) (string, error) {
+			if v, ok := i.(string); ok {
+				return v, nil
+			}
+			return "", fmt.Errorf("astiffmpeg: value should be a string: %w", err)
+		}); err != nil {
+			err = fmt.Errorf("astiffmpeg: adapting cmd for -c option #%d failed: %w", idx, err)
+			return
+		}
+	}
 	return
 }
 
 // Output represents an output
 type Output struct {
-	Options *OutputOptions
-	Path    string
+	// The output is written to a temporary file, located in the same directory, which is renamed only once ffmpeg
+	// has exited successfully so that watchers never pick up partially written files. It's ignored for non file
+	// outputs such as URLs, pipes or image sequence patterns.
+	Atomic bool
+	// When the output path resolves to the same file as an input, the output is written to a temporary file which
+	// replaces the input once ffmpeg has exited successfully. Otherwise, ErrOutputOverwritesInput is returned.
+	InPlace bool
+	// By default, flags commonly required by the output format are inferred from the path when not set explicitly:
+	// faststart for mp4/mov, adts for .aac and image2 for sequence patterns (e.g. "img-%03d.png"). When true, they
+	// are not.
+	NoFormatDetection bool
+	Options           *OutputOptions
+	Path              string
+	// When set, the output is sent to the sink as it's produced instead of being written to Path, which is only used
+	// to infer the format and passed to the sink as the output name. Formats whose muxer needs to seek back in the
+	// output (e.g. mp4) are written to a temporary file first, and HLS segments are sent as soon as they're
+	// finalized.
+	Sink OutputSink
+	// When set, the output is written to stdout ("pipe:1") and Path is ignored, which allows streaming to e.g. an
+	// HTTP response or an uploader. Since the format can't be inferred from the path, OutputOptions.Format is
+	// mandatory. Only one output can have a writer.
+	Writer io.Writer
+}
+
+func (o Output) path() string {
+	if o.Writer != nil {
+		return "pipe:1"
+	}
+	return o.Path
+}
+
+// NullOutput creates an output discarding everything, which is useful for analysis or validation runs
+func NullOutput(o *OutputOptions) Output {
+	c := copyOutputOptions(o)
+	c.Format = "null"
+	return Output{
+		Options: c,
+		Path:    "-",
+	}
+}
+
+// RTMPOutput creates an output publishing to an RTMP server, whose onMetaData doesn't contain duration and
+// filesize
+func RTMPOutput(url string, o *OutputOptions) Output {
+	c := copyOutputOptions(o)
+	c.Format = "flv"
+	var m MuxingOptions
+	if c.Muxing != nil {
+		m = *c.Muxing
+	}
+	if len(m.FLVFlags) == 0 {
+		m.FLVFlags = []string{FLVFlagNoDurationFilesize}
+	}
+	c.Muxing = &m
+	return Output{
+		Options: c,
+		Path:    url,
+	}
 }
 
 func (o Output) adaptCmd(cmd *exec.Cmd) (err error) {
+	if o.Writer != nil && (o.Options == nil || o.Options.Format == "") {
+		err = errors.New("astiffmpeg: format is mandatory when writing to a writer")
+		return
+	}
+	if !o.NoFormatDetection {
+		o.Options = detectOutputOptions(o.Path, o.Options)
+	}
 	if o.Options != nil {
+		start := len(cmd.Args)
 		if err = o.Options.adaptCmd(cmd); err != nil {
 			err = fmt.Errorf("astiffmpeg: adapting cmd for output failed: %w", err)
 			return
 		}
+		if err = normalizeCmdArgs(cmd, start); err != nil {
+			err = fmt.Errorf("astiffmpeg: normalizing options failed: %w", err)
+			return
+		}
 	}
-	cmd.Args = append(cmd.Args, o.Path)
+	cmd.Args = append(cmd.Args, platformPath(o.path()))
 	return
 }
 
+var (
+	pathDirectiveRegexp     = regexp.MustCompile(`%(%|\d*[a-zA-Z])`)
+	sequenceDirectiveRegexp = regexp.MustCompile(`^%0?\d*d$`)
+)
+
+// isSequencePattern checks whether the path is an image sequence pattern (e.g. "img-%03d.png")
+// Paths containing other directives are strftime patterns (e.g. "cam-%Y%m%d.mkv") in which case %d is the day of
+// the month.
+func isSequencePattern(p string) (ok bool) {
+	for _, d := range pathDirectiveRegexp.FindAllString(p, -1) {
+		if d == "%%" {
+			continue
+		} else if !sequenceDirectiveRegexp.MatchString(d) {
+			return false
+		}
+		ok = true
+	}
+	return
+}
+
+// isPatternPath checks whether the path is either a sequence or a strftime pattern, in which case it doesn't
+// designate a single file
+func isPatternPath(p string) bool {
+	for _, d := range pathDirectiveRegexp.FindAllString(p, -1) {
+		if d != "%%" {
+			return true
+		}
+	}
+	return false
+}
+
+var outputFormatsByExtension = map[string]string{
+	".aac":  "adts",
+	".flv":  "flv",
+	".m4a":  "ipod",
+	".m4v":  "mp4",
+	".mkv":  "matroska",
+	".mov":  "mov",
+	".mp4":  "mp4",
+	".oga":  "ogg",
+	".ogg":  "ogg",
+	".opus": "ogg",
+	".ts":   "mpegts",
+	".wav":  "wav",
+	".webm": "webm",
+}
+
+// detectOutputOptions returns a copy of the options completed with what can be inferred from the output path
+func detectOutputOptions(path string, o *OutputOptions) *OutputOptions {
+	// Only files are handled
+	if path == "-" || strings.Contains(path, "://") || strings.HasPrefix(path, "pipe:") {
+		return o
+	}
+
+	// Get format
+	var f string
+	if isSequencePattern(path) {
+		f = "image2"
+	} else {
+		f = outputFormatsByExtension[strings.ToLower(filepath.Ext(path))]
+	}
+
+	// Copy options
+	c := copyOutputOptions(o)
+
+	// Format
+	var updated bool
+	if len(c.Format) == 0 && (f == "adts" || f == "image2") {
+		c.Format = f
+		updated = true
+	} else if len(c.Format) > 0 {
+		f = c.Format
+	}
+
+	// Faststart
+	if f == "ipod" || f == "mov" || f == "mp4" {
+		if c.Muxing == nil {
+			c.Muxing = &MuxingOptions{MovFlags: []string{MovFlagFaststart}}
+			updated = true
+		} else if len(c.Muxing.MovFlags) == 0 {
+			m := *c.Muxing
+			m.MovFlags = []string{MovFlagFaststart}
+			c.Muxing = &m
+			updated = true
+		}
+	}
+
+	if !updated {
+		return o
+	}
+	return c
+}
+
 // SteamOption represents an option that can be specific to a stream
 type StreamOption struct {
 	Stream *StreamSpecifier
@@ -328,6 +887,42 @@ func (o StreamOption) adaptCmd(cmd *exec.Cmd, name string, fn func(i interface{}
 	return nil
 }
 
+func audioStreamOption(v interface{}) StreamOption {
+	return StreamOption{
+		Stream: &StreamSpecifier{Type: StreamSpecifierTypeAudio},
+		Value:  v,
+	}
+}
+
+func videoStreamOption(v interface{}) StreamOption {
+	return StreamOption{
+		Stream: &StreamSpecifier{Type: StreamSpecifierTypeVideo},
+		Value:  v,
+	}
+}
+
+// Codecs
+const (
+	CodecAAC             = "aac"
+	CodecCopy            = "copy"
+	CodecDVBSub          = "dvbsub"
+	CodecFLAC            = "flac"
+	CodecH264CUVID       = "h264_cuvid"
+	CodecH264NVENC       = "h264_nvenc"
+	CodecHEVCCUVID       = "hevc_cuvid"
+	CodecHEVCNVENC       = "hevc_nvenc"
+	CodecLibaomAV1       = "libaom-av1"
+	CodecLibmp3lame      = "libmp3lame"
+	CodecLibopus         = "libopus"
+	CodecLibvorbis       = "libvorbis"
+	CodecLibwebp         = "libwebp"
+	CodecLibwebpAnim     = "libwebp_anim"
+	CodecLibx264         = "libx264"
+	CodecLibx265         = "libx265"
+	CodecLibzvbiTeletext = "libzvbi_teletextdec"
+	CodecSRT             = "srt"
+)
+
 // Coders
 const (
 	CoderAC      = "ac"
@@ -337,7 +932,7 @@ const (
 	CoderVLC     = "vlc"
 )
 
-// Presets
+// Presets (libx264 and libx265)
 const (
 	PresetUltrafast = "ultrafast"
 	PresetSuperfast = "superfast"
@@ -350,7 +945,7 @@ const (
 	PresetVeryslow  = "veryslow"
 )
 
-// Profiles
+// Profiles (libx264)
 const (
 	ProfileBaseline = "baseline"
 	ProfileHigh     = "high"
@@ -361,7 +956,11 @@ const (
 )
 
 // Rate controls
-const ()
+const (
+	RateControlCBR     = "cbr"
+	RateControlConstQP = "constqp"
+	RateControlVBR     = "vbr"
+)
 
 // Tunes
 const (
@@ -375,24 +974,458 @@ const (
 
 // OutputOptions represents output options
 type OutputOptions struct {
-	Encoding *EncodingOptions
-	Format   string
-	Map      *MapOptions
+	// Files attached to the output (matroska only, e.g. fonts or cover art)
+	Attachments []Attachment
+	// When doing stream copy, copy also non-key frames found at the beginning
+	CopyInitialNonKeyframes bool
+	// When doing stream copy, copy also frames found before the start time (true) or drop them (false)
+	CopyPriorStart *bool
+	// Value should be a string (e.g. "attached_pic", "default", "default+forced", "0", ...)
+	Dispositions []StreamOption
+	// Stops writing the output once its duration reaches this value
+	Duration time.Duration
+	// How position and duration are rendered, see DurationFormat constants. Defaults to seconds.
+	DurationFormat string
+	// Overrides Duration
+	DurationTimecode *Timecode
+	Encoding         *EncodingOptions
+	// Stops writing the output once its size exceeds this value
+	FileSizeLimit *int // bytes
+	Format        string
+	// Flags of the output format, see FormatFlag constants
+	FormatFlags []string
+	// Video sync method, see FPSMode constants (ffmpeg >= 5.1)
+	FPSMode string
+	Map     *MapOptions
+	// Index of the input chapters are copied from, -1 disables chapters copy. An ffmetadata input can be used to
+	// create chapters.
+	MapChapters *int
+	// Index of the input global metadata is copied from, -1 strips metadata
+	MapMetadata *int
+	// Output side seeking: input is decoded and discarded until the position is reached, which is slow but frame
+	// accurate when transcoding. When stream copying, the output starts at the first keyframe following the
+	// position. Input side seeking (DecodingOptions.Position) is fast and is accurate as well when transcoding, but
+	// only seeks to the closest keyframe when stream copying. See FastAccurateSeek to combine both.
+	Position time.Duration
+	// Overrides Position
+	PositionTimecode *Timecode
+	// Protocol options, when the output path is an icecast:// URL
+	Icecast *IcecastOptions
+	// Global metadata such as ID3 tags, Vorbis comments (e.g. "title", "artist", "album", ...) or custom onMetaData keys
+	// (flv only)
+	Metadata map[string]string
+	Muxing   *MuxingOptions
+	NoAudio  bool
+	NoVideo  bool
+	// Programs created in the output (mpegts only)
+	Programs []Program
+	// Arguments appended as is after typed options, for options that are not supported yet. Options set by both
+	// typed and raw options with different values are rejected.
+	Raw []string
+	// Makes the output reproducible across runs and platforms, which allows golden file testing: bitexact format
+	// and codec flags, fixed creation time and single threaded encoding
+	Reproducible bool
+	// Finishes encoding when the shortest output stream ends
+	Shortest bool
+	// Value should be a map[string]string
+	StreamMetadata []StreamOption
+}
+
+// FPS modes
+const (
+	FPSModeAuto        = "auto"
+	FPSModeCFR         = "cfr"
+	FPSModeDrop        = "drop"
+	FPSModePassthrough = "passthrough"
+	FPSModeVFR         = "vfr"
+)
+
+// copyOutputOptions returns a copy of the options, or empty options when nil, so that they can be updated without
+// updating the caller's. Nested options and slices are shared and must be copied as well before being updated.
+func copyOutputOptions(o *OutputOptions) *OutputOptions {
+	c := &OutputOptions{}
+	if o != nil {
+		*c = *o
+	}
+	return c
 }
 
 func (o OutputOptions) adaptCmd(cmd *exec.Cmd) (err error) {
+	if o.Reproducible {
+		o = o.reproducible()
+	}
 	if o.Map != nil {
 		o.Map.adaptCmd(cmd)
 	}
+	for idx, a := range o.Attachments {
+		a.adaptCmd(cmd, idx)
+	}
+	if o.MapChapters != nil {
+		cmd.Args = append(cmd.Args, "-map_chapters", strconv.Itoa(*o.MapChapters))
+	}
+	if o.MapMetadata != nil {
+		cmd.Args = append(cmd.Args, "-map_metadata", strconv.Itoa(*o.MapMetadata))
+	}
 	if o.Encoding != nil {
 		if err = o.Encoding.adaptCmd(cmd); err != nil {
 			err = fmt.Errorf("astiffmpeg: adapting cmd for encoding options failed: %w", err)
 			return
 		}
 	}
+	if o.CopyInitialNonKeyframes {
+		cmd.Args = append(cmd.Args, "-copyinkf")
+	}
+	if o.CopyPriorStart != nil {
+		v := "0"
+		if *o.CopyPriorStart {
+			v = "1"
+		}
+		cmd.Args = append(cmd.Args, "-copypriorss", v)
+	}
+	for idx, so := range o.Dispositions {
+		if err = so.adaptCmd(cmd, "-disposition", func(i interface{}) (string, error) {
+			if v, ok := i.(string); ok {
+				return v, nil
+			}
+			return "", fmt.Errorf("astiffmpeg: value should be a string: %w", err)
+		}); err != nil {
+			err = fmt.Errorf("astiffmpeg: adapting cmd for -disposition option #%d failed: %w", idx, err)
+			return
+		}
+	}
+	if o.DurationTimecode != nil {
+		cmd.Args = append(cmd.Args, "-t", o.DurationTimecode.seconds())
+	} else if o.Duration > 0 {
+		cmd.Args = append(cmd.Args, "-t", formatDuration(o.Duration, o.DurationFormat))
+	}
+	if o.FileSizeLimit != nil {
+		cmd.Args = append(cmd.Args, "-fs", strconv.Itoa(*o.FileSizeLimit))
+	}
+	if o.Icecast != nil {
+		o.Icecast.adaptCmd(cmd)
+	}
+	if len(o.Metadata) > 0 {
+		var ks []string
+		for k := range o.Metadata {
+			ks = append(ks, k)
+		}
+		sort.Strings(ks)
+		for _, k := range ks {
+			cmd.Args = append(cmd.Args, "-metadata", k+"="+o.Metadata[k])
+		}
+	}
+	if o.Muxing != nil {
+		if err = o.Muxing.adaptCmd(cmd); err != nil {
+			err = fmt.Errorf("astiffmpeg: adapting cmd for muxing options failed: %w", err)
+			return
+		}
+	}
+	if o.NoAudio {
+		cmd.Args = append(cmd.Args, "-an")
+	}
+	if o.NoVideo {
+		cmd.Args = append(cmd.Args, "-vn")
+	}
+	if o.PositionTimecode != nil {
+		cmd.Args = append(cmd.Args, "-ss", o.PositionTimecode.seconds())
+	} else if o.Position > 0 {
+		cmd.Args = append(cmd.Args, "-ss", formatDuration(o.Position, o.DurationFormat))
+	}
+	for _, p := range o.Programs {
+		cmd.Args = append(cmd.Args, "-program", p.string())
+	}
+	if o.Shortest {
+		cmd.Args = append(cmd.Args, "-shortest")
+	}
+	for idx, so := range o.StreamMetadata {
+		m, ok := so.Value.(map[string]string)
+		if !ok {
+			err = fmt.Errorf("astiffmpeg: value of -metadata:s option #%d should be a map[string]string", idx)
+			return
+		}
+		var ks []string
+		for k := range m {
+			ks = append(ks, k)
+		}
+		sort.Strings(ks)
+		for _, k := range ks {
+			if err = (StreamOption{Stream: so.Stream, Value: k + "=" + m[k]}).adaptCmd(cmd, "-metadata:s", func(i interface{}) (string, error) {
+				return i.(string), nil
+			}); err != nil {
+				err = fmt.Errorf("astiffmpeg: adapting cmd for -metadata:s option #%d failed: %w", idx, err)
+				return
+			}
+		}
+	}
+	if len(o.FPSMode) > 0 {
+		cmd.Args = append(cmd.Args, "-fps_mode", o.FPSMode)
+	}
+	if len(o.FormatFlags) > 0 {
+		cmd.Args = append(cmd.Args, "-fflags", "+"+strings.Join(o.FormatFlags, "+"))
+	}
 	if len(o.Format) > 0 {
 		cmd.Args = append(cmd.Args, "-f", o.Format)
 	}
+	cmd.Args = append(cmd.Args, o.Raw...)
+	return
+}
+
+// ReproducibleCreationTime is the creation time of reproducible outputs
+const ReproducibleCreationTime = "1970-01-01T00:00:00.000000Z"
+
+func (o OutputOptions) reproducible() OutputOptions {
+	// Format flags
+	if !containsString(o.FormatFlags, FormatFlagBitexact) {
+		o.FormatFlags = append(append([]string{}, o.FormatFlags...), FormatFlagBitexact)
+	}
+
+	// Metadata
+	m := map[string]string{"creation_time": ReproducibleCreationTime}
+	for k, v := range o.Metadata {
+		if k != "creation_time" {
+			m[k] = v
+		}
+	}
+	o.Metadata = m
+
+	// Encoding
+	e := copyEncodingOptions(o.Encoding)
+	if !containsString(e.Flags, CodecFlagBitexact) {
+		e.Flags = append(append([]string{}, e.Flags...), CodecFlagBitexact)
+	}
+	e.Threads = astikit.IntPtr(1)
+	o.Encoding = e
+	return o
+}
+
+func containsString(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Strict values
+const (
+	StrictExperimental = "experimental"
+	StrictNormal       = "normal"
+	StrictStrict       = "strict"
+	StrictUnofficial   = "unofficial"
+	StrictVery         = "very"
+)
+
+// Codec flags
+const (
+	// Only writes platform, build and time independent data, and only uses bitexact algorithms
+	CodecFlagBitexact     = "bitexact"
+	CodecFlagGlobalHeader = "global_header"
+	CodecFlagLowDelay     = "low_delay"
+)
+
+// Format flags
+const (
+	// Only writes platform, build and time independent data, e.g. the muxer version is not written
+	FormatFlagBitexact       = "bitexact"
+	FormatFlagDiscardCorrupt = "discardcorrupt"
+	FormatFlagFlushPackets   = "flush_packets"
+	FormatFlagGenPTS         = "genpts"
+	// Input packets are not buffered when analyzing the input, which reduces latency
+	FormatFlagNoBuffer = "nobuffer"
+)
+
+// Program represents an output program
+type Program struct {
+	Number *int
+	// Indexes of the output streams belonging to the program
+	Streams []int
+	Title   string
+}
+
+func (p Program) string() string {
+	var ss []string
+	if len(p.Title) > 0 {
+		// Program options are split the same way as filter arguments, which means ":" must be escaped
+		ss = append(ss, "title="+filterValueReplacer.Replace(p.Title))
+	}
+	if p.Number != nil {
+		ss = append(ss, "program_num="+strconv.Itoa(*p.Number))
+	}
+	for _, s := range p.Streams {
+		ss = append(ss, "st="+strconv.Itoa(s))
+	}
+	return strings.Join(ss, ":")
+}
+
+// Attachment represents a file attached to the output
+type Attachment struct {
+	// Mandatory for the matroska muxer (e.g. "application/x-truetype-font" or "image/jpeg")
+	MimeType string
+	Path     string
+}
+
+// Attachments are indexed among attachment streams, which means no other attachment stream should be mapped
+func (a Attachment) adaptCmd(cmd *exec.Cmd, idx int) {
+	cmd.Args = append(cmd.Args, "-attach", a.Path)
+	if len(a.MimeType) > 0 {
+		cmd.Args = append(cmd.Args, "-metadata:s:"+StreamSpecifierTypeAttachment+":"+strconv.Itoa(idx), "mimetype="+a.MimeType)
+	}
+}
+
+// FLV flags
+const (
+	FLVFlagAACSeqHeaderDetect = "aac_seq_header_detect"
+	FLVFlagAddKeyframeIndex   = "add_keyframe_index"
+	// Duration and filesize are not written in the onMetaData, since they are meaningless for live publishes and
+	// they are rejected by some strict RTMP servers
+	FLVFlagNoDurationFilesize = "no_duration_filesize"
+	FLVFlagNoMetadata         = "no_metadata"
+	FLVFlagNoSequenceEnd      = "no_sequence_end"
+)
+
+// Matroska default track modes
+const (
+	// Default flags are inferred from the dispositions or the first stream of each type
+	MatroskaDefaultTrackModeInfer = "infer"
+	// Same as infer except that subtitles never get the default flag
+	MatroskaDefaultTrackModeInferNoSubs = "infer_no_subs"
+	// Default flags are only set from dispositions
+	MatroskaDefaultTrackModePassthrough = "passthrough"
+)
+
+// Mov flags
+const (
+	MovFlagDefaultBaseMoof = "default_base_moof"
+	MovFlagEmptyMoov       = "empty_moov"
+	MovFlagFaststart       = "faststart"
+	MovFlagFragKeyframe    = "frag_keyframe"
+)
+
+// MuxingOptions represents muxing options
+type MuxingOptions struct {
+	// Common encryption (mov/mp4 only)
+	CENC *CENCOptions
+	// Maximum size of a cluster (matroska only)
+	ClusterSizeLimit *int // bytes
+	// Maximum duration of a cluster (matroska only)
+	ClusterTimeLimit *time.Duration
+	DASH             *DASHOptions
+	// Packets are flushed to the output as soon as they're muxed
+	FlushPackets *bool
+	// Flags of the flv muxer (e.g. FLVFlagNoDurationFilesize)
+	FLVFlags []string
+	// Hash algorithm used by the hash, framehash and streamhash muxers (e.g. HashAlgorithmSHA256)
+	Hash string
+	HLS  *HLSOptions
+	// Version of the ID3v2 header written by the mp3 muxer, 3 is the most compatible one
+	ID3v2Version *int
+	// Number of times animated images loop (webp, avif and gif muxers), 0 means infinite
+	Loop *int
+	// How the default flag of tracks is set (matroska only), see MatroskaDefaultTrackMode constants
+	// It's unrelated to the DefaultDuration of tracks, which the muxer derives from the frame rate of the streams
+	// and which can therefore be set with EncodingOptions.Framerate.
+	MatroskaDefaultTrackMode string
+	// Maximum duration between two interleaved packets. 0 means infinite and leads to packets being buffered until
+	// a packet is available for every stream.
+	MaxInterleaveDelta *time.Duration
+	// Maximum number of packets buffered per stream while waiting for all streams to be initialized. Increase it to
+	// fix "Too many packets buffered for output stream" errors.
+	MaxMuxingQueueSize *int
+	// Flags of the mov/mp4 muxer (e.g. MovFlagFaststart)
+	MovFlags []string
+	// Maximum demux-decode delay
+	MuxDelay *time.Duration
+	// Initial demux-decode delay
+	MuxPreload *time.Duration
+	// Space reserved at the beginning of the file for the cues so that the index is written upfront, which makes the
+	// output seekable while being streamed (matroska only)
+	ReserveIndexSpace *int // bytes
+	Segment           *SegmentOptions
+	// Timescale used for video tracks by the mov/mp4 muxer (e.g. 90000)
+	VideoTrackTimescale *int
+	WHIP                *WHIPOptions
+	// Whether the mp3 muxer writes an ID3v1 footer
+	WriteID3v1 *bool
+}
+
+func (o MuxingOptions) adaptCmd(cmd *exec.Cmd) (err error) {
+	if o.CENC != nil {
+		var args []string
+		if args, err = o.CENC.args(); err != nil {
+			err = fmt.Errorf("astiffmpeg: creating cenc args failed: %w", err)
+			return
+		}
+		cmd.Args = append(cmd.Args, args...)
+	}
+	if o.DASH != nil {
+		o.DASH.adaptCmd(cmd)
+	}
+	if o.ClusterSizeLimit != nil {
+		cmd.Args = append(cmd.Args, "-cluster_size_limit", strconv.Itoa(*o.ClusterSizeLimit))
+	}
+	if o.ClusterTimeLimit != nil {
+		cmd.Args = append(cmd.Args, "-cluster_time_limit", strconv.FormatInt(o.ClusterTimeLimit.Milliseconds(), 10))
+	}
+	if len(o.FLVFlags) > 0 {
+		cmd.Args = append(cmd.Args, "-flvflags", "+"+strings.Join(o.FLVFlags, "+"))
+	}
+	if o.FlushPackets != nil {
+		v := "0"
+		if *o.FlushPackets {
+			v = "1"
+		}
+		cmd.Args = append(cmd.Args, "-flush_packets", v)
+	}
+	if len(o.Hash) > 0 {
+		cmd.Args = append(cmd.Args, "-hash", o.Hash)
+	}
+	if o.HLS != nil {
+		o.HLS.adaptCmd(cmd)
+	}
+	if o.ID3v2Version != nil {
+		cmd.Args = append(cmd.Args, "-id3v2_version", strconv.Itoa(*o.ID3v2Version))
+	}
+	if o.MaxInterleaveDelta != nil {
+		cmd.Args = append(cmd.Args, "-max_interleave_delta", strconv.FormatInt(o.MaxInterleaveDelta.Microseconds(), 10))
+	}
+	if o.MaxMuxingQueueSize != nil {
+		cmd.Args = append(cmd.Args, "-max_muxing_queue_size", strconv.Itoa(*o.MaxMuxingQueueSize))
+	}
+	if o.Loop != nil {
+		cmd.Args = append(cmd.Args, "-loop", strconv.Itoa(*o.Loop))
+	}
+	if len(o.MatroskaDefaultTrackMode) > 0 {
+		cmd.Args = append(cmd.Args, "-default_mode", o.MatroskaDefaultTrackMode)
+	}
+	if len(o.MovFlags) > 0 {
+		cmd.Args = append(cmd.Args, "-movflags", "+"+strings.Join(o.MovFlags, "+"))
+	}
+	if o.MuxDelay != nil {
+		cmd.Args = append(cmd.Args, "-muxdelay", strconv.FormatFloat(o.MuxDelay.Seconds(), 'f', 3, 64))
+	}
+	if o.MuxPreload != nil {
+		cmd.Args = append(cmd.Args, "-muxpreload", strconv.FormatFloat(o.MuxPreload.Seconds(), 'f', 3, 64))
+	}
+	if o.ReserveIndexSpace != nil {
+		cmd.Args = append(cmd.Args, "-reserve_index_space", strconv.Itoa(*o.ReserveIndexSpace))
+	}
+	if o.Segment != nil {
+		o.Segment.adaptCmd(cmd)
+	}
+	if o.VideoTrackTimescale != nil {
+		cmd.Args = append(cmd.Args, "-video_track_timescale", strconv.Itoa(*o.VideoTrackTimescale))
+	}
+	if o.WHIP != nil {
+		o.WHIP.adaptCmd(cmd)
+	}
+	if o.WriteID3v1 != nil {
+		v := "0"
+		if *o.WriteID3v1 {
+			v = "1"
+		}
+		cmd.Args = append(cmd.Args, "-write_id3v1", v)
+	}
 	return
 }
 
@@ -405,37 +1438,106 @@ type ComplexFilterOption struct {
 
 // EncodingOptions represents encoding options
 type EncodingOptions struct {
+	// Whether A53 closed captions found in the input are embedded (libx264, nvenc, ...)
+	A53CC *bool
+	// Intended application type, "voip", "audio" or "lowdelay" (libopus only)
+	Application     string
+	AudioChannels   *int
 	AudioSamplerate *int
-	BFrames         *int
-	Bitrate         []StreamOption
-	BStrategy       *int
-	BufSize         *Number
-	Codec           []StreamOption
-	Coder           string
-	ComplexFilter   string
-	ComplexFilters  []ComplexFilterOption
-	ConstantQuality *float64
-	CRF             *int
+	// Enables average bitrate mode (libmp3lame only)
+	AverageBitrate bool
+	BFrames        *int
+	Bitrate        []StreamOption
+	// Value should be a string (e.g. BitstreamFilterRemoveH264SEI)
+	BitstreamFilters []StreamOption
+	BStrategy        *int
+	BufSize          *Number
+	Codec            []StreamOption
+	Coder            string
+	ComplexFilter    string
+	ComplexFilters   []ComplexFilterOption
+	// Compression level (flac, libopus, ...)
+	CompressionLevel *int
+	ConstantQuality  *float64
+	// Speed level, see SpeedLevel constants (libvpx-vp9 and libaom-av1 only)
+	CPUUsed *int
+	CRF     *int
+	// Value can be a Ratio (e.g. 1/90000) or a string (e.g. "demux" or "filter")
+	EncoderTimeBase []StreamOption
 	Filters         []StreamOption
-	Framerate       *float64
-	Frames          []StreamOption
-	GOP             *int
-	KeyintMin       *int
-	Level           *float64
-	Maxrate         []StreamOption
-	Minrate         []StreamOption
-	Preset          string
-	Profile         string
-	Quality         []StreamOption
-	RateControl     string
-	SCThreshold     *int
-	Tune            string
+	// Codec flags, see CodecFlag constants
+	Flags          []string
+	ForceKeyFrames string
+	Framerate      *float64
+	Frames         []StreamOption
+	GOP            *int
+	// Quality factor from 0 to 100 (libwebp and libwebp_anim only)
+	ImageQuality *float64
+	KeyintMin    *int
+	Level        *float64
+	// libwebp and libwebp_anim only
+	Lossless *bool
+	Maxrate  []StreamOption
+	Minrate  []StreamOption
+	// Signal HRD information, "vbr" or "cbr" (libx264 only)
+	NALHRD      string
+	PixelFormat PixelFormat
+	Preset      string
+	// Value should be the path of an ffpreset file, see WriteFFPreset (-fpre)
+	PresetFiles []StreamOption
+	// Value should be the name of an ffpreset file looked up in ffmpeg's datadir (-pre)
+	PresetNames []StreamOption
+	Profile     string
+	Quality     []StreamOption
+	RateControl string
+	SCThreshold *int
+	// Encodes in still picture mode, needed for AVIF images (libaom-av1 only)
+	StillPicture *bool
+	// Strictness of standards compliance, see Strict constants
+	Strict string
+	// Codec tags, value should be a string (e.g. "hvc1")
+	Tags    []StreamOption
+	Threads *int
+	Tune    string
+	// Variable bitrate mode, "on", "off" or "constrained" (libopus only)
+	VBR        string
+	X265Params map[string]string
+}
+
+// copyEncodingOptions returns a copy of the options, or empty options when nil, so that they can be updated without
+// updating the caller's. Slices are shared and must be copied as well before being updated.
+func copyEncodingOptions(o *EncodingOptions) *EncodingOptions {
+	c := &EncodingOptions{}
+	if o != nil {
+		*c = *o
+	}
+	return c
 }
 
 func (o EncodingOptions) adaptCmd(cmd *exec.Cmd) (err error) {
+	if err = o.validate(); err != nil {
+		err = fmt.Errorf("astiffmpeg: validating encoding options failed: %w", err)
+		return
+	}
+	if o.A53CC != nil {
+		v := "0"
+		if *o.A53CC {
+			v = "1"
+		}
+		cmd.Args = append(cmd.Args, "-a53cc", v)
+	}
+	if len(o.Application) > 0 {
+		cmd.Args = append(cmd.Args, "-application", o.Application)
+	}
+	if o.AudioChannels != nil {
+		cmd.Args = append(cmd.Args, "-ac", strconv.Itoa(*o.AudioChannels))
+	}
 	if o.AudioSamplerate != nil {
 		cmd.Args = append(cmd.Args, "-ar", strconv.Itoa(*o.AudioSamplerate))
 	}
+	if o.AverageBitrate {
+		cmd.Args = append(cmd.Args, "-abr", "1")
+	}
 	if o.BFrames != nil {
 		cmd.Args = append(cmd.Args, "-bf", strconv.Itoa(*o.BFrames))
 	}
@@ -450,6 +1552,17 @@ func (o EncodingOptions) adaptCmd(cmd *exec.Cmd) (err error) {
 			return
 		}
 	}
+	for idx, ro := range o.BitstreamFilters {
+		if err = ro.adaptCmd(cmd, "-bsf", func(i interface{}) (string, error) {
+			if v, ok := i.(string); ok {
+				return v, nil
+			}
+			return "", fmt.Errorf("astiffmpeg: value should be a string: %w", err)
+		}); err != nil {
+			err = fmt.Errorf("astiffmpeg: adapting cmd for -bsf option #%d failed: %w", idx, err)
+			return
+		}
+	}
 	if o.BStrategy != nil {
 		cmd.Args = append(cmd.Args, "-b_strategy", strconv.Itoa(*o.BStrategy))
 	}
@@ -487,35 +1600,85 @@ func (o EncodingOptions) adaptCmd(cmd *exec.Cmd) (err error) {
 		}
 		cmd.Args = append(cmd.Args, "-filter_complex", strings.Join(vs, ";"))
 	}
+	if o.CompressionLevel != nil {
+		cmd.Args = append(cmd.Args, "-compression_level", strconv.Itoa(*o.CompressionLevel))
+	}
 	if o.ConstantQuality != nil {
 		cmd.Args = append(cmd.Args, "-cq", strconv.FormatFloat(*o.ConstantQuality, 'f', 3, 64))
 	}
+	if o.CPUUsed != nil {
+		cmd.Args = append(cmd.Args, "-cpu-used", strconv.Itoa(*o.CPUUsed))
+	}
 	if o.CRF != nil {
 		cmd.Args = append(cmd.Args, "-crf", strconv.Itoa(*o.CRF))
 	}
+	for idx, ro := range o.EncoderTimeBase {
+		if err = ro.adaptCmd(cmd, "-enc_time_base", func(i interface{}) (string, error) {
+			switch v := i.(type) {
+			case Ratio:
+				return v.string(), nil
+			case string:
+				return v, nil
+			}
+			return "", fmt.Errorf("astiffmpeg: value should be a Ratio or a string: %w", err)
+		}); err != nil {
+			err = fmt.Errorf("astiffmpeg: adapting cmd for -enc_time_base option #%d failed: %w", idx, err)
+			return
+		}
+	}
 	for idx, ro := range o.Filters {
 		if err = ro.adaptCmd(cmd, "-filter", func(i interface{}) (string, error) {
-			if v, ok := i.(FilterOptions); ok {
-				return v.string(), nil
+			// Filters are applied in the order of the slice
+			var fos []FilterOptions
+			switch v := i.(type) {
+			case FilterOptions:
+				fos = []FilterOptions{v}
+			case []FilterOptions:
+				fos = v
+			default:
+				return "", fmt.Errorf("astiffmpeg: value should be a FilterOptions or a []FilterOptions: %w", err)
 			}
-			return "", fmt.Errorf("astiffmpeg: value should be a FilterOptions: %w", err)
+			var ss []string
+			for fidx, fo := range fos {
+				if err := fo.validate(ro.Stream); err != nil {
+					return "", fmt.Errorf("astiffmpeg: validating filter options #%d failed: %w", fidx, err)
+				}
+				ss = append(ss, fo.string())
+			}
+			return strings.Join(ss, ","), nil
 		}); err != nil {
 			err = fmt.Errorf("astiffmpeg: adapting cmd for -filter option #%d failed: %w", idx, err)
 			return
 		}
 	}
+	if len(o.Flags) > 0 {
+		cmd.Args = append(cmd.Args, "-flags", "+"+strings.Join(o.Flags, "+"))
+	}
+	if len(o.ForceKeyFrames) > 0 {
+		cmd.Args = append(cmd.Args, "-force_key_frames", o.ForceKeyFrames)
+	}
 	if o.Framerate != nil {
 		cmd.Args = append(cmd.Args, "-r", strconv.FormatFloat(*o.Framerate, 'f', 3, 64))
 	}
 	if o.GOP != nil {
 		cmd.Args = append(cmd.Args, "-g", strconv.Itoa(*o.GOP))
 	}
+	if o.ImageQuality != nil {
+		cmd.Args = append(cmd.Args, "-quality", strconv.FormatFloat(*o.ImageQuality, 'f', -1, 64))
+	}
 	if o.KeyintMin != nil {
 		cmd.Args = append(cmd.Args, "-keyint_min", strconv.Itoa(*o.KeyintMin))
 	}
 	if o.Level != nil {
 		cmd.Args = append(cmd.Args, "-level", strconv.FormatFloat(*o.Level, 'f', 1, 64))
 	}
+	if o.Lossless != nil {
+		v := "0"
+		if *o.Lossless {
+			v = "1"
+		}
+		cmd.Args = append(cmd.Args, "-lossless", v)
+	}
 	for idx, ro := range o.Maxrate {
 		if err = ro.adaptCmd(cmd, "-maxrate", func(i interface{}) (string, error) {
 			if v, ok := i.(Number); ok {
@@ -538,9 +1701,37 @@ func (o EncodingOptions) adaptCmd(cmd *exec.Cmd) (err error) {
 			return
 		}
 	}
+	if len(o.NALHRD) > 0 {
+		cmd.Args = append(cmd.Args, "-nal-hrd", o.NALHRD)
+	}
+	if len(o.PixelFormat) > 0 {
+		cmd.Args = append(cmd.Args, "-pix_fmt", string(o.PixelFormat))
+	}
 	if len(o.Preset) > 0 {
 		cmd.Args = append(cmd.Args, "-preset", o.Preset)
 	}
+	for idx, ro := range o.PresetFiles {
+		if err = ro.adaptCmd(cmd, "-fpre", func(i interface{}) (string, error) {
+			if v, ok := i.(string); ok {
+				return v, nil
+			}
+			return "", errors.New("astiffmpeg: value should be a string")
+		}); err != nil {
+			err = fmt.Errorf("astiffmpeg: adapting cmd for -fpre option #%d failed: %w", idx, err)
+			return
+		}
+	}
+	for idx, ro := range o.PresetNames {
+		if err = ro.adaptCmd(cmd, "-pre", func(i interface{}) (string, error) {
+			if v, ok := i.(string); ok {
+				return v, nil
+			}
+			return "", errors.New("astiffmpeg: value should be a string")
+		}); err != nil {
+			err = fmt.Errorf("astiffmpeg: adapting cmd for -pre option #%d failed: %w", idx, err)
+			return
+		}
+	}
 	if len(o.Profile) > 0 {
 		cmd.Args = append(cmd.Args, "-profile", o.Profile)
 	}
@@ -561,9 +1752,48 @@ func (o EncodingOptions) adaptCmd(cmd *exec.Cmd) (err error) {
 	if o.SCThreshold != nil {
 		cmd.Args = append(cmd.Args, "-sc_threshold", strconv.Itoa(*o.SCThreshold))
 	}
+	if o.StillPicture != nil {
+		v := "0"
+		if *o.StillPicture {
+			v = "1"
+		}
+		cmd.Args = append(cmd.Args, "-still-picture", v)
+	}
+	if len(o.Strict) > 0 {
+		cmd.Args = append(cmd.Args, "-strict", o.Strict)
+	}
+	for idx, ro := range o.Tags {
+		if err = ro.adaptCmd(cmd, "-tag", func(i interface{}) (string, error) {
+			if v, ok := i.(string); ok {
+				return v, nil
+			}
+			return "", fmt.Errorf("astiffmpeg: value should be a string: %w", err)
+		}); err != nil {
+			err = fmt.Errorf("astiffmpeg: adapting cmd for -tag option #%d failed: %w", idx, err)
+			return
+		}
+	}
+	if o.Threads != nil {
+		cmd.Args = append(cmd.Args, "-threads", strconv.Itoa(*o.Threads))
+	}
 	if len(o.Tune) > 0 {
 		cmd.Args = append(cmd.Args, "-tune", o.Tune)
 	}
+	if len(o.VBR) > 0 {
+		cmd.Args = append(cmd.Args, "-vbr", o.VBR)
+	}
+	if len(o.X265Params) > 0 {
+		var ks []string
+		for k := range o.X265Params {
+			ks = append(ks, k)
+		}
+		sort.Strings(ks)
+		var ps []string
+		for _, k := range ks {
+			ps = append(ps, k+"="+o.X265Params[k])
+		}
+		cmd.Args = append(cmd.Args, "-x265-params", strings.Join(ps, ":"))
+	}
 	for idx, ro := range o.Frames {
 		if err = ro.adaptCmd(cmd, "-frames", func(i interface{}) (string, error) {
 			if v, ok := i.(int); ok {
@@ -581,7 +1811,9 @@ func (o EncodingOptions) adaptCmd(cmd *exec.Cmd) (err error) {
 type PixelFormat string
 
 const (
-	PixelFormatRGBA PixelFormat = "rgba"
+	PixelFormatRGBA        PixelFormat = "rgba"
+	PixelFormatYUV420P     PixelFormat = "yuv420p"
+	PixelFormatYUV420P10LE PixelFormat = "yuv420p10le"
 )
 
 // Format represents a format filter
@@ -612,8 +1844,10 @@ func (r Ratio) string() string {
 
 // Scale represents a scale
 type Scale struct {
-	Height *int
-	Width  *int
+	// "decrease" or "increase"
+	ForceOriginalAspectRatio string
+	Height                   *int
+	Width                    *int
 }
 
 func (s Scale) string() string {
@@ -628,27 +1862,249 @@ func (s Scale) string() string {
 	} else {
 		ss = append(ss, "w=-1")
 	}
+	if len(s.ForceOriginalAspectRatio) > 0 {
+		ss = append(ss, "force_original_aspect_ratio="+s.ForceOriginalAspectRatio)
+	}
+	return strings.Join(ss, ":")
+}
+
+// Crop represents a crop filter
+// Values are expressions, empty values fall back to ffmpeg's defaults
+type Crop struct {
+	Height string
+	Width  string
+	X      string
+	Y      string
+}
+
+func (c Crop) string() string {
+	var ss []string
+	for _, v := range []struct{ k, v string }{
+		{k: "w", v: c.Width},
+		{k: "h", v: c.Height},
+		{k: "x", v: c.X},
+		{k: "y", v: c.Y},
+	} {
+		if len(v.v) > 0 {
+			ss = append(ss, v.k+"="+v.v)
+		}
+	}
+	return strings.Join(ss, ":")
+}
+
+// Pad represents a pad filter
+// Values are expressions, empty values fall back to ffmpeg's defaults
+type Pad struct {
+	Color  string
+	Height string
+	Width  string
+	X      string
+	Y      string
+}
+
+func (p Pad) string() string {
+	var ss []string
+	for _, v := range []struct{ k, v string }{
+		{k: "w", v: p.Width},
+		{k: "h", v: p.Height},
+		{k: "x", v: p.X},
+		{k: "y", v: p.Y},
+		{k: "color", v: p.Color},
+	} {
+		if len(v.v) > 0 {
+			ss = append(ss, v.k+"="+v.v)
+		}
+	}
+	return strings.Join(ss, ":")
+}
+
+// Thumbnail represents a thumbnail filter which selects the most representative frame of each batch of frames
+type Thumbnail struct {
+	N *int // Batch size
+}
+
+func (t Thumbnail) string() string {
+	if t.N != nil {
+		return fmt.Sprintf("n=%d", *t.N)
+	}
+	return ""
+}
+
+// Fade types
+const (
+	FadeTypeIn  = "in"
+	FadeTypeOut = "out"
+)
+
+// Fade represents a video fade filter
+type Fade struct {
+	Color     string // Defaults to black
+	Duration  time.Duration
+	StartTime time.Duration
+	Type      string
+}
+
+func (f Fade) string() string {
+	ss := []string{
+		"t=" + f.Type,
+		"st=" + strconv.FormatFloat(f.StartTime.Seconds(), 'f', 3, 64),
+		"d=" + strconv.FormatFloat(f.Duration.Seconds(), 'f', 3, 64),
+	}
+	if len(f.Color) > 0 {
+		ss = append(ss, "color="+f.Color)
+	}
+	return strings.Join(ss, ":")
+}
+
+// AFade represents an audio fade filter
+type AFade struct {
+	Curve     string // Defaults to "tri" (linear)
+	Duration  time.Duration
+	StartTime time.Duration
+	Type      string
+}
+
+func (f AFade) string() string {
+	ss := []string{
+		"t=" + f.Type,
+		"st=" + strconv.FormatFloat(f.StartTime.Seconds(), 'f', 3, 64),
+		"d=" + strconv.FormatFloat(f.Duration.Seconds(), 'f', 3, 64),
+	}
+	if len(f.Curve) > 0 {
+		ss = append(ss, "curve="+f.Curve)
+	}
+	return strings.Join(ss, ":")
+}
+
+// FadeInOutFilters creates video and audio filters fading the clip in from black and silence at its beginning, and
+// out to black and silence at its end
+// Durations must be positive, and fades in and out must not overlap.
+func FadeInOutFilters(clipDuration, fadeDuration time.Duration) ([]StreamOption, error) {
+	// Invalid durations
+	if clipDuration <= 0 {
+		return nil, errors.New("astiffmpeg: clip duration must be positive")
+	} else if fadeDuration <= 0 {
+		return nil, errors.New("astiffmpeg: fade duration must be positive")
+	} else if 2*fadeDuration > clipDuration {
+		return nil, fmt.Errorf("astiffmpeg: fades of %s don't fit in a clip of %s", fadeDuration, clipDuration)
+	}
+	return []StreamOption{
+		videoStreamOption(FilterOptions{Fades: []Fade{
+			{Duration: fadeDuration, Type: FadeTypeIn},
+			{Duration: fadeDuration, StartTime: clipDuration - fadeDuration, Type: FadeTypeOut},
+		}}),
+		audioStreamOption(FilterOptions{AFades: []AFade{
+			{Duration: fadeDuration, Type: FadeTypeIn},
+			{Duration: fadeDuration, StartTime: clipDuration - fadeDuration, Type: FadeTypeOut},
+		}}),
+	}, nil
+}
+
+// ZoomPan represents a zoompan filter, used for Ken Burns effects or smooth digital zooms
+// Expressions are evaluated for each output frame and can use variables such as "zoom", "pzoom", "on", "iw" or "ih"
+type ZoomPan struct {
+	Duration *int     // Number of output frames produced out of each input frame
+	FPS      *float64 // Output frame rate
+	Size     string   // Output size (e.g. "1280x720" or "hd720")
+	X        string   // X expression
+	Y        string   // Y expression
+	Zoom     string   // Zoom expression
+}
+
+func (z ZoomPan) string() string {
+	var ss []string
+	if len(z.Zoom) > 0 {
+		ss = append(ss, "z='"+z.Zoom+"'")
+	}
+	if len(z.X) > 0 {
+		ss = append(ss, "x='"+z.X+"'")
+	}
+	if len(z.Y) > 0 {
+		ss = append(ss, "y='"+z.Y+"'")
+	}
+	if z.Duration != nil {
+		ss = append(ss, "d="+strconv.Itoa(*z.Duration))
+	}
+	if len(z.Size) > 0 {
+		ss = append(ss, "s="+z.Size)
+	}
+	if z.FPS != nil {
+		ss = append(ss, "fps="+strconv.FormatFloat(*z.FPS, 'f', -1, 64))
+	}
 	return strings.Join(ss, ":")
 }
 
 // FilterOptions represents filter options
+// Filters applied to the same stream can be provided as a []FilterOptions when their order matters (e.g. crop
+// before scale)
 type FilterOptions struct {
-	Format   *Format
-	SAR      *Ratio
-	Scale    *Scale
-	ScaleNPP *Scale
-	Select   string
+	AFades    []AFade
+	Crop      *Crop
+	Fades     []Fade
+	Format    *Format
+	Generic   []GenericFilter
+	Pad       *Pad
+	SAR       *Ratio
+	Scale     *Scale
+	ScaleNPP  *Scale
+	Select    string
+	Thumbnail *Thumbnail
+	ZoomPan   *ZoomPan
 }
 
 func (o FilterOptions) add(k, v string) string {
+	if v == "" {
+		return k
+	}
 	return fmt.Sprintf("%s=%s", k, v)
 }
 
+func (o FilterOptions) hasAudioFilters() bool {
+	return len(o.AFades) > 0
+}
+
+func (o FilterOptions) hasVideoFilters() bool {
+	return o.Crop != nil || len(o.Fades) > 0 || o.Format != nil || o.Pad != nil || o.SAR != nil || o.Scale != nil ||
+		o.ScaleNPP != nil || o.Select != "" || o.Thumbnail != nil || o.ZoomPan != nil
+}
+
+func (o FilterOptions) validate(s *StreamSpecifier) error {
+	if s == nil {
+		return nil
+	}
+	switch s.Type {
+	case StreamSpecifierTypeAudio:
+		if o.hasVideoFilters() {
+			return errors.New("astiffmpeg: video filters can't be applied to audio streams")
+		}
+	case StreamSpecifierTypeVideo, StreamSpecifierTypeVideoAndNotThumbnail:
+		if o.hasAudioFilters() {
+			return errors.New("astiffmpeg: audio filters can't be applied to video streams")
+		}
+	}
+	return nil
+}
+
 func (o FilterOptions) string() string {
 	var items []string
+	for _, f := range o.AFades {
+		items = append(items, o.add("afade", f.string()))
+	}
+	if o.Crop != nil {
+		items = append(items, o.add("crop", o.Crop.string()))
+	}
+	for _, f := range o.Fades {
+		items = append(items, o.add("fade", f.string()))
+	}
 	if o.Format != nil {
 		items = append(items, o.add("format", o.Format.string()))
 	}
+	for _, f := range o.Generic {
+		items = append(items, f.String())
+	}
+	if o.Pad != nil {
+		items = append(items, o.add("pad", o.Pad.string()))
+	}
 	if o.SAR != nil {
 		items = append(items, o.add("setsar", o.SAR.string()))
 	}
@@ -661,6 +2117,12 @@ func (o FilterOptions) string() string {
 	if o.Select != "" {
 		items = append(items, o.add("select", o.Select))
 	}
+	if o.Thumbnail != nil {
+		items = append(items, o.add("thumbnail", o.Thumbnail.string()))
+	}
+	if o.ZoomPan != nil {
+		items = append(items, o.add("zoompan", o.ZoomPan.string()))
+	}
 	return strings.Join(items, ",")
 }
 
@@ -676,13 +2138,24 @@ func (os MapOptions) adaptCmd(cmd *exec.Cmd) {
 // MapOption represents a map option
 type MapOption struct {
 	InputFileID int
-	Stream      *StreamSpecifier
+	// Maps the output of a complex filter graph instead of an input stream
+	Label string
+	// Doesn't fail if the stream doesn't exist
+	Optional bool
+	Stream   *StreamSpecifier
 }
 
 func (o MapOption) adaptCmd(cmd *exec.Cmd) {
+	if len(o.Label) > 0 {
+		cmd.Args = append(cmd.Args, "-map", "["+o.Label+"]")
+		return
+	}
 	v := strconv.Itoa(o.InputFileID)
 	if o.Stream != nil {
 		v += ":" + o.Stream.string()
 	}
+	if o.Optional {
+		v += "?"
+	}
 	cmd.Args = append(cmd.Args, "-map", v)
 }
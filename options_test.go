@@ -2,7 +2,12 @@ package astiffmpeg
 
 import (
 	"math"
+	"os/exec"
+	"reflect"
 	"testing"
+	"time"
+
+	"github.com/asticode/go-astikit"
 )
 
 func TestNumber(t *testing.T) {
@@ -21,6 +26,9 @@ func TestNumber(t *testing.T) {
 		{f: 12.0 * 8 * math.Pow(1024, 3), i: "12GiB", s: "12GiB"},
 		{f: 12.0 * 8 * math.Pow(1024, 4), i: "12TiB", s: "12TiB"},
 		{f: 12.0 * 8 * math.Pow(1024, 5), i: "12PiB", s: "12PiB"},
+		{f: 4.5 * math.Pow(1000, 2), i: "4.5M", s: "4.5M"},
+		{f: 4.5678 * math.Pow(1000, 2), i: "4.5678M", s: "4.5678M"},
+		{hasError: true, i: ""},
 	} {
 		n, err := numberFromString(i.i)
 		if i.hasError {
@@ -40,3 +48,213 @@ func TestNumber(t *testing.T) {
 		}
 	}
 }
+
+func TestNumberHelpers(t *testing.T) {
+	if e, g := "4500k", Kilobits(4500).string(); e != g {
+		t.Errorf("expected %s, got %s", e, g)
+	}
+	if e, g := 2*8*math.Pow(1024, 2), Mebibytes(2).Float64(); e != g {
+		t.Errorf("expected %v, got %v", e, g)
+	}
+	if e, g := "3000k", Kilobits(4500).Mul(2.0/3).string(); e != g {
+		t.Errorf("expected %s, got %s", e, g)
+	}
+	if e, g := "6000k", Kilobits(4500).Add(Megabits(1.5)).string(); e != g {
+		t.Errorf("expected %s, got %s", e, g)
+	}
+	if e, g := "4000k", Kilobits(4500).Sub(Number{Value: 500000}).string(); e != g {
+		t.Errorf("expected %s, got %s", e, g)
+	}
+	if e, g := -1, Kilobits(999).Compare(Megabits(1)); e != g {
+		t.Errorf("expected %d, got %d", e, g)
+	}
+	if e, g := 0, Kilobits(1000).Compare(Megabits(1)); e != g {
+		t.Errorf("expected %d, got %d", e, g)
+	}
+}
+
+func TestFadeInOutFilters(t *testing.T) {
+	fs, err := FadeInOutFilters(10*time.Second, time.Second)
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+	cmd := exec.Command("ffmpeg")
+	if err = (EncodingOptions{Filters: fs}).adaptCmd(cmd); err != nil {
+		t.Errorf("expected no error, got %s", err.Error())
+	}
+	e := []string{"ffmpeg",
+		"-filter:v", "fade=t=in:st=0.000:d=1.000,fade=t=out:st=9.000:d=1.000",
+		"-filter:a", "afade=t=in:st=0.000:d=1.000,afade=t=out:st=9.000:d=1.000",
+	}
+	if !reflect.DeepEqual(e, cmd.Args) {
+		t.Errorf("expected %+v, got %+v", e, cmd.Args)
+	}
+	for _, v := range [][2]time.Duration{{0, time.Second}, {10 * time.Second, 0}, {10 * time.Second, -time.Second}, {time.Second, 2 * time.Second}, {time.Second, 600 * time.Millisecond}} {
+		if _, err = FadeInOutFilters(v[0], v[1]); err == nil {
+			t.Errorf("expected error for clip %s and fade %s", v[0], v[1])
+		}
+	}
+}
+
+func TestFilters(t *testing.T) {
+	cmd := exec.Command("ffmpeg")
+	if err := (EncodingOptions{Filters: []StreamOption{audioStreamOption(FilterOptions{Scale: &Scale{}})}}).adaptCmd(cmd); err == nil {
+		t.Error("expected error")
+	}
+	cmd = exec.Command("ffmpeg")
+	if err := (EncodingOptions{Filters: []StreamOption{videoStreamOption([]FilterOptions{
+		{Crop: &Crop{Height: "ih-20", Width: "iw-20"}},
+		{SAR: &Ratio{Antecedent: 1, Consequent: 1}, Scale: &Scale{Height: astikit.IntPtr(720), Width: astikit.IntPtr(1280)}},
+	})}}).adaptCmd(cmd); err != nil {
+		t.Errorf("expected no error, got %s", err.Error())
+	}
+	e := []string{"ffmpeg", "-filter:v", "crop=w=iw-20:h=ih-20,setsar=1/1,scale=h=720:w=1280"}
+	if !reflect.DeepEqual(e, cmd.Args) {
+		t.Errorf("expected %+v, got %+v", e, cmd.Args)
+	}
+}
+
+func TestDetectOutputOptions(t *testing.T) {
+	o := &OutputOptions{Format: "mp4"}
+	for _, v := range []struct {
+		e    *OutputOptions
+		i    *OutputOptions
+		path string
+	}{
+		{e: &OutputOptions{Muxing: &MuxingOptions{MovFlags: []string{MovFlagFaststart}}}, path: "/tmp/out.MP4"},
+		{e: &OutputOptions{Format: "adts"}, path: "out.aac"},
+		{path: "out.mkv"},
+		{e: &OutputOptions{Format: "image2"}, path: "img-%03d.png"},
+		{e: &OutputOptions{Format: "mp4", Muxing: &MuxingOptions{MovFlags: []string{MovFlagFaststart}}}, i: o, path: "out"},
+		{e: &OutputOptions{Format: "mpegts"}, i: &OutputOptions{Format: "mpegts"}, path: "out.mp4"},
+		{e: &OutputOptions{Muxing: &MuxingOptions{MovFlags: []string{MovFlagFragKeyframe}}}, i: &OutputOptions{Muxing: &MuxingOptions{MovFlags: []string{MovFlagFragKeyframe}}}, path: "out.mp4"},
+		{path: "out.ts"},
+		{path: "cam-%Y%m%d.mkv"},
+		{path: "cam-%Y%m%d.ts"},
+		{path: "100%%d.ts"},
+		{path: "http://host/out.mp4"},
+	} {
+		if g := detectOutputOptions(v.path, v.i); !reflect.DeepEqual(v.e, g) {
+			t.Errorf("expected %+v, got %+v for %s", v.e, g, v.path)
+		}
+	}
+
+	// Detection can be disabled
+	cmd := exec.Command("ffmpeg")
+	if err := (Output{NoFormatDetection: true, Path: "out.mp4"}).adaptCmd(cmd); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	if e := []string{"ffmpeg", "out.mp4"}; !reflect.DeepEqual(e, cmd.Args) {
+		t.Errorf("expected %+v, got %+v", e, cmd.Args)
+	}
+	if o.Muxing != nil {
+		t.Error("expected input options not to be updated")
+	}
+}
+
+func TestIsSequencePattern(t *testing.T) {
+	for p, e := range map[string]bool{
+		"img-%d.png":       true,
+		"img-%03d.png":     true,
+		"cam-%Y%m%d.mkv":   false,
+		"cam-%d.mkv":       true,
+		"100%%d.png":       false,
+		"out.mp4":          false,
+		"img-%%-%04d.jpeg": true,
+	} {
+		if g := isSequencePattern(p); g != e {
+			t.Errorf("expected %v, got %v for %s", e, g, p)
+		}
+	}
+	if isPatternPath("100%%.mp4") {
+		t.Error("expected false, got true")
+	}
+	if !isPatternPath("cam-%Y%m%d.mkv") {
+		t.Error("expected true, got false")
+	}
+}
+
+func TestStreamSpecifier(t *testing.T) {
+	for e, s := range map[string]StreamSpecifier{
+		"a":       {Type: StreamSpecifierTypeAudio},
+		"v:1":     {Index: astikit.IntPtr(1), Type: StreamSpecifierTypeVideo},
+		"p:2":     {Program: astikit.IntPtr(2)},
+		"p:2:a:0": {Index: astikit.IntPtr(0), Program: astikit.IntPtr(2), Type: StreamSpecifierTypeAudio},
+		"label":   {Index: astikit.IntPtr(0), Name: "label"},
+	} {
+		if g := s.string(); g != e {
+			t.Errorf("expected %s, got %s", e, g)
+		}
+	}
+	if e, g := "title=Channel 1:program_num=1:st=0:st=1", (Program{Number: astikit.IntPtr(1), Streams: []int{0, 1}, Title: "Channel 1"}).string(); e != g {
+		t.Errorf("expected %s, got %s", e, g)
+	}
+	if e, g := `title=News\: 24\'7:st=0`, (Program{Streams: []int{0}, Title: "News: 24'7"}).string(); e != g {
+		t.Errorf("expected %s, got %s", e, g)
+	}
+}
+
+func TestFastAccurateSeek(t *testing.T) {
+	i, o := FastAccurateSeek(time.Minute, 10*time.Second)
+	if e := 50 * time.Second; i != e {
+		t.Errorf("expected %s, got %s", e, i)
+	}
+	if e := 10 * time.Second; o != e {
+		t.Errorf("expected %s, got %s", e, o)
+	}
+	i, o = FastAccurateSeek(5*time.Second, 10*time.Second)
+	if i != 0 {
+		t.Errorf("expected 0, got %s", i)
+	}
+	if e := 5 * time.Second; o != e {
+		t.Errorf("expected %s, got %s", e, o)
+	}
+}
+
+func TestMachineReadableStats(t *testing.T) {
+	cmd := exec.Command("ffmpeg")
+	if err := (GlobalOptions{}).MachineReadableStats(ExtraFilePath(0), 5*time.Second).adaptCmd(cmd); err != nil {
+		t.Errorf("expected no error, got %s", err.Error())
+	}
+	e := []string{"ffmpeg", "-hide_banner", "-nostats", "-progress", "pipe:3", "-stats_period", "5.000"}
+	if !reflect.DeepEqual(e, cmd.Args) {
+		t.Errorf("expected %+v, got %+v", e, cmd.Args)
+	}
+}
+
+func TestDurationFormat(t *testing.T) {
+	cmd := exec.Command("ffmpeg")
+	if err := (DecodingOptions{
+		Duration:       time.Hour + 2*time.Minute + 3500*time.Millisecond,
+		DurationFormat: DurationFormatSexagesimal,
+		Offset:         -1500 * time.Millisecond,
+		Position:       10 * time.Second,
+	}).adaptCmd(cmd); err != nil {
+		t.Errorf("expected no error, got %s", err.Error())
+	}
+	e := []string{"ffmpeg", "-t", "01:02:03.500", "-itsoffset", "-00:00:01.500", "-ss", "00:00:10.000"}
+	if !reflect.DeepEqual(e, cmd.Args) {
+		t.Errorf("expected %+v, got %+v", e, cmd.Args)
+	}
+	if e, g := "-1.500", formatDuration(-1500*time.Millisecond, ""); e != g {
+		t.Errorf("expected %s, got %s", e, g)
+	}
+}
+
+func TestReproducible(t *testing.T) {
+	cmd := &exec.Cmd{}
+	e := &EncodingOptions{Flags: []string{CodecFlagBitexact}}
+	if err := (OutputOptions{
+		Encoding:     e,
+		Metadata:     map[string]string{"creation_time": "now", "title": "t"},
+		Reproducible: true,
+	}).adaptCmd(cmd); err != nil {
+		t.Fatal(err)
+	}
+	if e := []string{"-flags", "+bitexact", "-threads", "1", "-metadata", "creation_time=" + ReproducibleCreationTime, "-metadata", "title=t", "-fflags", "+bitexact"}; !reflect.DeepEqual(e, cmd.Args) {
+		t.Errorf("expected %+v, got %+v", e, cmd.Args)
+	}
+	if e.Threads != nil {
+		t.Error("expected encoding options not to be updated")
+	}
+}
@@ -0,0 +1,69 @@
+package astiffmpeg
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrOutputOverwritesInput is returned when the output path resolves to the same file as an input
+var ErrOutputOverwritesInput = errors.New("astiffmpeg: output overwrites an input")
+
+// prepareOutput makes sure the output doesn't destroy an input and, when needed, updates the output so that it's
+// written to a temporary path. In that case the returned function must be executed once ffmpeg has exited.
+func prepareOutput(in []Input, out Output) (Output, func(err error) error, error) {
+	// Output overwrites an input
+	if outputOverwritesInput(in, out) {
+		// In place output is not allowed
+		if !out.InPlace {
+			return out, nil, fmt.Errorf("astiffmpeg: %s: %w", out.Path, ErrOutputOverwritesInput)
+		}
+	} else if !out.Atomic || !isFileOutput(out.Path) {
+		return out, nil, nil
+	}
+
+	// Write to a temporary path
+	p := out.Path
+	out.Path = temporaryPath(p)
+	return out, func(err error) error {
+		// ffmpeg failed
+		if err != nil {
+			os.Remove(out.Path)
+			return nil
+		}
+
+		// Rename
+		if err = os.Rename(out.Path, p); err != nil {
+			return fmt.Errorf("astiffmpeg: renaming %s to %s failed: %w", out.Path, p, err)
+		}
+		return nil
+	}, nil
+}
+
+func isFileOutput(p string) bool {
+	return p != "-" && !strings.Contains(p, "://") && !strings.HasPrefix(p, "pipe:") && !isPatternPath(p)
+}
+
+func outputOverwritesInput(in []Input, out Output) bool {
+	oi, err := os.Stat(out.Path)
+	if err != nil {
+		return false
+	}
+	for _, i := range in {
+		if ii, err := os.Stat(i.Path); err == nil && os.SameFile(oi, ii) {
+			return true
+		}
+	}
+	return false
+}
+
+// Temporary path is hidden, located in the same directory so that the rename is atomic, and keeps the extension so
+// that the format is still inferred properly
+func temporaryPath(p string) string {
+	ext := filepath.Ext(p)
+	return filepath.Join(filepath.Dir(p), "."+strings.TrimSuffix(filepath.Base(p), ext)+"."+strconv.FormatInt(time.Now().UnixNano(), 10)+".tmp"+ext)
+}
@@ -0,0 +1,79 @@
+package astiffmpeg
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPrepareOutput(t *testing.T) {
+	d := t.TempDir()
+	p := filepath.Join(d, "in.mp4")
+	if err := os.WriteFile(p, []byte("in"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	in := []Input{{Path: p}}
+
+	// Different file
+	o, fn, err := prepareOutput(in, Output{Path: filepath.Join(d, "out.mp4")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fn != nil || o.Path != filepath.Join(d, "out.mp4") {
+		t.Errorf("expected output not to be updated, got %+v", o)
+	}
+
+	// Same file
+	if _, _, err = prepareOutput(in, Output{Path: filepath.Join(d, ".", "in.mp4")}); !errors.Is(err, ErrOutputOverwritesInput) {
+		t.Errorf("expected ErrOutputOverwritesInput, got %v", err)
+	}
+
+	// In place
+	if o, fn, err = prepareOutput(in, Output{InPlace: true, Path: p}); err != nil {
+		t.Fatal(err)
+	}
+	if o.Path == p || filepath.Dir(o.Path) != d || filepath.Ext(o.Path) != ".mp4" {
+		t.Errorf("invalid temporary path %s", o.Path)
+	}
+	if err = os.WriteFile(o.Path, []byte("out"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err = fn(nil); err != nil {
+		t.Fatal(err)
+	}
+	if b, err := os.ReadFile(p); err != nil || string(b) != "out" {
+		t.Errorf("expected input to be replaced, got %s (%v)", b, err)
+	}
+}
+
+func TestPrepareOutputAtomic(t *testing.T) {
+	d := t.TempDir()
+	p := filepath.Join(d, "out.mp4")
+
+	// Non file output
+	o, fn, err := prepareOutput(nil, Output{Atomic: true, Path: filepath.Join(d, "img-%03d.jpg")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fn != nil || o.Path != filepath.Join(d, "img-%03d.jpg") {
+		t.Errorf("expected output not to be updated, got %+v", o)
+	}
+
+	// Failure
+	if o, fn, err = prepareOutput(nil, Output{Atomic: true, Path: p}); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(o.Path, []byte("partial"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err = fn(errors.New("failed")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = os.Stat(o.Path); !os.IsNotExist(err) {
+		t.Errorf("expected temporary file to be removed, got %v", err)
+	}
+	if _, err = os.Stat(p); !os.IsNotExist(err) {
+		t.Errorf("expected output not to exist, got %v", err)
+	}
+}
@@ -15,6 +15,12 @@ type StdErrParser interface {
 	Process(t time.Time, b *bytes.Buffer)
 }
 
+// StdErrFlusher represents a stderr parser that wants to be notified once the process has exited, instead of being
+// provided with one last Process call, so that final results can be captured
+type StdErrFlusher interface {
+	Flush(t time.Time, b *bytes.Buffer)
+}
+
 // DefaultStdErrParser creates the default stderr parser
 func DefaultStdErrParser(period time.Duration, fn func(r DefaultStdErrResults)) StdErrParser {
 	return &defaultStdErrParser{
@@ -33,28 +39,57 @@ func (p defaultStdErrParser) Period() time.Duration {
 }
 
 func (p defaultStdErrParser) Process(t time.Time, b *bytes.Buffer) {
-	// Split on \n
-	var lines = bytes.Split(b.Bytes(), []byte("\n"))
-	if len(lines) == 0 {
+	// Get last progress line
+	l, ok := lastProgressLine(b.Bytes())
+	if !ok {
 		return
 	}
 
-	// Split on \r
-	var items = bytes.Split(lines[len(lines)-1], []byte("\r"))
-	if len(items) < 2 {
+	// Parse results
+	r := p.parseResults(l)
+
+	// Execute callback
+	p.fn(r)
+}
+
+// Flush parses the final stats line, which, contrary to progress lines, ends with \n
+func (p defaultStdErrParser) Flush(t time.Time, b *bytes.Buffer) {
+	// Get last stats line
+	l, ok := lastStatsLine(b.Bytes())
+	if !ok {
 		return
 	}
 
 	// Parse results
-	r := p.parseResults(items[len(items)-2])
+	r := p.parseResults(l)
+	r.Final = true
 
 	// Execute callback
 	p.fn(r)
 }
 
+// lastProgressLine returns the last complete progress line, progress lines being separated by \r
+func lastProgressLine(b []byte) (l []byte, ok bool) {
+	// Split on \n
+	var lines = bytes.Split(b, []byte("\n"))
+	if len(lines) == 0 {
+		return
+	}
+
+	// Split on \r
+	var items = bytes.Split(lines[len(lines)-1], []byte("\r"))
+	if len(items) < 2 {
+		return
+	}
+	return items[len(items)-2], true
+}
+
 // DefaultStdErrResults represents default stderr results
 type DefaultStdErrResults struct {
 	Bitrate *float64 // bits/s
+	Drop    *int     // Number of dropped frames
+	Dup     *int     // Number of duplicated frames
+	Final   bool     // Whether results have been parsed once the process has exited
 	FPS     *int
 	Frame   *int
 	Q       *float64
@@ -81,6 +116,14 @@ func (p defaultStdErrParser) parseResults(b []byte) (r DefaultStdErrResults) {
 				if p, err := strconv.ParseFloat(v, 64); err == nil {
 					r.Bitrate = astikit.Float64Ptr(p * 1000)
 				}
+			case "drop":
+				if p, err := strconv.Atoi(v); err == nil {
+					r.Drop = astikit.IntPtr(p)
+				}
+			case "dup":
+				if p, err := strconv.Atoi(v); err == nil {
+					r.Dup = astikit.IntPtr(p)
+				}
 			case "frame":
 				if p, err := strconv.Atoi(v); err == nil {
 					r.Frame = astikit.IntPtr(p)
@@ -93,7 +136,7 @@ func (p defaultStdErrParser) parseResults(b []byte) (r DefaultStdErrResults) {
 				if p, err := strconv.ParseFloat(v, 64); err == nil {
 					r.Q = astikit.Float64Ptr(p)
 				}
-			case "size":
+			case "Lsize", "size":
 				if n, err := numberFromString(v); err == nil {
 					r.Size = astikit.IntPtr(int(n.float64()))
 				}
@@ -102,30 +145,7 @@ func (p defaultStdErrParser) parseResults(b []byte) (r DefaultStdErrResults) {
 					r.Speed = astikit.Float64Ptr(p)
 				}
 			case "time":
-				// Split on .
-				var d time.Duration
-				ps := strings.Split(v, ".")
-				if len(ps) > 1 {
-					if p, err := strconv.Atoi(ps[1]); err == nil {
-						// For now we make the assumption that milliseconds are in this format ".99" and not ".999"
-						d += time.Duration(p*10) * time.Millisecond
-					}
-				}
-
-				// Split on :
-				ps = strings.Split(ps[0], ":")
-				if len(ps) >= 3 {
-					if p, err := strconv.Atoi(ps[0]); err == nil {
-						d += time.Duration(p) * time.Hour
-					}
-					if p, err := strconv.Atoi(ps[1]); err == nil {
-						d += time.Duration(p) * time.Minute
-					}
-					if p, err := strconv.Atoi(ps[2]); err == nil {
-						d += time.Duration(p) * time.Second
-					}
-				}
-				r.Time = astikit.DurationPtr(d)
+				r.Time = astikit.DurationPtr(durationFromString(v))
 			}
 		}
 
@@ -138,3 +158,83 @@ func (p defaultStdErrParser) parseResults(b []byte) (r DefaultStdErrResults) {
 	}
 	return
 }
+
+// 00:11:38.14
+func durationFromString(v string) (d time.Duration) {
+	// Split on .
+	ps := strings.Split(v, ".")
+	if len(ps) > 1 {
+		if p, err := strconv.Atoi(ps[1]); err == nil {
+			// For now we make the assumption that milliseconds are in this format ".99" and not ".999"
+			d += time.Duration(p*10) * time.Millisecond
+		}
+	}
+
+	// Split on :
+	ps = strings.Split(ps[0], ":")
+	if len(ps) >= 3 {
+		if p, err := strconv.Atoi(ps[0]); err == nil {
+			d += time.Duration(p) * time.Hour
+		}
+		if p, err := strconv.Atoi(ps[1]); err == nil {
+			d += time.Duration(p) * time.Minute
+		}
+		if p, err := strconv.Atoi(ps[2]); err == nil {
+			d += time.Duration(p) * time.Second
+		}
+	}
+	return
+}
+
+// CompositeStdErrParser creates a stderr parser dispatching stderr to several parsers, each at its own period, so
+// that progress and analysis filters outputs (e.g. loudnorm or silencedetect) can be consumed in the same run
+func CompositeStdErrParser(ps ...StdErrParser) StdErrParser {
+	c := &compositeStdErrParser{
+		lasts: make([]time.Time, len(ps)),
+		ps:    ps,
+	}
+	for _, p := range ps {
+		if c.period == 0 || p.Period() < c.period {
+			c.period = p.Period()
+		}
+	}
+	return c
+}
+
+type compositeStdErrParser struct {
+	lasts  []time.Time
+	period time.Duration
+	ps     []StdErrParser
+}
+
+func (c *compositeStdErrParser) Period() time.Duration {
+	return c.period
+}
+
+func (c *compositeStdErrParser) Process(t time.Time, b *bytes.Buffer) {
+	for idx, p := range c.ps {
+		// Ticks may be slightly late or early, hence the tolerance
+		if !c.lasts[idx].IsZero() && t.Sub(c.lasts[idx]) < p.Period()-c.period/2 {
+			continue
+		}
+		c.lasts[idx] = t
+
+		// Each parser is provided with its own buffer so that reading it doesn't consume it for the others
+		p.Process(t, bytes.NewBuffer(b.Bytes()))
+	}
+}
+
+// Flush flushes every parser regardless of its period
+func (c *compositeStdErrParser) Flush(t time.Time, b *bytes.Buffer) {
+	for _, p := range c.ps {
+		flushStdErrParser(p, t, bytes.NewBuffer(b.Bytes()))
+	}
+}
+
+func flushStdErrParser(p StdErrParser, t time.Time, b *bytes.Buffer) {
+	if f, ok := p.(StdErrFlusher); ok {
+		f.Flush(t, b)
+	} else {
+		p.Process(t, b)
+	}
+}
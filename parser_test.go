@@ -1,6 +1,7 @@
 package astiffmpeg
 
 import (
+	"bytes"
 	"reflect"
 	"testing"
 	"time"
@@ -23,4 +24,59 @@ func TestDefaultStdErrParser(t *testing.T) {
 	if !reflect.DeepEqual(e, g) {
 		t.Errorf("expected %+v, got %+v", e, g)
 	}
+	e.Drop = astikit.IntPtr(2)
+	e.Dup = astikit.IntPtr(12)
+	g = p.parseResults([]byte("frame=17448 fps=254 q=31.0 size=  176032kB time=00:11:38.14 bitrate=2065.5kbits/s dup=12 drop=2 speed=10.2x"))
+	if !reflect.DeepEqual(e, g) {
+		t.Errorf("expected %+v, got %+v", e, g)
+	}
+}
+
+type mockStdErrParser struct {
+	bs     []string
+	period time.Duration
+	ts     []int
+}
+
+func (p *mockStdErrParser) Period() time.Duration { return p.period }
+func (p *mockStdErrParser) Process(t time.Time, b *bytes.Buffer) {
+	// Reading consumes the buffer
+	p.bs = append(p.bs, b.String())
+	b.Next(b.Len())
+	p.ts = append(p.ts, int(t.Sub(time.Unix(0, 0))/time.Second))
+}
+
+func TestCompositeStdErrParser(t *testing.T) {
+	p1 := &mockStdErrParser{period: time.Second}
+	p2 := &mockStdErrParser{period: 2 * time.Second}
+	c := CompositeStdErrParser(p1, p2)
+	if e := time.Second; c.Period() != e {
+		t.Errorf("expected %s, got %s", e, c.Period())
+	}
+	for idx := 1; idx <= 5; idx++ {
+		c.Process(time.Unix(0, 0).Add(time.Duration(idx)*time.Second), bytes.NewBufferString("test"))
+	}
+	if e := []int{1, 2, 3, 4, 5}; !reflect.DeepEqual(e, p1.ts) {
+		t.Errorf("expected %+v, got %+v", e, p1.ts)
+	}
+	if e := []int{1, 3, 5}; !reflect.DeepEqual(e, p2.ts) {
+		t.Errorf("expected %+v, got %+v", e, p2.ts)
+	}
+	if e := []string{"test", "test", "test"}; !reflect.DeepEqual(e, p2.bs) {
+		t.Errorf("expected %+v, got %+v", e, p2.bs)
+	}
+}
+
+func TestDefaultStdErrParserFlush(t *testing.T) {
+	var g DefaultStdErrResults
+	p := DefaultStdErrParser(time.Second, func(r DefaultStdErrResults) { g = r })
+	b := bytes.NewBufferString("frame=  10 fps=0.0 q=28.0 size=       0kB time=00:00:00.40 bitrate=   0.0kbits/s speed=0.8x\rframe=  25 fps=0.0 q=-1.0 Lsize=      45kB time=00:00:01.00 bitrate= 368.6kbits/s speed=1.2x\nvideo:43kB audio:0kB\n")
+	p.Process(time.Now(), b)
+	if g.Frame != nil {
+		t.Errorf("expected no results, got %+v", g)
+	}
+	flushStdErrParser(p, time.Now(), b)
+	if g.Frame == nil || *g.Frame != 25 || g.Size == nil || *g.Size != 45*8*1000 || !g.Final {
+		t.Errorf("expected final results, got %+v", g)
+	}
 }
@@ -0,0 +1,135 @@
+package astiffmpeg
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/asticode/go-astikit"
+)
+
+// PCM formats
+const (
+	PCMFormatF32LE = "f32le"
+	PCMFormatS16LE = "s16le"
+	PCMFormatS32LE = "s32le"
+)
+
+// PCMOptions represents raw PCM audio options
+type PCMOptions struct {
+	// Defaults to 1
+	Channels int
+	// See PCMFormat constants, defaults to PCMFormatS16LE
+	Format string
+	// Defaults to 48000
+	SampleRate int
+}
+
+func (o PCMOptions) withDefaults() PCMOptions {
+	if o.Channels <= 0 {
+		o.Channels = 1
+	}
+	if len(o.Format) == 0 {
+		o.Format = PCMFormatS16LE
+	}
+	if o.SampleRate <= 0 {
+		o.SampleRate = 48000
+	}
+	return o
+}
+
+// DecodePCM decodes the first audio stream of the input to interleaved raw PCM written to w while it's decoded,
+// which enables integrating with Go DSP or speech recognition libraries
+func (f *FFMpeg) DecodePCM(ctx context.Context, g GlobalOptions, in Input, o PCMOptions, w io.Writer) (j *Job, err error) {
+	if j, err = f.ExecAsyncWithOptions(ctx, g, []Input{in}, pcmOutput(o), ExecOptions{Stdout: w}); err != nil {
+		err = fmt.Errorf("astiffmpeg: starting job failed: %w", err)
+		return
+	}
+	return
+}
+
+func pcmOutput(o PCMOptions) Output {
+	o = o.withDefaults()
+	return Output{
+		Options: &OutputOptions{
+			Encoding: &EncodingOptions{
+				AudioChannels:   astikit.IntPtr(o.Channels),
+				AudioSamplerate: astikit.IntPtr(o.SampleRate),
+				Codec:           []StreamOption{audioStreamOption("pcm_" + o.Format)},
+			},
+			Format:  o.Format,
+			Map:     &MapOptions{{Stream: &StreamSpecifier{Index: astikit.IntPtr(0), Type: StreamSpecifierTypeAudio}}},
+			NoVideo: true,
+		},
+		Path: "pipe:1",
+	}
+}
+
+// EncodePCM starts ffmpeg reading interleaved raw PCM from its stdin and encoding it into the output
+// PCM is written with the returned writer, which must be closed once everything has been written.
+func (f *FFMpeg) EncodePCM(ctx context.Context, g GlobalOptions, o PCMOptions, out Output) (w *PipeWriter, err error) {
+	if w, err = f.execPipeWriter(ctx, g, pcmInput(o), out); err != nil {
+		err = fmt.Errorf("astiffmpeg: executing with pipe writer failed: %w", err)
+		return
+	}
+	return
+}
+
+func pcmInput(o PCMOptions) Input {
+	o = o.withDefaults()
+	return Input{
+		Options: &InputOptions{
+			AudioChannels:   astikit.IntPtr(o.Channels),
+			Format:          o.Format,
+			AudioSamplerate: astikit.IntPtr(o.SampleRate),
+		},
+		Path: "pipe:0",
+	}
+}
+
+// PipeWriter writes to ffmpeg's stdin
+type PipeWriter struct {
+	j *Job
+	w *os.File
+}
+
+func (f *FFMpeg) execPipeWriter(ctx context.Context, g GlobalOptions, in Input, out Output) (w *PipeWriter, err error) {
+	// Create pipe
+	// An *os.File is used so that writes fail instead of blocking once ffmpeg has exited
+	var r *os.File
+	w = &PipeWriter{}
+	if r, w.w, err = os.Pipe(); err != nil {
+		err = fmt.Errorf("astiffmpeg: creating pipe failed: %w", err)
+		return
+	}
+	defer r.Close()
+
+	// Start job
+	if w.j, err = f.ExecAsyncWithOptions(ctx, g, []Input{in}, out, ExecOptions{Stdin: r}); err != nil {
+		w.w.Close()
+		err = fmt.Errorf("astiffmpeg: starting job failed: %w", err)
+		return
+	}
+	return
+}
+
+// Write writes to ffmpeg's stdin
+func (w *PipeWriter) Write(b []byte) (int, error) {
+	return w.w.Write(b)
+}
+
+// Job returns the underlying job
+func (w *PipeWriter) Job() *Job {
+	return w.j
+}
+
+// Close closes ffmpeg's stdin, which makes it finalize the output, and waits for it to exit
+func (w *PipeWriter) Close() (err error) {
+	if err = w.w.Close(); err != nil {
+		err = fmt.Errorf("astiffmpeg: closing pipe failed: %w", err)
+		return
+	}
+	err = w.j.Wait()
+	return
+}
@@ -0,0 +1,24 @@
+package astiffmpeg
+
+import (
+	"os/exec"
+	"reflect"
+	"testing"
+)
+
+func TestPCM(t *testing.T) {
+	cmd := exec.Command("ffmpeg")
+	if err := pcmInput(PCMOptions{Format: PCMFormatF32LE, SampleRate: 16000}).adaptCmd(cmd); err != nil {
+		t.Errorf("expected no error, got %s", err)
+	}
+	if err := pcmOutput(PCMOptions{Channels: 2}).adaptCmd(cmd); err != nil {
+		t.Errorf("expected no error, got %s", err)
+	}
+	e := []string{"ffmpeg",
+		"-ac", "1", "-ar", "16000", "-f", "f32le", "-i", "pipe:0",
+		"-map", "0:a:0", "-ac", "2", "-ar", "48000", "-codec:a", "pcm_s16le", "-vn", "-f", "s16le", "pipe:1",
+	}
+	if !reflect.DeepEqual(e, cmd.Args) {
+		t.Errorf("expected %+v, got %+v", e, cmd.Args)
+	}
+}
@@ -0,0 +1,72 @@
+package astiffmpeg
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// ConfigurationError is returned when the configuration doesn't allow running ffmpeg
+type ConfigurationError struct {
+	BinaryPath string
+	Err        error
+}
+
+// Error implements the error interface
+func (e ConfigurationError) Error() string {
+	return fmt.Sprintf("astiffmpeg: invalid binary path %q: %s", e.BinaryPath, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e ConfigurationError) Unwrap() error {
+	return e.Err
+}
+
+func checkBinaryPath(p string) error {
+	// Empty
+	if len(p) == 0 {
+		return ConfigurationError{Err: errors.New("binary path is empty")}
+	}
+
+	// Look up, which checks that it exists and is executable
+	if _, err := exec.LookPath(p); err != nil {
+		return ConfigurationError{BinaryPath: p, Err: err}
+	}
+	return nil
+}
+
+// Ping checks that the binary exists, is executable and is actually ffmpeg, which makes it suitable for health
+// checks
+// Configuration errors are returned as ConfigurationError.
+func (f *FFMpeg) Ping(ctx context.Context) (err error) {
+	// Check binary path
+	if err = checkBinaryPath(f.binaryPath); err != nil {
+		return
+	}
+
+	// Create cmd
+	var cmd = exec.CommandContext(ctx, f.binaryPath, "-version")
+	cmd.Env = os.Environ()
+	var bufErr = &bytes.Buffer{}
+	cmd.Stderr = bufErr
+
+	// Run cmd
+	var b []byte
+	if b, err = cmd.Output(); err != nil {
+		if ctx.Err() == nil {
+			err = ConfigurationError{BinaryPath: f.binaryPath, Err: fmt.Errorf("running -version failed with stderr %s: %w", bufErr.Bytes(), err)}
+		}
+		return
+	}
+
+	// Check output
+	if !strings.HasPrefix(string(b), "ffmpeg version") {
+		err = ConfigurationError{BinaryPath: f.binaryPath, Err: errors.New("binary is not ffmpeg")}
+		return
+	}
+	return
+}
@@ -0,0 +1,32 @@
+package astiffmpeg
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestPing(t *testing.T) {
+	for _, p := range []string{"", filepath.Join(t.TempDir(), "ffmpeg")} {
+		var e ConfigurationError
+		if err := New(Configuration{BinaryPath: p}).Ping(context.Background()); !errors.As(err, &e) {
+			t.Errorf("expected a ConfigurationError for %q, got %v", p, err)
+		}
+	}
+}
+
+func TestConfigurationError(t *testing.T) {
+	f := New(Configuration{BinaryPath: filepath.Join(t.TempDir(), "ffmpeg")})
+	for n, fn := range map[string]func() error{
+		"filters": func() error { _, err := f.Filters(context.Background()); return err },
+		"muxers":  func() error { _, err := f.Muxers(context.Background()); return err },
+		"probe":   func() error { _, err := f.Probe(context.Background(), Input{Path: "in.mp4"}); return err },
+		"streams": func() error { _, err := f.ProbeStreams(context.Background(), "in.mp4"); return err },
+	} {
+		var e ConfigurationError
+		if err := fn(); !errors.As(err, &e) {
+			t.Errorf("%s: expected a ConfigurationError, got %v", n, err)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package astiffmpeg
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// PiP corners
+const (
+	PiPCornerBottomLeft  = "bottom-left"
+	PiPCornerBottomRight = "bottom-right"
+	PiPCornerTopLeft     = "top-left"
+	PiPCornerTopRight    = "top-right"
+)
+
+// PiPOptions represents picture-in-picture options
+type PiPOptions struct {
+	BorderColor string // Defaults to white
+	BorderWidth int    // In pixels, 0 means no border
+	Corner      string // Defaults to PiPCornerBottomRight
+	// Encoding options of the output. Defaults to H.264/AAC.
+	Encoding *EncodingOptions
+	// Time after which the secondary video stops being displayed, 0 means until the end
+	End    time.Duration
+	Margin *int // Distance in pixels between the secondary video and the edges. Defaults to 20.
+	// Width of the secondary video relative to the main video's width. Defaults to 0.25.
+	Scale float64
+	// Time after which the secondary video starts being displayed
+	Start time.Duration
+}
+
+// PictureInPicture overlays the secondary input on top of the main input
+// Output audio is the main input's audio, if any
+func (f *FFMpeg) PictureInPicture(ctx context.Context, g GlobalOptions, main, secondary Input, o PiPOptions, outputPath string) (err error) {
+	// Create filters
+	var cfs []ComplexFilterOption
+	if cfs, err = pipFilters(o); err != nil {
+		err = fmt.Errorf("astiffmpeg: creating filters failed: %w", err)
+		return
+	}
+
+	// Create output options
+	if o.Encoding == nil {
+		o.Encoding = &EncodingOptions{
+			Codec: []StreamOption{
+				videoStreamOption(CodecLibx264),
+				audioStreamOption(CodecAAC),
+			},
+			PixelFormat: PixelFormatYUV420P,
+		}
+	}
+	e := copyEncodingOptions(o.Encoding)
+	e.ComplexFilters = cfs
+	oo := &OutputOptions{
+		Encoding: e,
+		Map: &MapOptions{
+			{Label: "v"},
+			{Optional: true, Stream: &StreamSpecifier{Type: StreamSpecifierTypeAudio}},
+		},
+	}
+
+	// Exec
+	if err = f.Exec(ctx, g, []Input{main, secondary}, Output{Options: oo, Path: outputPath}); err != nil {
+		err = fmt.Errorf("astiffmpeg: executing failed: %w", err)
+		return
+	}
+	return
+}
+
+func pipFilters(o PiPOptions) (cfs []ComplexFilterOption, err error) {
+	// Default options
+	if len(o.BorderColor) == 0 {
+		o.BorderColor = "white"
+	}
+	if len(o.Corner) == 0 {
+		o.Corner = PiPCornerBottomRight
+	}
+	m := "20"
+	if o.Margin != nil {
+		m = strconv.Itoa(*o.Margin)
+	}
+	if o.Scale <= 0 {
+		o.Scale = 0.25
+	}
+
+	// Get position
+	var x, y string
+	switch o.Corner {
+	case PiPCornerBottomLeft:
+		x, y = m, "H-h-"+m
+	case PiPCornerBottomRight:
+		x, y = "W-w-"+m, "H-h-"+m
+	case PiPCornerTopLeft:
+		x, y = m, m
+	case PiPCornerTopRight:
+		x, y = "W-w-"+m, m
+	default:
+		err = fmt.Errorf("astiffmpeg: invalid corner %s", o.Corner)
+		return
+	}
+
+	// Scale secondary video relatively to the main video
+	cfs = append(cfs, ComplexFilterOption{
+		Filters:       []string{"scale2ref=w=main_w*" + strconv.FormatFloat(o.Scale, 'f', -1, 64) + ":h=ow/dar"},
+		InputStreams:  []StreamSpecifier{{Name: "1:v"}, {Name: "0:v"}},
+		OutputStreams: []StreamSpecifier{{Name: "pip"}, {Name: "main"}},
+	})
+
+	// Add border
+	pip := StreamSpecifier{Name: "pip"}
+	if o.BorderWidth > 0 {
+		b := strconv.Itoa(o.BorderWidth)
+		pip = StreamSpecifier{Name: "pipb"}
+		cfs = append(cfs, ComplexFilterOption{
+			Filters: []string{"pad=" + Pad{
+				Color:  o.BorderColor,
+				Height: "ih+2*" + b,
+				Width:  "iw+2*" + b,
+				X:      b,
+				Y:      b,
+			}.string()},
+			InputStreams:  []StreamSpecifier{{Name: "pip"}},
+			OutputStreams: []StreamSpecifier{pip},
+		})
+	}
+
+	// Overlay
+	overlay := "overlay=x=" + x + ":y=" + y
+	if o.Start > 0 || o.End > 0 {
+		if o.End > 0 {
+			overlay += fmt.Sprintf(":enable='between(t,%s,%s)'", strconv.FormatFloat(o.Start.Seconds(), 'f', 3, 64), strconv.FormatFloat(o.End.Seconds(), 'f', 3, 64))
+		} else {
+			overlay += fmt.Sprintf(":enable='gte(t,%s)'", strconv.FormatFloat(o.Start.Seconds(), 'f', 3, 64))
+		}
+	}
+	cfs = append(cfs, ComplexFilterOption{
+		Filters:       []string{overlay},
+		InputStreams:  []StreamSpecifier{{Name: "main"}, pip},
+		OutputStreams: []StreamSpecifier{{Name: "v"}},
+	})
+	return
+}
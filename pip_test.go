@@ -0,0 +1,32 @@
+package astiffmpeg
+
+import (
+	"os/exec"
+	"testing"
+	"time"
+)
+
+func TestPiPFilters(t *testing.T) {
+	if _, err := pipFilters(PiPOptions{Corner: "invalid"}); err == nil {
+		t.Error("expected error")
+	}
+	cfs, err := pipFilters(PiPOptions{
+		BorderWidth: 4,
+		Corner:      PiPCornerTopLeft,
+		End:         5 * time.Second,
+		Start:       2 * time.Second,
+	})
+	if err != nil {
+		t.Errorf("expected no error, got %s", err.Error())
+	}
+	cmd := exec.Command("ffmpeg")
+	if err = (EncodingOptions{ComplexFilters: cfs}).adaptCmd(cmd); err != nil {
+		t.Errorf("expected no error, got %s", err.Error())
+	}
+	e := "[1:v][0:v]scale2ref=w=main_w*0.25:h=ow/dar[pip][main];" +
+		"[pip]pad=w=iw+2*4:h=ih+2*4:x=4:y=4:color=white[pipb];" +
+		"[main][pipb]overlay=x=20:y=20:enable='between(t,2.000,5.000)'[v]"
+	if len(cmd.Args) != 3 || cmd.Args[2] != e {
+		t.Errorf("expected %s, got %+v", e, cmd.Args)
+	}
+}
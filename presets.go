@@ -0,0 +1,86 @@
+package astiffmpeg
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/asticode/go-astikit"
+)
+
+// StreamingGOP sets -g, -keyint_min, -sc_threshold and -force_key_frames so that a keyframe is placed at the
+// beginning of every segment of the specified duration, which is required by segmented outputs such as HLS or DASH
+func (o *EncodingOptions) StreamingGOP(segmentDuration time.Duration, framerate float64) error {
+	// Check input
+	if segmentDuration <= 0 {
+		return errors.New("astiffmpeg: segment duration should be > 0")
+	}
+	if framerate <= 0 {
+		return errors.New("astiffmpeg: framerate should be > 0")
+	}
+
+	// Compute GOP size
+	gop := int(math.Round(segmentDuration.Seconds() * framerate))
+	if gop < 1 {
+		return errors.New("astiffmpeg: segment duration is shorter than a frame")
+	}
+
+	// Update options
+	o.GOP = astikit.IntPtr(gop)
+	o.KeyintMin = astikit.IntPtr(gop)
+	o.SCThreshold = astikit.IntPtr(0)
+	o.ForceKeyFrames = "expr:gte(t,n_forced*" + strconv.FormatFloat(segmentDuration.Seconds(), 'f', -1, 64) + ")"
+	return nil
+}
+
+// CBR creates encoding options producing a true constant bitrate video stream with the specified codec
+// Supported codecs are CodecLibx264, CodecLibx265, CodecH264NVENC and CodecHEVCNVENC
+func CBR(codec string, bitrate Number) (o EncodingOptions, err error) {
+	// Common options
+	o.Codec = []StreamOption{videoStreamOption(codec)}
+	o.Bitrate = []StreamOption{videoStreamOption(bitrate)}
+	o.Maxrate = []StreamOption{videoStreamOption(bitrate)}
+	o.BufSize = &Number{Value: bitrate.float64()}
+
+	// Codec specific options
+	switch codec {
+	case CodecLibx264:
+		o.Minrate = []StreamOption{videoStreamOption(bitrate)}
+		o.NALHRD = RateControlCBR
+	case CodecLibx265:
+		o.Minrate = []StreamOption{videoStreamOption(bitrate)}
+		o.X265Params = map[string]string{"hrd": "1", "strict-cbr": "1"}
+	case CodecH264NVENC, CodecHEVCNVENC:
+		o.RateControl = RateControlCBR
+	default:
+		err = fmt.Errorf("astiffmpeg: codec %s is not supported", codec)
+		return
+	}
+	return
+}
+
+// CappedVBR creates encoding options producing a constant quality video stream with the specified codec whose
+// bitrate never exceeds maxrate
+// Supported codecs are CodecLibx264, CodecLibx265, CodecH264NVENC and CodecHEVCNVENC
+func CappedVBR(codec string, quality int, maxrate Number) (o EncodingOptions, err error) {
+	// Common options
+	o.Codec = []StreamOption{videoStreamOption(codec)}
+	o.Maxrate = []StreamOption{videoStreamOption(maxrate)}
+	o.BufSize = &Number{Value: 2 * maxrate.float64()}
+
+	// Codec specific options
+	switch codec {
+	case CodecLibx264, CodecLibx265:
+		o.CRF = astikit.IntPtr(quality)
+	case CodecH264NVENC, CodecHEVCNVENC:
+		o.Bitrate = []StreamOption{videoStreamOption(Number{Value: 0})}
+		o.ConstantQuality = astikit.Float64Ptr(float64(quality))
+		o.RateControl = RateControlVBR
+	default:
+		err = fmt.Errorf("astiffmpeg: codec %s is not supported", codec)
+		return
+	}
+	return
+}
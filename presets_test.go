@@ -0,0 +1,47 @@
+package astiffmpeg
+
+import (
+	"os/exec"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/asticode/go-astikit"
+)
+
+func TestStreamingGOP(t *testing.T) {
+	o := &EncodingOptions{}
+	if err := o.StreamingGOP(0, 25); err == nil {
+		t.Error("expected error")
+	}
+	if err := o.StreamingGOP(2*time.Second, 29.97); err != nil {
+		t.Errorf("expected no error, got %s", err.Error())
+	}
+	e := &EncodingOptions{
+		ForceKeyFrames: "expr:gte(t,n_forced*2)",
+		GOP:            astikit.IntPtr(60),
+		KeyintMin:      astikit.IntPtr(60),
+		SCThreshold:    astikit.IntPtr(0),
+	}
+	if !reflect.DeepEqual(e, o) {
+		t.Errorf("expected %+v, got %+v", e, o)
+	}
+}
+
+func TestCBR(t *testing.T) {
+	if _, err := CBR("invalid", Number{Value: 1}); err == nil {
+		t.Error("expected error")
+	}
+	o, err := CBR(CodecLibx264, Number{Prefix: "M", Value: 4.5})
+	if err != nil {
+		t.Errorf("expected no error, got %s", err.Error())
+	}
+	cmd := exec.Command("ffmpeg")
+	if err = o.adaptCmd(cmd); err != nil {
+		t.Errorf("expected no error, got %s", err.Error())
+	}
+	e := []string{"ffmpeg", "-b:v", "4.5M", "-bufsize", "4500000", "-codec:v", "libx264", "-maxrate:v", "4.5M", "-minrate:v", "4.5M", "-nal-hrd", "cbr"}
+	if !reflect.DeepEqual(e, cmd.Args) {
+		t.Errorf("expected %+v, got %+v", e, cmd.Args)
+	}
+}
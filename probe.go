@@ -0,0 +1,288 @@
+package astiffmpeg
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/asticode/go-astikit"
+)
+
+// ErrInputNotProbable is returned when probing an input that is read from a reader, since probing would consume it
+var ErrInputNotProbable = errors.New("astiffmpeg: input with a reader can't be probed")
+
+func (i Input) checkProbable() error {
+	if i.Reader != nil {
+		return ErrInputNotProbable
+	}
+	return nil
+}
+
+// InputInfo represents information about an input, as printed by ffmpeg in its stderr banner
+type InputInfo struct {
+	Bitrate   *int   // bits/s
+	Container string // e.g. "mov,mp4,m4a,3gp,3g2,mj2"
+	Duration  *time.Duration
+	Index     int
+	Path      string
+	Streams   []StreamInfo
+}
+
+// Stream info types
+const (
+	StreamInfoTypeAudio    = "Audio"
+	StreamInfoTypeData     = "Data"
+	StreamInfoTypeSubtitle = "Subtitle"
+	StreamInfoTypeVideo    = "Video"
+)
+
+// StreamInfo represents information about an input stream
+type StreamInfo struct {
+	Bitrate       *int // bits/s
+	ChannelLayout string
+	Channels      *int
+	Codec         string
+	FPS           *float64
+	Height        *int
+	Index         int
+	Language      string
+	PixelFormat   string
+	Profile       string
+	SampleRate    *int
+	Type          string
+	Width         *int
+}
+
+var (
+	bannerDurationRegexp = regexp.MustCompile(`^\s+Duration: ([^,]+)(?:, start: [^,]+)?(?:, bitrate: (\d+ kb/s|N/A))?`)
+	bannerInputRegexp    = regexp.MustCompile(`^Input #(\d+), (.+), from '(.*)':`)
+	bannerStreamRegexp   = regexp.MustCompile(`^\s+Stream #\d+:(\d+)(?:\[\w+\])?(?:\((\w+)\))?: (\w+): (.+)$`)
+)
+
+// ParseInputInfos parses information about inputs from ffmpeg's stderr banner
+// Even when ffprobe is not available, ffmpeg prints "Input #0" and "Stream #0:0" lines to stderr
+func ParseInputInfos(b []byte) (is []InputInfo) {
+	var i *InputInfo
+	for _, l := range strings.Split(string(b), "\n") {
+		l = strings.TrimRight(l, "\r")
+
+		// New input
+		if ms := bannerInputRegexp.FindStringSubmatch(l); len(ms) > 0 {
+			if i != nil {
+				is = append(is, *i)
+			}
+			i = &InputInfo{Container: ms[2], Path: ms[3]}
+			i.Index, _ = strconv.Atoi(ms[1])
+			continue
+		}
+
+		// Not in an input section
+		if i == nil {
+			continue
+		}
+
+		// End of inputs
+		if !strings.HasPrefix(l, " ") {
+			is = append(is, *i)
+			i = nil
+			continue
+		}
+
+		// Duration
+		if ms := bannerDurationRegexp.FindStringSubmatch(l); len(ms) > 0 {
+			if ms[1] != "N/A" {
+				i.Duration = astikit.DurationPtr(durationFromString(ms[1]))
+			}
+			if v, err := strconv.Atoi(strings.TrimSuffix(ms[2], " kb/s")); err == nil {
+				i.Bitrate = astikit.IntPtr(v * 1000)
+			}
+			continue
+		}
+
+		// Stream
+		if ms := bannerStreamRegexp.FindStringSubmatch(l); len(ms) > 0 {
+			i.Streams = append(i.Streams, parseStreamInfo(ms[1], ms[2], ms[3], ms[4]))
+		}
+	}
+	if i != nil {
+		is = append(is, *i)
+	}
+	return
+}
+
+var channelsByLayout = map[string]int{
+	"mono":   1,
+	"stereo": 2,
+	"2.1":    3,
+	"quad":   4,
+	"4.0":    4,
+	"5.0":    5,
+	"5.1":    6,
+	"6.1":    7,
+	"7.1":    8,
+}
+
+// h264 (High) (avc1 / 0x31637661), yuv420p(progressive), 1920x1080 [SAR 1:1 DAR 16:9], 1000 kb/s, 25 fps, 25 tbr
+// aac (LC) (mp4a / 0x6134706D), 48000 Hz, stereo, fltp, 128 kb/s (default)
+func parseStreamInfo(index, language, typ, details string) (s StreamInfo) {
+	// Create stream
+	s = StreamInfo{
+		Language: language,
+		Type:     typ,
+	}
+	s.Index, _ = strconv.Atoi(index)
+
+	// Loop through details
+	for idx, d := range splitOutsideParentheses(details) {
+		// Codec
+		if idx == 0 {
+			if fs := strings.Fields(d); len(fs) > 0 {
+				s.Codec = fs[0]
+			}
+
+			// First parentheses contain either the profile or the codec tag (e.g. "avc1 / 0x31637661")
+			if start, end := strings.Index(d, "("), strings.Index(d, ")"); start > -1 && end > start {
+				if p := d[start+1 : end]; !strings.Contains(p, " / ") {
+					s.Profile = p
+				}
+			}
+			continue
+		}
+
+		// Get fields
+		fs := strings.Fields(d)
+		if len(fs) == 0 {
+			continue
+		}
+		var unit string
+		if len(fs) > 1 {
+			unit = fs[1]
+		}
+
+		// Parse detail
+		switch {
+		case unit == "kb/s":
+			if v, err := strconv.Atoi(fs[0]); err == nil {
+				s.Bitrate = astikit.IntPtr(v * 1000)
+			}
+		case unit == "fps":
+			if v, err := strconv.ParseFloat(fs[0], 64); err == nil {
+				s.FPS = astikit.Float64Ptr(v)
+			}
+		case unit == "Hz":
+			if v, err := strconv.Atoi(fs[0]); err == nil {
+				s.SampleRate = astikit.IntPtr(v)
+			}
+		case typ == StreamInfoTypeVideo && idx == 1:
+			s.PixelFormat = strings.Split(fs[0], "(")[0]
+		case typ == StreamInfoTypeVideo && s.Width == nil && strings.Contains(fs[0], "x"):
+			ps := strings.Split(fs[0], "x")
+			w, errW := strconv.Atoi(ps[0])
+			h, errH := strconv.Atoi(ps[1])
+			if errW == nil && errH == nil {
+				s.Height = astikit.IntPtr(h)
+				s.Width = astikit.IntPtr(w)
+			}
+		case typ == StreamInfoTypeAudio && s.SampleRate != nil && len(s.ChannelLayout) == 0:
+			s.ChannelLayout = strings.TrimSpace(strings.Split(d, "(")[0])
+			if v, ok := channelsByLayout[s.ChannelLayout]; ok {
+				s.Channels = astikit.IntPtr(v)
+			} else if ps := strings.Split(s.ChannelLayout, " "); len(ps) == 2 && ps[1] == "channels" {
+				if v, err := strconv.Atoi(ps[0]); err == nil {
+					s.Channels = astikit.IntPtr(v)
+				}
+			}
+		}
+	}
+	return
+}
+
+func splitOutsideParentheses(i string) (o []string) {
+	var depth, start int
+	for idx := 0; idx < len(i); idx++ {
+		switch i[idx] {
+		case '(', '[':
+			depth++
+		case ')', ']':
+			depth--
+		case ',':
+			if depth == 0 {
+				o = append(o, strings.TrimSpace(i[start:idx]))
+				start = idx + 1
+			}
+		}
+	}
+	o = append(o, strings.TrimSpace(i[start:]))
+	return
+}
+
+// Probe retrieves information about the specified input using ffmpeg only
+// It reads the stderr banner printed by "ffmpeg -i <input>" which means it doesn't require ffprobe
+func (f *FFMpeg) Probe(ctx context.Context, in Input) (i InputInfo, err error) {
+	// Check input
+	if err = in.checkProbable(); err != nil {
+		return
+	}
+
+	// Check binary path
+	if err = checkBinaryPath(f.binaryPath); err != nil {
+		return
+	}
+
+	// Create cmd
+	var cmd = exec.CommandContext(ctx, f.binaryPath, "-hide_banner")
+	cmd.Env = os.Environ()
+	var bufErr = &bytes.Buffer{}
+	cmd.Stderr = bufErr
+
+	// Input
+	if err = in.adaptCmd(cmd); err != nil {
+		err = fmt.Errorf("astiffmpeg: adapting cmd for input failed: %w", err)
+		return
+	}
+
+	// Run cmd
+	// ffmpeg always exits with an error since no output is provided
+	if err = cmd.Run(); err != nil {
+		var errExit *exec.ExitError
+		if !errors.As(err, &errExit) {
+			err = fmt.Errorf("astiffmpeg: running %s failed: %w", cmd.String(), err)
+			return
+		}
+		err = nil
+	}
+
+	// Parse
+	is := ParseInputInfos(bufErr.Bytes())
+	if len(is) == 0 {
+		err = fmt.Errorf("astiffmpeg: no input info found in stderr %s", bufErr.Bytes())
+		return
+	}
+	i = is[0]
+	return
+}
+
+// Duration probes the duration of the specified input using ffmpeg only
+func (f *FFMpeg) Duration(ctx context.Context, in Input) (d time.Duration, err error) {
+	// Probe
+	var i InputInfo
+	if i, err = f.Probe(ctx, in); err != nil {
+		err = fmt.Errorf("astiffmpeg: probing failed: %w", err)
+		return
+	}
+
+	// No duration
+	if i.Duration == nil {
+		err = errors.New("astiffmpeg: no duration found")
+		return
+	}
+	d = *i.Duration
+	return
+}
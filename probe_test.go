@@ -0,0 +1,115 @@
+package astiffmpeg
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/asticode/go-astikit"
+)
+
+func TestParseInputInfos(t *testing.T) {
+	is := ParseInputInfos([]byte(`Input #0, mov,mp4,m4a,3gp,3g2,mj2, from 'in.mp4':
+  Metadata:
+    major_brand     : isom
+  Duration: 00:00:10.50, start: 0.000000, bitrate: 1234 kb/s
+  Stream #0:0[0x1](und): Video: h264 (High) (avc1 / 0x31637661), yuv420p(tv, bt709, progressive), 1920x1080 [SAR 1:1 DAR 16:9], 1000 kb/s, 29.97 fps, 29.97 tbr, 30k tbn (default)
+    Metadata:
+      handler_name    : VideoHandler
+  Stream #0:1[0x2](eng): Audio: aac (LC) (mp4a / 0x6134706D), 48000 Hz, stereo, fltp, 128 kb/s (default)
+Input #1, wav, from 'in.wav':
+  Duration: N/A, bitrate: 1536 kb/s
+  Stream #1:0: Audio: pcm_s16le ([1][0][0][0] / 0x0001), 48000 Hz, 6 channels, s16, 4608 kb/s
+Input #2, lavfi, from 'testsrc=duration=5':
+  Duration: 00:00:05.00, start: 0.000000, bitrate: N/A
+  Stream #2:0: Video: wrapped_avframe, rgb24, 320x240 [SAR 1:1 DAR 4:3], 25 fps, 25 tbr, 25 tbn
+Stream mapping:
+  Stream #0:0 -> #0:0 (h264 (native) -> h264 (libx264))
+Output #0, mp4, to 'out.mp4':
+  Stream #0:0: Video: h264, yuv420p, 1920x1080, q=2-31
+`))
+	e := []InputInfo{
+		{
+			Bitrate:   astikit.IntPtr(1234000),
+			Container: "mov,mp4,m4a,3gp,3g2,mj2",
+			Duration:  astikit.DurationPtr(10*time.Second + 500*time.Millisecond),
+			Path:      "in.mp4",
+			Streams: []StreamInfo{
+				{
+					Bitrate:     astikit.IntPtr(1000000),
+					Codec:       "h264",
+					FPS:         astikit.Float64Ptr(29.97),
+					Height:      astikit.IntPtr(1080),
+					Language:    "und",
+					PixelFormat: "yuv420p",
+					Profile:     "High",
+					Type:        StreamInfoTypeVideo,
+					Width:       astikit.IntPtr(1920),
+				},
+				{
+					Bitrate:       astikit.IntPtr(128000),
+					ChannelLayout: "stereo",
+					Channels:      astikit.IntPtr(2),
+					Codec:         "aac",
+					Index:         1,
+					Language:      "eng",
+					Profile:       "LC",
+					SampleRate:    astikit.IntPtr(48000),
+					Type:          StreamInfoTypeAudio,
+				},
+			},
+		},
+		{
+			Bitrate:   astikit.IntPtr(1536000),
+			Container: "wav",
+			Index:     1,
+			Path:      "in.wav",
+			Streams: []StreamInfo{
+				{
+					Bitrate:       astikit.IntPtr(4608000),
+					ChannelLayout: "6 channels",
+					Channels:      astikit.IntPtr(6),
+					Codec:         "pcm_s16le",
+					SampleRate:    astikit.IntPtr(48000),
+					Type:          StreamInfoTypeAudio,
+				},
+			},
+		},
+		{
+			Container: "lavfi",
+			Duration:  astikit.DurationPtr(5 * time.Second),
+			Index:     2,
+			Path:      "testsrc=duration=5",
+			Streams: []StreamInfo{
+				{
+					Codec:       "wrapped_avframe",
+					FPS:         astikit.Float64Ptr(25),
+					Height:      astikit.IntPtr(240),
+					PixelFormat: "rgb24",
+					Type:        StreamInfoTypeVideo,
+					Width:       astikit.IntPtr(320),
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(e, is) {
+		t.Errorf("expected %+v, got %+v", e, is)
+	}
+}
+
+func TestProbeReader(t *testing.T) {
+	f := New(Configuration{BinaryPath: "ffmpeg"})
+	in := Input{Reader: strings.NewReader("")}
+	if _, err := f.Probe(context.Background(), in); !errors.Is(err, ErrInputNotProbable) {
+		t.Errorf("expected %s, got %v", ErrInputNotProbable, err)
+	}
+	if _, err := f.Duration(context.Background(), in); !errors.Is(err, ErrInputNotProbable) {
+		t.Errorf("expected %s, got %v", ErrInputNotProbable, err)
+	}
+	if _, err := f.FrameRate(context.Background(), in); !errors.Is(err, ErrInputNotProbable) {
+		t.Errorf("expected %s, got %v", ErrInputNotProbable, err)
+	}
+}
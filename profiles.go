@@ -0,0 +1,135 @@
+package astiffmpeg
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/asticode/go-astikit"
+)
+
+// Output profile names
+const (
+	OutputProfileNameArchiveHEVC        = "archive-hevc"
+	OutputProfileNameLiveLowLatency     = "live-low-latency"
+	OutputProfileNamePodcastAAC         = "podcast-aac"
+	OutputProfileNameSocialSquare1x1    = "social-square-1x1"
+	OutputProfileNameSocialVertical9x16 = "social-vertical-9x16"
+	OutputProfileNameSpeechWAV          = "speech-wav"
+	OutputProfileNameWeb1080pH264       = "web-1080p-h264"
+)
+
+var outputProfiles = map[string]func() OutputOptions{
+	OutputProfileNameArchiveHEVC:        OutputProfileArchiveHEVC,
+	OutputProfileNameLiveLowLatency:     OutputProfileLiveLowLatency,
+	OutputProfileNamePodcastAAC:         OutputProfilePodcastAAC,
+	OutputProfileNameSocialSquare1x1:    OutputProfileSocialSquare1x1,
+	OutputProfileNameSocialVertical9x16: OutputProfileSocialVertical9x16,
+	OutputProfileNameSpeechWAV:          OutputProfileSpeechWAV,
+	OutputProfileNameWeb1080pH264:       OutputProfileWeb1080pH264,
+}
+
+// OutputProfile returns the output options of the profile with the specified name
+// Returned options are a fresh copy that can be tweaked freely
+func OutputProfile(name string) (o OutputOptions, err error) {
+	fn, ok := outputProfiles[name]
+	if !ok {
+		err = fmt.Errorf("astiffmpeg: unknown output profile %s", name)
+		return
+	}
+	o = fn()
+	return
+}
+
+// OutputProfileWeb1080pH264 returns output options producing a progressive download friendly 1080p H.264/AAC mp4
+func OutputProfileWeb1080pH264() OutputOptions {
+	return OutputOptions{
+		Encoding: &EncodingOptions{
+			AudioChannels:   astikit.IntPtr(2),
+			AudioSamplerate: astikit.IntPtr(48000),
+			Bitrate:         []StreamOption{audioStreamOption(Number{Prefix: "k", Value: 128})},
+			BufSize:         &Number{Prefix: "M", Value: 12},
+			Codec: []StreamOption{
+				videoStreamOption(CodecLibx264),
+				audioStreamOption(CodecAAC),
+			},
+			CRF: astikit.IntPtr(23),
+			Filters: []StreamOption{videoStreamOption(FilterOptions{Scale: &Scale{
+				Height: astikit.IntPtr(1080),
+				Width:  astikit.IntPtr(-2),
+			}})},
+			Maxrate:     []StreamOption{videoStreamOption(Number{Prefix: "M", Value: 6})},
+			PixelFormat: PixelFormatYUV420P,
+			Preset:      PresetMedium,
+		},
+		Format: "mp4",
+		Muxing: &MuxingOptions{MovFlags: []string{MovFlagFaststart}},
+	}
+}
+
+// OutputProfileArchiveHEVC returns output options producing a high quality 10 bits HEVC/FLAC mkv
+func OutputProfileArchiveHEVC() OutputOptions {
+	return OutputOptions{
+		Encoding: &EncodingOptions{
+			Codec: []StreamOption{
+				videoStreamOption(CodecLibx265),
+				audioStreamOption(CodecFLAC),
+			},
+			CRF:         astikit.IntPtr(18),
+			PixelFormat: PixelFormatYUV420P10LE,
+			Preset:      PresetSlow,
+		},
+		Format: "matroska",
+	}
+}
+
+// OutputProfilePodcastAAC returns output options producing an audio only AAC m4a
+func OutputProfilePodcastAAC() OutputOptions {
+	return OutputOptions{
+		Encoding: &EncodingOptions{
+			AudioChannels:   astikit.IntPtr(2),
+			AudioSamplerate: astikit.IntPtr(44100),
+			Bitrate:         []StreamOption{audioStreamOption(Number{Prefix: "k", Value: 128})},
+			Codec:           []StreamOption{audioStreamOption(CodecAAC)},
+		},
+		Format:  "ipod",
+		Muxing:  &MuxingOptions{MovFlags: []string{MovFlagFaststart}},
+		NoVideo: true,
+	}
+}
+
+// LiveLowLatencyInputOptions returns input options minimizing the startup and buffering latency of a live input
+// It should be used with OutputProfileLiveLowLatency.
+func LiveLowLatencyInputOptions() InputOptions {
+	return InputOptions{
+		AnalyzeDuration: astikit.DurationPtr(100 * time.Millisecond), // 0 would mean ffmpeg's default
+		Decoding:        &DecodingOptions{Flags: []string{CodecFlagLowDelay}},
+		FormatFlags:     []string{FormatFlagNoBuffer},
+		ProbeSize:       astikit.IntPtr(32),
+	}
+}
+
+// OutputProfileLiveLowLatency returns output options producing a sub-second latency H.264/AAC mpegts: no b-frames,
+// zerolatency tune, 1s GOP at 30fps and packets flushed as soon as they're muxed
+func OutputProfileLiveLowLatency() OutputOptions {
+	return OutputOptions{
+		Encoding: &EncodingOptions{
+			BFrames: astikit.IntPtr(0),
+			Bitrate: []StreamOption{audioStreamOption(Number{Prefix: "k", Value: 128})},
+			Codec: []StreamOption{
+				videoStreamOption(CodecLibx264),
+				audioStreamOption(CodecAAC),
+			},
+			Flags:       []string{CodecFlagLowDelay},
+			GOP:         astikit.IntPtr(30),
+			PixelFormat: PixelFormatYUV420P,
+			Preset:      PresetVeryfast,
+			SCThreshold: astikit.IntPtr(0),
+			Tune:        TuneZerolatency,
+		},
+		Format: "mpegts",
+		Muxing: &MuxingOptions{
+			FlushPackets: astikit.BoolPtr(true),
+			MuxDelay:     astikit.DurationPtr(0),
+		},
+	}
+}
@@ -0,0 +1,167 @@
+package astiffmpeg
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/asticode/go-astikit"
+)
+
+// Progress represents a progress block written by ffmpeg with -progress
+type Progress struct {
+	Bitrate    *float64 // kbits/s
+	DropFrames int
+	DupFrames  int
+	// Last block written once the job has ended (progress=end)
+	Ended     bool
+	FPS       *float64
+	Frame     *int
+	OutTime   *time.Duration
+	Speed     *float64
+	TotalSize *int64 // bytes
+}
+
+// ProgressHandler handles progress blocks
+type ProgressHandler func(p Progress)
+
+// progressReader reads progress written by ffmpeg either to a pipe, or to a local tcp connection on platforms where
+// pipes can't be inherited
+type progressReader struct {
+	done chan struct{}
+	l    net.Listener
+	r    *os.File
+	url  string
+	w    *os.File
+}
+
+func newProgressReader() (r *progressReader, err error) {
+	r = &progressReader{done: make(chan struct{})}
+	if extraFilesSupported {
+		if r.r, r.w, err = os.Pipe(); err != nil {
+			err = fmt.Errorf("astiffmpeg: creating pipe failed: %w", err)
+			return
+		}
+	} else {
+		if r.l, err = net.Listen("tcp", "127.0.0.1:0"); err != nil {
+			err = fmt.Errorf("astiffmpeg: listening failed: %w", err)
+			return
+		}
+		r.url = "tcp://" + r.l.Addr().String()
+	}
+	return
+}
+
+// adaptCmd adds the write end of the pipe to the extra files and returns the progress url
+func (r *progressReader) adaptCmd(cmd *exec.Cmd) string {
+	if r.w == nil {
+		return r.url
+	}
+	cmd.ExtraFiles = append(append([]*os.File{}, cmd.ExtraFiles...), r.w)
+	return ExtraFilePath(len(cmd.ExtraFiles) - 1)
+}
+
+// start starts reading progress once the cmd has started
+func (r *progressReader) start(fn ProgressHandler) {
+	// The write end of the pipe is only needed by ffmpeg
+	if r.w != nil {
+		r.w.Close()
+	}
+
+	go func() {
+		defer close(r.done)
+
+		// Get reader
+		var rd io.ReadCloser = r.r
+		if r.l != nil {
+			c, err := r.l.Accept()
+			if err != nil {
+				return
+			}
+			rd = c
+		}
+		defer rd.Close()
+
+		// Parse
+		parseProgress(rd, fn)
+	}()
+}
+
+// wait waits for progress to be fully read, once ffmpeg has exited
+func (r *progressReader) wait() {
+	if r.l != nil {
+		// ffmpeg may have exited before connecting
+		r.l.Close()
+	}
+	<-r.done
+}
+
+// close releases resources when the cmd couldn't be started
+func (r *progressReader) close() {
+	if r.l != nil {
+		r.l.Close()
+	}
+	if r.r != nil {
+		r.r.Close()
+	}
+	if r.w != nil {
+		r.w.Close()
+	}
+}
+
+// Blocks are key=value lines ending with "progress=continue" or "progress=end"
+func parseProgress(rd io.Reader, fn ProgressHandler) {
+	var p Progress
+	s := bufio.NewScanner(rd)
+	for s.Scan() {
+		// Get key and value
+		ps := strings.SplitN(strings.TrimSpace(s.Text()), "=", 2)
+		if len(ps) != 2 {
+			continue
+		}
+		k, v := ps[0], strings.TrimSpace(ps[1])
+
+		// Parse
+		switch k {
+		case "bitrate":
+			if f, err := strconv.ParseFloat(strings.TrimSuffix(v, "kbits/s"), 64); err == nil {
+				p.Bitrate = astikit.Float64Ptr(f)
+			}
+		case "drop_frames":
+			p.DropFrames, _ = strconv.Atoi(v)
+		case "dup_frames":
+			p.DupFrames, _ = strconv.Atoi(v)
+		case "fps":
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				p.FPS = astikit.Float64Ptr(f)
+			}
+		case "frame":
+			if i, err := strconv.Atoi(v); err == nil {
+				p.Frame = astikit.IntPtr(i)
+			}
+		case "out_time_us":
+			if i, err := strconv.ParseInt(v, 10, 64); err == nil {
+				p.OutTime = astikit.DurationPtr(time.Duration(i) * time.Microsecond)
+			}
+		case "speed":
+			if f, err := strconv.ParseFloat(strings.TrimSuffix(v, "x"), 64); err == nil {
+				p.Speed = astikit.Float64Ptr(f)
+			}
+		case "total_size":
+			if i, err := strconv.ParseInt(v, 10, 64); err == nil {
+				p.TotalSize = &i
+			}
+		case "progress":
+			// End of block
+			p.Ended = v == "end"
+			fn(p)
+			p = Progress{}
+		}
+	}
+}
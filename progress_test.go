@@ -0,0 +1,32 @@
+package astiffmpeg
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/asticode/go-astikit"
+)
+
+func TestParseProgress(t *testing.T) {
+	var ps []Progress
+	parseProgress(strings.NewReader("frame=50\nfps=25.00\nstream_0_0_q=28.0\nbitrate=1024.5kbits/s\ntotal_size=262144\n"+
+		"out_time_us=2000000\nout_time_ms=2000000\nout_time=00:00:02.000000\ndup_frames=1\ndrop_frames=0\nspeed=1.5x\n"+
+		"progress=continue\nframe=100\nbitrate=N/A\nspeed=N/A\nprogress=end\n"), func(p Progress) { ps = append(ps, p) })
+	e := []Progress{
+		{
+			Bitrate:   astikit.Float64Ptr(1024.5),
+			DupFrames: 1,
+			FPS:       astikit.Float64Ptr(25),
+			Frame:     astikit.IntPtr(50),
+			OutTime:   astikit.DurationPtr(2 * time.Second),
+			Speed:     astikit.Float64Ptr(1.5),
+			TotalSize: func(i int64) *int64 { return &i }(262144),
+		},
+		{Ended: true, Frame: astikit.IntPtr(100)},
+	}
+	if !reflect.DeepEqual(e, ps) {
+		t.Errorf("expected %+v, got %+v", e, ps)
+	}
+}
@@ -0,0 +1,28 @@
+package astiffmpeg
+
+import "strings"
+
+// Protocols wrapping other protocols
+const (
+	ProtocolAsync = "async"
+	ProtocolCache = "cache"
+)
+
+// CacheURL wraps the URL with the cache protocol, which stores what has been read in a temporary file so that
+// seeking backward within a remote input doesn't download it again from the start
+func CacheURL(url string) string {
+	return wrapURL(ProtocolCache, url)
+}
+
+// AsyncURL wraps the URL with the async protocol, which reads ahead in a separate thread so that decoding doesn't
+// stall on network reads. It can be combined with the cache protocol: AsyncURL(CacheURL(url)).
+func AsyncURL(url string) string {
+	return wrapURL(ProtocolAsync, url)
+}
+
+func wrapURL(protocol, url string) string {
+	if strings.HasPrefix(url, protocol+":") {
+		return url
+	}
+	return protocol + ":" + url
+}
@@ -0,0 +1,34 @@
+package astiffmpeg
+
+import (
+	"os/exec"
+	"reflect"
+	"testing"
+
+	"github.com/asticode/go-astikit"
+)
+
+func TestWrapURL(t *testing.T) {
+	for _, v := range []struct {
+		e string
+		g string
+	}{
+		{e: "cache:http://host/in.mp4", g: CacheURL("http://host/in.mp4")},
+		{e: "cache:http://host/in.mp4", g: CacheURL("cache:http://host/in.mp4")},
+		{e: "async:cache:http://host/in.mp4", g: AsyncURL(CacheURL("http://host/in.mp4"))},
+	} {
+		if v.e != v.g {
+			t.Errorf("expected %s, got %s", v.e, v.g)
+		}
+	}
+}
+
+func TestSeekable(t *testing.T) {
+	cmd := exec.Command("ffmpeg")
+	if err := (Input{Options: &InputOptions{Seekable: astikit.BoolPtr(true)}, Path: CacheURL("http://host/in.mp4")}).adaptCmd(cmd); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	if e := exec.Command("ffmpeg", "-seekable", "1", "-i", "cache:http://host/in.mp4").Args; !reflect.DeepEqual(e, cmd.Args) {
+		t.Errorf("expected %+v, got %+v", e, cmd.Args)
+	}
+}
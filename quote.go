@@ -0,0 +1,34 @@
+package astiffmpeg
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Args made only of these characters don't need to be quoted
+var safeArgRegexp = regexp.MustCompile(`^[a-zA-Z0-9@%+=:,./_-]+$`)
+
+// QuoteArg quotes the arg for POSIX shells, if needed
+// Single quotes are used so that nothing is interpreted, and single quotes inside the arg are closed, escaped and
+// reopened.
+func QuoteArg(arg string) string {
+	if safeArgRegexp.MatchString(arg) {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
+
+// QuoteArgs renders the args as a copy-pastable command for POSIX shells, which is useful for debugging
+// Unlike exec.Cmd.String, paths with spaces and filter graphs with quotes are quoted properly.
+func QuoteArgs(args []string) string {
+	var ss []string
+	for _, a := range args {
+		ss = append(ss, QuoteArg(a))
+	}
+	return strings.Join(ss, " ")
+}
+
+// String returns the command of the job as a copy-pastable command for POSIX shells
+func (j *Job) String() string {
+	return QuoteArgs(j.cmd.Args)
+}
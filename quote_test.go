@@ -0,0 +1,11 @@
+package astiffmpeg
+
+import "testing"
+
+func TestQuoteArgs(t *testing.T) {
+	if e, g := `ffmpeg -i 'my video.mp4' -filter:v 'drawtext=text='\''Hello'\''' -map 0:v:0 '' out-%03d.jpg`, QuoteArgs([]string{
+		"ffmpeg", "-i", "my video.mp4", "-filter:v", "drawtext=text='Hello'", "-map", "0:v:0", "", "out-%03d.jpg",
+	}); e != g {
+		t.Errorf("expected %s, got %s", e, g)
+	}
+}
@@ -0,0 +1,202 @@
+package astiffmpeg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/asticode/go-astikit"
+)
+
+// Frame represents a decoded video frame
+type Frame struct {
+	// Pixels are available in Image.Pix, Image.Stride being the number of bytes per row
+	Image *image.RGBA
+	Index int
+	// Only set when the frame rate is provided
+	Time *time.Duration
+}
+
+// DecodeFramesOptions represents decode frames options
+type DecodeFramesOptions struct {
+	// Frames are converted to this frame rate, frames are delivered as decoded by default
+	FPS *float64
+	// Frames are scaled to this size, which is probed from the first video stream of the input by default. Both
+	// should be set.
+	Height int
+	Width  int
+}
+
+// DecodeFrames decodes the first video stream of the input as rgba rawvideo through a pipe and delivers frames as
+// images over the returned channel, which enables Go side frame analysis without cgo
+// The channel is closed once ffmpeg has exited, the returned job can then be used to retrieve its error. Frames
+// must be read until the channel is closed, or the context cancelled, for ffmpeg to make progress.
+func (f *FFMpeg) DecodeFrames(ctx context.Context, g GlobalOptions, in Input, o DecodeFramesOptions) (<-chan Frame, *Job, error) {
+	// Get size
+	if o.Width <= 0 || o.Height <= 0 {
+		i, err := f.Probe(ctx, in)
+		if err != nil {
+			return nil, nil, fmt.Errorf("astiffmpeg: probing failed: %w", err)
+		}
+		for _, s := range i.Streams {
+			if s.Type == StreamInfoTypeVideo && s.Width != nil && s.Height != nil {
+				o.Height, o.Width = *s.Height, *s.Width
+				break
+			}
+		}
+		if o.Width <= 0 || o.Height <= 0 {
+			return nil, nil, errors.New("astiffmpeg: no video size found")
+		}
+	}
+
+	// Start job
+	// Stdout is read through a pipe so that cmd.Wait returns only once everything has been read
+	pr, pw := io.Pipe()
+	j, err := f.ExecAsyncWithOptions(ctx, g, []Input{in}, decodeFramesOutput(o), ExecOptions{Stdout: pw})
+	if err != nil {
+		return nil, nil, fmt.Errorf("astiffmpeg: starting job failed: %w", err)
+	}
+
+	// Close pipe once cmd has exited
+	go func() {
+		j.Wait()
+		pw.Close()
+	}()
+
+	// Read
+	ch := make(chan Frame)
+	go func() {
+		defer close(ch)
+		readRawFrames(pr, o, func(f Frame) {
+			select {
+			case ch <- f:
+			case <-ctx.Done():
+			}
+		})
+		// Make sure ffmpeg is not blocked writing to the pipe
+		io.Copy(io.Discard, pr)
+	}()
+	return ch, j, nil
+}
+
+func decodeFramesOutput(o DecodeFramesOptions) Output {
+	fs := []FilterOptions{{Scale: &Scale{Height: astikit.IntPtr(o.Height), Width: astikit.IntPtr(o.Width)}}}
+	if o.FPS != nil {
+		fs = append(fs, FilterOptions{Generic: []GenericFilter{fpsFilter(*o.FPS)}})
+	}
+	return Output{
+		Options: &OutputOptions{
+			Encoding: &EncodingOptions{
+				Codec:       []StreamOption{videoStreamOption("rawvideo")},
+				Filters:     []StreamOption{videoStreamOption(fs)},
+				PixelFormat: PixelFormatRGBA,
+			},
+			Format:  "rawvideo",
+			Map:     &MapOptions{{Stream: &StreamSpecifier{Index: astikit.IntPtr(0), Type: StreamSpecifierTypeVideo}}},
+			NoAudio: true,
+		},
+		Path: "pipe:1",
+	}
+}
+
+func fpsFilter(fps float64) GenericFilter {
+	return GenericFilter{
+		Name:    "fps",
+		Ordered: []KV{{Value: strconv.FormatFloat(fps, 'f', -1, 64)}},
+	}
+}
+
+func readRawFrames(r io.Reader, o DecodeFramesOptions, fn func(f Frame)) {
+	for idx := 0; ; idx++ {
+		// Read
+		i := image.NewRGBA(image.Rect(0, 0, o.Width, o.Height))
+		if _, err := io.ReadFull(r, i.Pix); err != nil {
+			return
+		}
+
+		// Create frame
+		f := Frame{
+			Image: i,
+			Index: idx,
+		}
+		if o.FPS != nil && *o.FPS > 0 {
+			f.Time = astikit.DurationPtr(time.Duration(float64(idx) / *o.FPS * float64(time.Second)))
+		}
+		fn(f)
+	}
+}
+
+// EncodeFramesOptions represents encode frames options
+type EncodeFramesOptions struct {
+	// Defaults to 25
+	FPS    float64
+	Height int
+	Width  int
+}
+
+// FrameWriter writes frames to ffmpeg's stdin
+type FrameWriter struct {
+	*PipeWriter
+	i *image.RGBA
+}
+
+// EncodeFrames starts ffmpeg reading rgba rawvideo frames of the declared size and rate from its stdin and encoding
+// them into the output, which lets Go programs render frames (e.g. charts or overlays) and assemble them into a video
+// Frames are written with the returned writer, which must be closed once all frames have been written.
+func (f *FFMpeg) EncodeFrames(ctx context.Context, g GlobalOptions, o EncodeFramesOptions, out Output) (w *FrameWriter, err error) {
+	// Check options
+	if o.Width <= 0 || o.Height <= 0 {
+		err = errors.New("astiffmpeg: width and height should be > 0")
+		return
+	}
+
+	// Default options
+	if o.FPS <= 0 {
+		o.FPS = 25
+	}
+
+	// Exec
+	w = &FrameWriter{i: image.NewRGBA(image.Rect(0, 0, o.Width, o.Height))}
+	if w.PipeWriter, err = f.execPipeWriter(ctx, g, encodeFramesInput(o), out); err != nil {
+		err = fmt.Errorf("astiffmpeg: executing with pipe writer failed: %w", err)
+		return
+	}
+	return
+}
+
+func encodeFramesInput(o EncodeFramesOptions) Input {
+	return Input{
+		Options: &InputOptions{
+			Format:      "rawvideo",
+			Framerate:   astikit.Float64Ptr(o.FPS),
+			PixelFormat: PixelFormatRGBA,
+			VideoSize:   strconv.Itoa(o.Width) + "x" + strconv.Itoa(o.Height),
+		},
+		Path: "pipe:0",
+	}
+}
+
+// WriteImage writes an image as a frame, the image being drawn over a transparent frame when its bounds don't match
+// the declared size
+func (w *FrameWriter) WriteImage(i image.Image) (err error) {
+	// Get pixels
+	pix := w.i.Pix
+	if r, ok := i.(*image.RGBA); ok && r.Rect == w.i.Rect && r.Stride == w.i.Stride {
+		pix = r.Pix
+	} else {
+		draw.Draw(w.i, w.i.Rect, image.Transparent, image.Point{}, draw.Src)
+		draw.Draw(w.i, w.i.Rect, i, i.Bounds().Min, draw.Src)
+	}
+
+	// Write
+	if _, err = w.w.Write(pix); err != nil {
+		err = fmt.Errorf("astiffmpeg: writing failed: %w", err)
+		return
+	}
+	return
+}
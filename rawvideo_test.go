@@ -0,0 +1,47 @@
+package astiffmpeg
+
+import (
+	"bytes"
+	"os/exec"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/asticode/go-astikit"
+)
+
+func TestDecodeFramesOutput(t *testing.T) {
+	cmd := exec.Command("ffmpeg")
+	if err := decodeFramesOutput(DecodeFramesOptions{FPS: astikit.Float64Ptr(2), Height: 2, Width: 4}).adaptCmd(cmd); err != nil {
+		t.Errorf("expected no error, got %s", err)
+	}
+	e := []string{"ffmpeg", "-map", "0:v:0", "-codec:v", "rawvideo", "-filter:v", "scale=h=2:w=4,fps=2", "-pix_fmt", "rgba", "-an", "-f", "rawvideo", "pipe:1"}
+	if !reflect.DeepEqual(e, cmd.Args) {
+		t.Errorf("expected %+v, got %+v", e, cmd.Args)
+	}
+}
+
+func TestReadRawFrames(t *testing.T) {
+	var fs []Frame
+	readRawFrames(bytes.NewReader(append(bytes.Repeat([]byte{1}, 8), bytes.Repeat([]byte{2}, 10)...)), DecodeFramesOptions{FPS: astikit.Float64Ptr(2), Height: 1, Width: 2}, func(f Frame) { fs = append(fs, f) })
+	if e, g := 2, len(fs); e != g {
+		t.Fatalf("expected %d, got %d", e, g)
+	}
+	if e, g := []byte{2, 2, 2, 2, 2, 2, 2, 2}, fs[1].Image.Pix; !bytes.Equal(e, g) {
+		t.Errorf("expected %+v, got %+v", e, g)
+	}
+	if e, g := 500*time.Millisecond, *fs[1].Time; e != g {
+		t.Errorf("expected %s, got %s", e, g)
+	}
+}
+
+func TestEncodeFramesInput(t *testing.T) {
+	cmd := exec.Command("ffmpeg")
+	if err := encodeFramesInput(EncodeFramesOptions{FPS: 30, Height: 720, Width: 1280}).adaptCmd(cmd); err != nil {
+		t.Errorf("expected no error, got %s", err)
+	}
+	e := []string{"ffmpeg", "-framerate", "30", "-pixel_format", "rgba", "-video_size", "1280x720", "-f", "rawvideo", "-i", "pipe:0"}
+	if !reflect.DeepEqual(e, cmd.Args) {
+		t.Errorf("expected %+v, got %+v", e, cmd.Args)
+	}
+}
@@ -0,0 +1,197 @@
+package astiffmpeg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/asticode/go-astikit"
+)
+
+// ContinuousRecordOptions represents continuous record options
+type ContinuousRecordOptions struct {
+	// Executed when ffmpeg exits with an error and is about to be restarted
+	OnRestart func(err error)
+	// Output options, streams are copied by default. Format and segment options are overwritten.
+	Options *OutputOptions
+	// strftime pattern of the segments path (e.g. "/records/cam-%Y%m%d-%H%M%S.mkv")
+	Pattern string
+	// Delay before restarting ffmpeg once it has exited. Defaults to 1s.
+	RestartDelay time.Duration
+	// Segments older than this value are deleted, 0 means segments are kept forever. Only files the pattern could
+	// have produced are deleted, and retention is disabled when the pattern contains other directives than %Y, %y,
+	// %m, %d, %j, %H, %M, %S, %s, %F, %T and %%.
+	Retention time.Duration
+	// Segments are cut on the wall clock at this interval. Defaults to 10m.
+	SegmentDuration time.Duration
+}
+
+// ContinuousRecord records the input into segments named after the wall clock using the segment muxer, deletes
+// segments older than the retention and restarts ffmpeg whenever it exits, which is aimed at NVR-style 24/7 camera
+// recording
+// It only returns once the context is done.
+func (f *FFMpeg) ContinuousRecord(ctx context.Context, g GlobalOptions, in Input, o ContinuousRecordOptions) (err error) {
+	// Check options
+	if len(o.Pattern) == 0 {
+		err = errors.New("astiffmpeg: no pattern provided")
+		return
+	}
+
+	// Default options
+	if o.RestartDelay <= 0 {
+		o.RestartDelay = time.Second
+	}
+	if o.SegmentDuration <= 0 {
+		o.SegmentDuration = 10 * time.Minute
+	}
+
+	// Apply retention
+	if o.Retention > 0 {
+		go func() {
+			t := time.NewTicker(retentionPeriod(o.SegmentDuration))
+			defer t.Stop()
+			for {
+				applyRetention(o.Pattern, o.Retention, time.Now())
+				select {
+				case <-t.C:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	// Loop
+	out := Output{
+		Options: continuousRecordOutputOptions(o),
+		Path:    o.Pattern,
+	}
+	for {
+		// Exec
+		err = f.Exec(ctx, g, []Input{in}, out)
+
+		// Context is done
+		if ctx.Err() != nil {
+			err = fmt.Errorf("astiffmpeg: context error: %w", ctx.Err())
+			return
+		}
+
+		// Restart
+		if err != nil && o.OnRestart != nil {
+			o.OnRestart(err)
+		}
+		select {
+		case <-time.After(o.RestartDelay):
+		case <-ctx.Done():
+			err = fmt.Errorf("astiffmpeg: context error: %w", ctx.Err())
+			return
+		}
+	}
+}
+
+func continuousRecordOutputOptions(o ContinuousRecordOptions) *OutputOptions {
+	// Copy options
+	oo := OutputOptions{Encoding: &EncodingOptions{Codec: []StreamOption{{Value: CodecCopy}}}}
+	if o.Options != nil {
+		oo = *o.Options
+	}
+
+	// Segment
+	m := MuxingOptions{}
+	if oo.Muxing != nil {
+		m = *oo.Muxing
+	}
+	m.Segment = &SegmentOptions{
+		AtClocktime:     true,
+		ResetTimestamps: astikit.BoolPtr(true),
+		Strftime:        true,
+		Time:            &o.SegmentDuration,
+	}
+	oo.Format = "segment"
+	oo.Muxing = &m
+	return &oo
+}
+
+// Retention is applied often enough for segments not to exceed it by much, but not so often that directories are
+// listed continuously
+func retentionPeriod(segmentDuration time.Duration) time.Duration {
+	if segmentDuration < time.Minute {
+		return segmentDuration
+	}
+	return time.Minute
+}
+
+var strftimeDirectiveRegexp = regexp.MustCompile(`%[a-zA-Z%]`)
+
+// Only directives whose output has a fixed format are supported, so that files that have not been written by the
+// recorder can't be mistaken for segments
+var strftimeDirectiveRegexps = map[string]string{
+	"%%": "%",
+	"%F": `\d{4}-\d{2}-\d{2}`,
+	"%H": `\d{2}`,
+	"%M": `\d{2}`,
+	"%S": `\d{2}`,
+	"%T": `\d{2}:\d{2}:\d{2}`,
+	"%Y": `\d{4}`,
+	"%d": `\d{2}`,
+	"%j": `\d{3}`,
+	"%m": `\d{2}`,
+	"%s": `\d+`,
+	"%y": `\d{2}`,
+}
+
+// strftimePathRegexp returns a regexp matching the paths the strftime pattern can produce
+func strftimePathRegexp(pattern string) (r *regexp.Regexp, err error) {
+	var s string
+	var last int
+	for _, idxs := range strftimeDirectiveRegexp.FindAllStringIndex(pattern, -1) {
+		d := pattern[idxs[0]:idxs[1]]
+		e, ok := strftimeDirectiveRegexps[d]
+		if !ok {
+			err = fmt.Errorf("astiffmpeg: strftime directive %s is not supported", d)
+			return
+		}
+		s += regexp.QuoteMeta(pattern[last:idxs[0]]) + e
+		last = idxs[1]
+	}
+	return regexp.Compile("^" + s + regexp.QuoteMeta(pattern[last:]) + "$")
+}
+
+// applyRetention deletes files produced by the strftime pattern whose last modification is older than the
+// retention. Nothing is deleted when the pattern contains directives that are not supported.
+func applyRetention(pattern string, retention time.Duration, now time.Time) (deleted []string) {
+	// Create regexp
+	pattern = filepath.Clean(pattern)
+	r, err := strftimePathRegexp(pattern)
+	if err != nil {
+		return
+	}
+
+	// Glob
+	ps, err := filepath.Glob(strftimeDirectiveRegexp.ReplaceAllString(pattern, "*"))
+	if err != nil {
+		return
+	}
+
+	// Loop through paths
+	for _, p := range ps {
+		// Path has not been produced by the pattern
+		if !r.MatchString(p) {
+			continue
+		}
+
+		// Delete
+		fi, err := os.Stat(p)
+		if err != nil || fi.IsDir() || now.Sub(fi.ModTime()) <= retention {
+			continue
+		}
+		if err = os.Remove(p); err == nil {
+			deleted = append(deleted, p)
+		}
+	}
+	return
+}
@@ -0,0 +1,54 @@
+package astiffmpeg
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestContinuousRecordOutputOptions(t *testing.T) {
+	cmd := exec.Command("ffmpeg")
+	if err := (Output{
+		Options: continuousRecordOutputOptions(ContinuousRecordOptions{SegmentDuration: time.Hour}),
+		Path:    "cam-%Y%m%d-%H%M%S.mkv",
+	}).adaptCmd(cmd); err != nil {
+		t.Errorf("expected no error, got %s", err)
+	}
+	e := []string{"ffmpeg", "-codec", "copy", "-segment_atclocktime", "1", "-reset_timestamps", "1", "-strftime", "1", "-segment_time", "3600.000", "-f", "segment", "cam-%Y%m%d-%H%M%S.mkv"}
+	if !reflect.DeepEqual(e, cmd.Args) {
+		t.Errorf("expected %+v, got %+v", e, cmd.Args)
+	}
+}
+
+func TestApplyRetention(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	for _, i := range []struct {
+		age  time.Duration
+		name string
+	}{
+		{age: 2 * time.Hour, name: "cam-20240101-100000.mkv"},
+		{age: time.Minute, name: "cam-20240101-115900.mkv"},
+		{age: 2 * time.Hour, name: "cam-backup-final.mkv"},
+		{age: 2 * time.Hour, name: "other.mkv"},
+	} {
+		p := filepath.Join(dir, i.name)
+		if err := os.WriteFile(p, []byte("test"), 0600); err != nil {
+			t.Fatalf("expected no error, got %s", err)
+		}
+		if err := os.Chtimes(p, now.Add(-i.age), now.Add(-i.age)); err != nil {
+			t.Fatalf("expected no error, got %s", err)
+		}
+	}
+	if e, g := []string{filepath.Join(dir, "cam-20240101-100000.mkv")}, applyRetention(filepath.Join(dir, "cam-%Y%m%d-%H%M%S.mkv"), time.Hour, now); !reflect.DeepEqual(e, g) {
+		t.Errorf("expected %+v, got %+v", e, g)
+	}
+
+	// Unsupported directive
+	if g := applyRetention(filepath.Join(dir, "cam-%A.mkv"), time.Hour, now); len(g) > 0 {
+		t.Errorf("expected nothing deleted, got %+v", g)
+	}
+}
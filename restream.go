@@ -0,0 +1,143 @@
+package astiffmpeg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/asticode/go-astikit"
+)
+
+// AudioRestreamOptions represents audio restream options
+type AudioRestreamOptions struct {
+	// Defaults to 128k
+	Bitrate *Number
+	// CodecAAC or CodecLibopus, defaults to CodecAAC
+	Codec string
+	// Output is HLS when set
+	HLS *HLSOptions
+	// Output is an icecast:// URL when set, content type is inferred from the codec
+	Icecast *IcecastOptions
+	// Executed when silence starts and ends
+	OnHealthEvent func(e HealthEvent)
+	// Duration after which silence is reported. Defaults to 10s.
+	SilenceDuration time.Duration
+	// Volume under which audio is considered silent, in dB. Defaults to -50.
+	SilenceThreshold float64
+}
+
+// isHTTPURL checks whether the URL uses the HTTP protocol, possibly wrapped in the cache or async protocols
+func isHTTPURL(u string) bool {
+	u = strings.ToLower(u)
+	for _, p := range []string{ProtocolAsync, ProtocolCache} {
+		u = strings.TrimPrefix(u, p+":")
+	}
+	return strings.HasPrefix(u, "http://") || strings.HasPrefix(u, "https://")
+}
+
+// RestreamAudio pulls an audio stream (e.g. HTTP, Icecast or RTMP), transcodes it and republishes it to HLS or
+// Icecast, which is what radio-style use cases need
+// HTTP inputs are reconnected when the connection is lost, and silence is reported through health events.
+func (f *FFMpeg) RestreamAudio(ctx context.Context, g GlobalOptions, in Input, o AudioRestreamOptions, outputPath string) (err error) {
+	// Create output options
+	var oo OutputOptions
+	if oo, err = audioRestreamOutputOptions(o); err != nil {
+		err = fmt.Errorf("astiffmpeg: creating output options failed: %w", err)
+		return
+	}
+
+	// Exec
+	var eo ExecOptions
+	if o.OnHealthEvent != nil {
+		eo.StdErrParser = NewSilenceMonitor(time.Second, o.OnHealthEvent)
+	}
+	if err = f.ExecWithOptions(ctx, g, []Input{audioRestreamInput(in)}, Output{
+		Options: &oo,
+		Path:    outputPath,
+	}, eo); err != nil {
+		err = fmt.Errorf("astiffmpeg: executing failed: %w", err)
+		return
+	}
+	return
+}
+
+func audioRestreamInput(i Input) Input {
+	// Reconnect options only exist for the HTTP protocol, other protocols would reject them
+	if !isHTTPURL(i.Path) {
+		return i
+	}
+
+	// Copy options
+	o := &InputOptions{}
+	if i.Options != nil {
+		*o = *i.Options
+	}
+	o.Reconnect = true
+	i.Options = o
+	return i
+}
+
+func audioRestreamOutputOptions(o AudioRestreamOptions) (oo OutputOptions, err error) {
+	// Default options
+	if o.Bitrate == nil {
+		o.Bitrate = &Number{Prefix: "k", Value: 128}
+	}
+	if len(o.Codec) == 0 {
+		o.Codec = CodecAAC
+	}
+	if o.SilenceDuration <= 0 {
+		o.SilenceDuration = 10 * time.Second
+	}
+	if o.SilenceThreshold == 0 {
+		o.SilenceThreshold = -50
+	}
+
+	// Create options
+	oo = OutputOptions{
+		Encoding: &EncodingOptions{
+			AudioSamplerate: astikit.IntPtr(48000),
+			Bitrate:         []StreamOption{audioStreamOption(*o.Bitrate)},
+			Codec:           []StreamOption{audioStreamOption(o.Codec)},
+			Filters:         []StreamOption{audioStreamOption(FilterOptions{Generic: []GenericFilter{SilenceDetectFilter(o.SilenceThreshold, o.SilenceDuration)}})},
+		},
+		Map:     &MapOptions{{Stream: &StreamSpecifier{Index: astikit.IntPtr(0), Type: StreamSpecifierTypeAudio}}},
+		NoVideo: true,
+	}
+
+	// Output specific options
+	switch {
+	case o.HLS != nil:
+		oo.Format = "hls"
+		oo.Muxing = &MuxingOptions{HLS: o.HLS}
+		if o.Codec == CodecLibopus {
+			// Opus is only supported in fMP4 segments
+			h := *o.HLS
+			h.SegmentType = HLSSegmentTypeFMP4
+			oo.Muxing.HLS = &h
+		}
+	case o.Icecast != nil:
+		i := *o.Icecast
+		switch o.Codec {
+		case CodecAAC:
+			oo.Format = "adts"
+			if len(i.ContentType) == 0 {
+				i.ContentType = "audio/aac"
+			}
+		case CodecLibopus:
+			oo.Format = "ogg"
+			if len(i.ContentType) == 0 {
+				i.ContentType = "application/ogg"
+			}
+		default:
+			err = fmt.Errorf("astiffmpeg: codec %s is not supported", o.Codec)
+			return
+		}
+		oo.Icecast = &i
+	default:
+		err = errors.New("astiffmpeg: no output provided")
+		return
+	}
+	return
+}
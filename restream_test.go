@@ -0,0 +1,48 @@
+package astiffmpeg
+
+import (
+	"os/exec"
+	"reflect"
+	"testing"
+)
+
+func TestAudioRestreamOutputOptions(t *testing.T) {
+	if _, err := audioRestreamOutputOptions(AudioRestreamOptions{}); err == nil {
+		t.Error("expected error")
+	}
+	o, err := audioRestreamOutputOptions(AudioRestreamOptions{
+		Codec:   CodecLibopus,
+		Icecast: &IcecastOptions{Name: "Radio"},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	cmd := exec.Command("ffmpeg")
+	if err = o.adaptCmd(cmd); err != nil {
+		t.Errorf("expected no error, got %s", err)
+	}
+	e := []string{"ffmpeg",
+		"-map", "0:a:0", "-ar", "48000", "-b:a", "128k", "-codec:a", "libopus", "-filter:a", "silencedetect=d=10.000:n=-50dB",
+		"-content_type", "application/ogg", "-ice_name", "Radio", "-vn", "-f", "ogg",
+	}
+	if !reflect.DeepEqual(e, cmd.Args) {
+		t.Errorf("expected %+v, got %+v", e, cmd.Args)
+	}
+}
+
+func TestAudioRestreamInput(t *testing.T) {
+	for _, v := range []struct {
+		p         string
+		reconnect bool
+	}{
+		{p: "http://host/radio.mp3", reconnect: true},
+		{p: "async:HTTPS://host/radio.mp3", reconnect: true},
+		{p: "rtmp://host/live/radio"},
+		{p: "radio.mp3"},
+	} {
+		i := audioRestreamInput(Input{Path: v.p})
+		if g := i.Options != nil && i.Options.Reconnect; g != v.reconnect {
+			t.Errorf("%s: expected %v, got %v", v.p, v.reconnect, g)
+		}
+	}
+}
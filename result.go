@@ -0,0 +1,146 @@
+package astiffmpeg
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Result represents the final statistics of a job
+type Result struct {
+	// Wall clock duration
+	Duration time.Duration
+	Frames   *int
+	// Files produced by each output, in the order outputs were provided, main output first
+	Outputs []OutputResult
+	// Files produced by all outputs
+	Paths []string
+	// Total size of the files produced by all outputs
+	Size  int64 // bytes
+	Speed *float64
+	// Duration of the encoded output
+	Time *time.Duration
+}
+
+// OutputResult represents the files produced by an output
+// Paths and Size are empty for outputs that are not files (e.g. URLs, pipes or sequence patterns).
+type OutputResult struct {
+	Path string
+	// Files produced, including HLS segments listed in the playlist
+	Paths []string
+	// Final size of the files produced
+	Size int64 // bytes
+}
+
+// ExecResult executes the binary with the specified options and returns the final statistics of the job
+func (f *FFMpeg) ExecResult(ctx context.Context, g GlobalOptions, in []Input, out Output) (r Result, err error) {
+	// Start job
+	var j *Job
+	if j, err = f.ExecAsync(ctx, g, in, out); err != nil {
+		return
+	}
+
+	// Wait
+	if err = j.Wait(); err != nil {
+		return
+	}
+	r = j.Result()
+	return
+}
+
+// Result returns the final statistics of the job
+// It blocks until the job has exited
+func (j *Job) Result() (r Result) {
+	// Wait
+	<-j.done
+
+	// Parse stderr
+	if s, ok := lastStatsLine(j.bufErr.Bytes()); ok {
+		sr := defaultStdErrParser{}.parseResults(s)
+		r.Frames = sr.Frame
+		r.Speed = sr.Speed
+		r.Time = sr.Time
+	}
+	r.Duration = j.endedAt.Sub(j.startedAt)
+
+	// Loop through outputs
+	for _, p := range j.outputPaths {
+		// Get paths
+		o := OutputResult{Path: p}
+		if isFileOutput(p) {
+			o.Paths = append([]string{p}, hlsSegmentPaths(p)...)
+		}
+
+		// Get size
+		for _, p := range o.Paths {
+			if fi, err := os.Stat(p); err == nil {
+				o.Size += fi.Size()
+			}
+		}
+
+		// Append
+		r.Outputs = append(r.Outputs, o)
+		r.Paths = append(r.Paths, o.Paths...)
+		r.Size += o.Size
+	}
+	return
+}
+
+// Stats lines are separated by \r and the last one is followed by a summary
+func lastStatsLine(b []byte) (l []byte, ok bool) {
+	ls := bytes.FieldsFunc(b, func(r rune) bool { return r == '\r' || r == '\n' })
+	for idx := len(ls) - 1; idx >= 0; idx-- {
+		if bytes.Contains(ls[idx], []byte("time=")) && bytes.Contains(ls[idx], []byte("speed=")) {
+			return ls[idx], true
+		}
+	}
+	return
+}
+
+// hlsSegmentPaths returns the local segments listed in the playlist, if the path is an HLS playlist
+func hlsSegmentPaths(p string) (ps []string) {
+	// Not a playlist
+	if !strings.EqualFold(filepath.Ext(p), ".m3u8") {
+		return
+	}
+
+	// Open
+	f, err := os.Open(p)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	// Loop through lines
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		// Get uri
+		var u string
+		l := strings.TrimSpace(s.Text())
+		if strings.HasPrefix(l, "#EXT-X-MAP:") {
+			// Init segment
+			if idx := strings.Index(l, `URI="`); idx > -1 {
+				if v := l[idx+5:]; strings.Contains(v, `"`) {
+					u = v[:strings.Index(v, `"`)]
+				}
+			}
+		} else if !strings.HasPrefix(l, "#") {
+			u = l
+		}
+
+		// Only local segments are handled, and query strings (e.g. hls_base_url with tokens) are not part of the
+		// path
+		if len(u) == 0 || strings.Contains(u, "://") {
+			continue
+		}
+		if idx := strings.IndexAny(u, "?#"); idx > -1 {
+			u = u[:idx]
+		}
+		ps = append(ps, filepath.Join(filepath.Dir(p), filepath.FromSlash(u)))
+	}
+	return
+}
@@ -0,0 +1,55 @@
+package astiffmpeg
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLastStatsLine(t *testing.T) {
+	l, ok := lastStatsLine([]byte("Output #0, mp4, to 'out.mp4':\nframe=   10 fps=0.0 q=28.0 size=       0kB time=00:00:00.20 bitrate=   0.0kbits/s speed=0.4x    \rframe=  250 fps=120 q=-1.0 Lsize=    1024kB time=00:00:10.00 bitrate= 838.9kbits/s speed=4.8x    \nvideo:1000kB audio:0kB subtitle:0kB other streams:0kB global headers:0kB muxing overhead: 0.5%\n"))
+	if !ok {
+		t.Fatal("expected a stats line")
+	}
+	if e := "frame=  250 fps=120 q=-1.0 Lsize=    1024kB time=00:00:10.00 bitrate= 838.9kbits/s speed=4.8x    "; string(l) != e {
+		t.Errorf("expected %s, got %s", e, l)
+	}
+}
+
+func TestJobResult(t *testing.T) {
+	d := t.TempDir()
+	p1, p2 := filepath.Join(d, "1.mp4"), filepath.Join(d, "2.mp4")
+	if err := os.WriteFile(p1, []byte("12"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p2, []byte("345"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	j := &Job{bufErr: &syncBuffer{}, done: make(chan struct{}), outputPaths: []string{p1, "-", p2}}
+	close(j.done)
+	r := j.Result()
+	e := []OutputResult{
+		{Path: p1, Paths: []string{p1}, Size: 2},
+		{Path: "-"},
+		{Path: p2, Paths: []string{p2}, Size: 3},
+	}
+	if !reflect.DeepEqual(e, r.Outputs) {
+		t.Errorf("expected %+v, got %+v", e, r.Outputs)
+	}
+	if r.Size != 5 {
+		t.Errorf("expected 5, got %d", r.Size)
+	}
+}
+
+func TestHLSSegmentPaths(t *testing.T) {
+	d := t.TempDir()
+	p := filepath.Join(d, "index.m3u8")
+	if err := os.WriteFile(p, []byte("#EXTM3U\n#EXT-X-VERSION:7\n#EXT-X-MAP:URI=\"init.mp4?v=1\"\n#EXTINF:4.000000,\nsegment-0.m4s\n#EXTINF:4.000000,\nsegment-1.m4s?token=abc\n#EXTINF:4.000000,\nhttps://cdn/segment-2.m4s\n#EXT-X-ENDLIST\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	e := []string{filepath.Join(d, "init.mp4"), filepath.Join(d, "segment-0.m4s"), filepath.Join(d, "segment-1.m4s")}
+	if g := hlsSegmentPaths(p); !reflect.DeepEqual(e, g) {
+		t.Errorf("expected %+v, got %+v", e, g)
+	}
+}
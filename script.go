@@ -0,0 +1,135 @@
+package astiffmpeg
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// scriptOptionName returns the name of the option reading the value of the specified option from a file, if any
+func scriptOptionName(name string) (string, bool) {
+	switch {
+	case name == "-filter_complex" || name == "-lavfi":
+		return "-filter_complex_script", true
+	case name == "-vf":
+		return "-filter_script:v", true
+	case name == "-af":
+		return "-filter_script:a", true
+	case name == "-filter" || strings.HasPrefix(name, "-filter:"):
+		return "-filter_script" + strings.TrimPrefix(name, "-filter"), true
+	}
+	return "", false
+}
+
+func argsExceedLimits(args []string, maxArgLength, maxArgsLength int) bool {
+	var total int
+	for _, a := range args {
+		if len(a) > maxArgLength {
+			return true
+		}
+		total += len(a) + 1
+	}
+	return total > maxArgsLength
+}
+
+// spillArgs writes filter graphs to temporary script files read with -filter_complex_script and -filter_script, and
+// concat protocol inputs (e.g. "concat:a.ts|b.ts") to temporary lists read with the concat demuxer, when args exceed
+// command line limits, and returns the paths of the temporary files, which should be removed once ffmpeg has exited
+// Script options are deprecated since ffmpeg 7.0 but still supported.
+func spillArgs(args []string, maxArgLength, maxArgsLength int) (o []string, paths []string, err error) {
+	// Args don't exceed limits
+	if !argsExceedLimits(args, maxArgLength, maxArgsLength) {
+		o = args
+		return
+	}
+
+	// Loop through args
+	for idx := 0; idx < len(args); idx++ {
+		// Concat protocol input
+		if args[idx] == "-i" && idx+1 < len(args) && strings.HasPrefix(args[idx+1], "concat:") {
+			// Create list
+			var p string
+			if p, err = writeTempFile("astiffmpeg-concat-*.txt", concatList(args[idx+1])); err != nil {
+				removeFiles(paths)
+				err = fmt.Errorf("astiffmpeg: writing concat list failed: %w", err)
+				return
+			}
+			paths = append(paths, p)
+
+			// Replace input
+			o = append(o, "-f", "concat", "-safe", "0", "-i", p)
+			idx++
+			continue
+		}
+
+		// Not a filter graph
+		n, ok := scriptOptionName(args[idx])
+		if !ok || idx+1 >= len(args) {
+			o = append(o, args[idx])
+			continue
+		}
+
+		// Create script
+		var p string
+		if p, err = writeTempFile("astiffmpeg-script-*.txt", args[idx+1]); err != nil {
+			removeFiles(paths)
+			err = fmt.Errorf("astiffmpeg: writing script failed: %w", err)
+			return
+		}
+		paths = append(paths, p)
+
+		// Replace option
+		o = append(o, n, p)
+		idx++
+	}
+
+	// Args still exceed limits
+	if argsExceedLimits(o, maxArgLength, maxArgsLength) {
+		removeFiles(paths)
+		err = errors.New("astiffmpeg: args exceed command line limits even once filter graphs and concat lists are written to files")
+		return
+	}
+	return
+}
+
+// concatList converts a concat protocol input to a list read by the concat demuxer
+// Relative paths are made absolute since the concat demuxer resolves them relative to the list.
+func concatList(i string) string {
+	var b strings.Builder
+	for _, p := range strings.Split(strings.TrimPrefix(i, "concat:"), "|") {
+		if !strings.Contains(p, "://") && !filepath.IsAbs(p) {
+			if a, err := filepath.Abs(p); err == nil {
+				p = a
+			}
+		}
+		b.WriteString("file '" + strings.ReplaceAll(p, "'", `'\''`) + "'\n")
+	}
+	return b.String()
+}
+
+func writeTempFile(pattern, content string) (path string, err error) {
+	// Create file
+	var f *os.File
+	if f, err = os.CreateTemp("", pattern); err != nil {
+		err = fmt.Errorf("astiffmpeg: creating temporary file failed: %w", err)
+		return
+	}
+	defer f.Close()
+	path = f.Name()
+
+	// Write
+	if _, err = f.WriteString(content); err != nil {
+		os.Remove(path)
+		err = fmt.Errorf("astiffmpeg: writing to %s failed: %w", path, err)
+		return
+	}
+	return
+}
+
+func removeFiles(paths []string) {
+	for _, p := range paths {
+		os.Remove(p)
+	}
+}
@@ -0,0 +1,76 @@
+package astiffmpeg
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSpillArgs(t *testing.T) {
+	// Limits are not exceeded
+	args := []string{"ffmpeg", "-i", "in.mp4", "-filter:v", "scale=640:-2", "out.mp4"}
+	o, paths, err := spillArgs(args, 1024, 1024)
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	if !reflect.DeepEqual(args, o) || len(paths) > 0 {
+		t.Errorf("expected %+v and no paths, got %+v and %+v", args, o, paths)
+	}
+
+	// Limits are exceeded
+	g := strings.Repeat("null,", 100) + "null"
+	o, paths, err = spillArgs([]string{"ffmpeg", "-i", "in.mp4", "-filter_complex", g, "-filter:v", "scale=640:-2", "out.mp4"}, 256, 1024)
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	defer removeFiles(paths)
+	if len(paths) != 2 {
+		t.Fatalf("expected 2 paths, got %d", len(paths))
+	}
+	if e := []string{"ffmpeg", "-i", "in.mp4", "-filter_complex_script", paths[0], "-filter_script:v", paths[1], "out.mp4"}; !reflect.DeepEqual(e, o) {
+		t.Errorf("expected %+v, got %+v", e, o)
+	}
+	for idx, e := range []string{g, "scale=640:-2"} {
+		b, err := os.ReadFile(paths[idx])
+		if err != nil {
+			t.Errorf("expected no error, got %s", err)
+		}
+		if string(b) != e {
+			t.Errorf("expected %s, got %s", e, b)
+		}
+	}
+}
+
+func TestSpillArgsConcat(t *testing.T) {
+	// Concat list
+	in := "concat:" + strings.Repeat("/tmp/part.ts|", 50) + "it's.ts"
+	o, paths, err := spillArgs([]string{"ffmpeg", "-f", "mpegts", "-i", in, "out.mp4"}, 256, 1024)
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	defer removeFiles(paths)
+	if len(paths) != 1 {
+		t.Fatalf("expected 1 path, got %d", len(paths))
+	}
+	if e := []string{"ffmpeg", "-f", "mpegts", "-f", "concat", "-safe", "0", "-i", paths[0], "out.mp4"}; !reflect.DeepEqual(e, o) {
+		t.Errorf("expected %+v, got %+v", e, o)
+	}
+	b, err := os.ReadFile(paths[0])
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	a, err := filepath.Abs("it's.ts")
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	if e := strings.Repeat("file '/tmp/part.ts'\n", 50) + "file '" + strings.ReplaceAll(a, "'", `'\''`) + "'\n"; string(b) != e {
+		t.Errorf("expected %s, got %s", e, b)
+	}
+
+	// Limits are still exceeded
+	if _, _, err = spillArgs([]string{"ffmpeg", "-i", "in.mp4", strings.Repeat("a", 512)}, 256, 1024); err == nil {
+		t.Error("expected error")
+	}
+}
@@ -0,0 +1,70 @@
+package astiffmpeg
+
+import (
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Segment list types
+const (
+	SegmentListTypeCSV  = "csv"
+	SegmentListTypeFlat = "flat"
+	SegmentListTypeM3U8 = "m3u8"
+)
+
+// SegmentOptions represents options of the segment muxer
+type SegmentOptions struct {
+	// Segments are cut at wall clock times multiple of Time (e.g. every hour on the hour)
+	AtClocktime bool
+	// Format of the segments, when it can't be inferred from the output path
+	Format string
+	// File listing the written segments
+	List string
+	// See SegmentListType constants
+	ListType string
+	// Timestamps of each segment start at 0
+	ResetTimestamps *bool
+	// Output path is a strftime pattern (e.g. "cam-%Y%m%d-%H%M%S.mkv") instead of a sequence pattern
+	Strftime bool
+	// Target segment duration. Segments are only cut on keyframes.
+	Time *time.Duration
+	// Times at which segments are cut, overrides Time
+	Times []time.Duration
+}
+
+func (o SegmentOptions) adaptCmd(cmd *exec.Cmd) {
+	if o.AtClocktime {
+		cmd.Args = append(cmd.Args, "-segment_atclocktime", "1")
+	}
+	if len(o.Format) > 0 {
+		cmd.Args = append(cmd.Args, "-segment_format", o.Format)
+	}
+	if len(o.List) > 0 {
+		cmd.Args = append(cmd.Args, "-segment_list", o.List)
+	}
+	if len(o.ListType) > 0 {
+		cmd.Args = append(cmd.Args, "-segment_list_type", o.ListType)
+	}
+	if o.ResetTimestamps != nil {
+		v := "0"
+		if *o.ResetTimestamps {
+			v = "1"
+		}
+		cmd.Args = append(cmd.Args, "-reset_timestamps", v)
+	}
+	if o.Strftime {
+		cmd.Args = append(cmd.Args, "-strftime", "1")
+	}
+	if o.Time != nil {
+		cmd.Args = append(cmd.Args, "-segment_time", strconv.FormatFloat(o.Time.Seconds(), 'f', 3, 64))
+	}
+	if len(o.Times) > 0 {
+		var ss []string
+		for _, t := range o.Times {
+			ss = append(ss, strconv.FormatFloat(t.Seconds(), 'f', 3, 64))
+		}
+		cmd.Args = append(cmd.Args, "-segment_times", strings.Join(ss, ","))
+	}
+}
@@ -0,0 +1,260 @@
+package astiffmpeg
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/asticode/go-astikit"
+)
+
+// SilenceDetectFilter creates a silencedetect filter logging to stderr the silences whose volume is lower than the
+// threshold (in dB, e.g. -50) for at least the specified duration
+func SilenceDetectFilter(threshold float64, d time.Duration) GenericFilter {
+	return GenericFilter{
+		Args: map[string]string{
+			"d": strconv.FormatFloat(d.Seconds(), 'f', 3, 64),
+			"n": strconv.FormatFloat(threshold, 'f', -1, 64) + "dB",
+		},
+		Name: "silencedetect",
+	}
+}
+
+// Silence represents a silence detected by the silencedetect filter
+type Silence struct {
+	// Not set while the silence is ongoing
+	End   *time.Duration
+	Start time.Duration
+}
+
+var (
+	// [silencedetect @ 0x7f9] silence_start: 12.5
+	silenceStartRegexp = regexp.MustCompile(`\[silencedetect @ [^\]]+\] silence_start: (-?[\d.]+)`)
+	// [silencedetect @ 0x7f9] silence_end: 15.2 | silence_duration: 2.7
+	silenceEndRegexp = regexp.MustCompile(`\[silencedetect @ [^\]]+\] silence_end: (-?[\d.]+)`)
+)
+
+// ParseSilences parses silences logged to stderr by the silencedetect filter
+func ParseSilences(b []byte) (ss []Silence) {
+	for _, l := range bytes.Split(b, []byte("\n")) {
+		if m := silenceStartRegexp.FindSubmatch(l); m != nil {
+			ss = append(ss, Silence{Start: secondsFromString(string(m[1]))})
+		} else if m := silenceEndRegexp.FindSubmatch(l); m != nil && len(ss) > 0 && ss[len(ss)-1].End == nil {
+			d := secondsFromString(string(m[1]))
+			ss[len(ss)-1].End = &d
+		}
+	}
+	return
+}
+
+func secondsFromString(v string) time.Duration {
+	f, _ := strconv.ParseFloat(v, 64)
+	return time.Duration(f * float64(time.Second))
+}
+
+// NewSilenceMonitor creates a stderr parser executing the callback with a HealthEventNameSilence event whenever the
+// silencedetect filter detects a silence, and with a HealthEventNameRecovered event once it ends
+func NewSilenceMonitor(period time.Duration, fn func(e HealthEvent)) StdErrParser {
+	return &silenceMonitor{
+		fn:     fn,
+		period: period,
+	}
+}
+
+type silenceMonitor struct {
+	fn     func(e HealthEvent)
+	period time.Duration
+	t      silenceTracker
+}
+
+func (m *silenceMonitor) Period() time.Duration {
+	return m.period
+}
+
+func (m *silenceMonitor) Process(t time.Time, b *bytes.Buffer) {
+	// Several silences may have happened between two ticks
+	for _, u := range m.t.update(b.Bytes()) {
+		// Silence has started
+		if u.started {
+			m.fn(HealthEvent{
+				Name:   HealthEventNameSilence,
+				Reason: "silence started at " + u.s.Start.String(),
+				Time:   t,
+			})
+		}
+
+		// Silence has ended
+		if u.s.End != nil {
+			m.fn(HealthEvent{
+				Name:   HealthEventNameRecovered,
+				Reason: "silence ended at " + u.s.End.String(),
+				Time:   t,
+			})
+		}
+	}
+}
+
+// silenceTracker accumulates silences parsed from stderr, which may only contain the latest logs. Silences are
+// identified by their start since they're logged in order.
+type silenceTracker struct {
+	ss []Silence
+}
+
+type silenceUpdate struct {
+	s       Silence
+	started bool
+}
+
+// update returns the silences that have started or ended since the previous update
+func (t *silenceTracker) update(b []byte) (us []silenceUpdate) {
+	for _, s := range ParseSilences(b) {
+		// Get last silence
+		var last *Silence
+		if len(t.ss) > 0 {
+			last = &t.ss[len(t.ss)-1]
+		}
+
+		switch {
+		case last == nil || s.Start > last.Start:
+			// Silence has started
+			t.ss = append(t.ss, s)
+			us = append(us, silenceUpdate{s: s, started: true})
+		case s.Start == last.Start && last.End == nil && s.End != nil:
+			// Silence has ended
+			last.End = s.End
+			us = append(us, silenceUpdate{s: *last})
+		}
+	}
+	return
+}
+
+// silenceCollector collects silences while ffmpeg is running so that none of them is lost when stderr only
+// contains the latest logs
+type silenceCollector struct {
+	t silenceTracker
+}
+
+func (c *silenceCollector) Period() time.Duration {
+	return time.Second
+}
+
+func (c *silenceCollector) Process(t time.Time, b *bytes.Buffer) {
+	c.t.update(b.Bytes())
+}
+
+// SilenceSplitOptions represents silence split options
+type SilenceSplitOptions struct {
+	// Minimum duration of a silence separating two utterances. Defaults to 500ms.
+	MinSilenceDuration time.Duration
+	// Output options shared by all utterances. Defaults to copying the audio stream.
+	Options *OutputOptions
+	// Volume (in dB) under which audio is considered silent. Defaults to -40.
+	Threshold float64
+}
+
+// Utterance represents a file produced by SplitOnSilences
+type Utterance struct {
+	End   time.Duration
+	Path  string
+	Start time.Duration
+}
+
+// SplitOnSilences splits a long recording into one file per utterance and returns the produced files with their
+// time range
+// Silences are detected in a first pass with the silencedetect filter, and the input is then cut in the middle of
+// each silence with the segment muxer. Output path must be a sequence pattern such as "utterance-%03d.wav".
+func (f *FFMpeg) SplitOnSilences(ctx context.Context, g GlobalOptions, in Input, o SilenceSplitOptions, outputPath string) (us []Utterance, err error) {
+	// Default options
+	if o.MinSilenceDuration <= 0 {
+		o.MinSilenceDuration = 500 * time.Millisecond
+	}
+	if o.Threshold == 0 {
+		o.Threshold = -40
+	}
+
+	// Probe duration
+	var d time.Duration
+	if d, err = f.Duration(ctx, in); err != nil {
+		err = fmt.Errorf("astiffmpeg: probing duration failed: %w", err)
+		return
+	}
+
+	// Detect silences
+	c := &silenceCollector{}
+	if err = f.ExecWithOptions(ctx, g, []Input{in}, NullOutput(&OutputOptions{
+		Encoding: &EncodingOptions{Filters: []StreamOption{audioStreamOption(FilterOptions{
+			Generic: []GenericFilter{SilenceDetectFilter(o.Threshold, o.MinSilenceDuration)},
+		})}},
+		NoVideo: true,
+	}), ExecOptions{StdErrParser: c}); err != nil {
+		err = fmt.Errorf("astiffmpeg: detecting silences failed: %w", err)
+		return
+	}
+
+	// Split
+	var oo *OutputOptions
+	if oo, us, err = silenceSplitOutputOptions(o, c.t.ss, d, outputPath); err != nil {
+		err = fmt.Errorf("astiffmpeg: creating output options failed: %w", err)
+		return
+	}
+	if err = f.Exec(ctx, g, []Input{in}, Output{Options: oo, Path: outputPath}); err != nil {
+		err = fmt.Errorf("astiffmpeg: splitting failed: %w", err)
+		return
+	}
+	return
+}
+
+func silenceSplitOutputOptions(o SilenceSplitOptions, ss []Silence, d time.Duration, outputPath string) (oo *OutputOptions, us []Utterance, err error) {
+	// Check output path
+	if !strings.Contains(outputPath, "%") {
+		err = errors.New("astiffmpeg: output path should be a sequence pattern")
+		return
+	}
+
+	// Cut in the middle of silences, except those touching the beginning or the end of the input since they don't
+	// separate utterances
+	var times []time.Duration
+	for _, s := range ss {
+		if s.End == nil || s.Start <= 0 || *s.End >= d {
+			continue
+		}
+		times = append(times, s.Start+(*s.End-s.Start)/2)
+	}
+
+	// Create utterances
+	start := time.Duration(0)
+	for idx, t := range append(times, d) {
+		us = append(us, Utterance{
+			End:   t,
+			Path:  fmt.Sprintf(outputPath, idx),
+			Start: start,
+		})
+		start = t
+	}
+
+	// Copy options
+	oo = &OutputOptions{Encoding: &EncodingOptions{Codec: []StreamOption{audioStreamOption(CodecCopy)}}}
+	if o.Options != nil {
+		c := *o.Options
+		oo = &c
+	}
+
+	// Segment
+	m := MuxingOptions{}
+	if oo.Muxing != nil {
+		m = *oo.Muxing
+	}
+	m.Segment = &SegmentOptions{
+		ResetTimestamps: astikit.BoolPtr(true),
+		Times:           times,
+	}
+	oo.Format = "segment"
+	oo.Muxing = &m
+	oo.NoVideo = true
+	return
+}
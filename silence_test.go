@@ -0,0 +1,71 @@
+package astiffmpeg
+
+import (
+	"bytes"
+	"os/exec"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/asticode/go-astikit"
+)
+
+var silenceStdErr = "[silencedetect @ 0x7f9] silence_start: 12.5\n" +
+	"[silencedetect @ 0x7f9] silence_end: 15.25 | silence_duration: 2.75\n" +
+	"[silencedetect @ 0x7f9] silence_start: 20\n"
+
+func TestParseSilences(t *testing.T) {
+	end := 15250 * time.Millisecond
+	if e, g := []Silence{{End: &end, Start: 12500 * time.Millisecond}, {Start: 20 * time.Second}}, ParseSilences([]byte(silenceStdErr)); !reflect.DeepEqual(e, g) {
+		t.Errorf("expected %+v, got %+v", e, g)
+	}
+}
+
+func TestSilenceMonitor(t *testing.T) {
+	var ns []string
+	m := NewSilenceMonitor(time.Second, func(e HealthEvent) { ns = append(ns, e.Name) })
+	m.Process(time.Now(), bytes.NewBufferString(silenceStdErr))
+	m.Process(time.Now(), bytes.NewBufferString(silenceStdErr))
+	m.Process(time.Now(), bytes.NewBufferString(silenceStdErr+"[silencedetect @ 0x7f9] silence_end: 21 | silence_duration: 1\n"))
+	if e := []string{HealthEventNameSilence, HealthEventNameRecovered, HealthEventNameSilence, HealthEventNameRecovered}; !reflect.DeepEqual(e, ns) {
+		t.Errorf("expected %+v, got %+v", e, ns)
+	}
+
+	// Older logs have been dropped
+	ns = []string{}
+	m.Process(time.Now(), bytes.NewBufferString("[silencedetect @ 0x7f9] silence_end: 21 | silence_duration: 1\n[silencedetect @ 0x7f9] silence_start: 30\n"))
+	if e := []string{HealthEventNameSilence}; !reflect.DeepEqual(e, ns) {
+		t.Errorf("expected %+v, got %+v", e, ns)
+	}
+}
+
+func TestSilenceSplitOutputOptions(t *testing.T) {
+	if _, _, err := silenceSplitOutputOptions(SilenceSplitOptions{}, nil, time.Minute, "utterance.wav"); err == nil {
+		t.Error("expected error")
+	}
+	oo, us, err := silenceSplitOutputOptions(SilenceSplitOptions{}, []Silence{
+		{End: astikit.DurationPtr(time.Second)},
+		{End: astikit.DurationPtr(12 * time.Second), Start: 10 * time.Second},
+		{End: astikit.DurationPtr(30 * time.Second), Start: 29 * time.Second},
+		{Start: 50 * time.Second},
+	}, time.Minute, "utterance-%03d.wav")
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	eu := []Utterance{
+		{End: 11 * time.Second, Path: "utterance-000.wav"},
+		{End: 29500 * time.Millisecond, Path: "utterance-001.wav", Start: 11 * time.Second},
+		{End: time.Minute, Path: "utterance-002.wav", Start: 29500 * time.Millisecond},
+	}
+	if !reflect.DeepEqual(eu, us) {
+		t.Errorf("expected %+v, got %+v", eu, us)
+	}
+	cmd := exec.Command("ffmpeg")
+	if err = oo.adaptCmd(cmd); err != nil {
+		t.Errorf("expected no error, got %s", err)
+	}
+	e := []string{"ffmpeg", "-codec:a", "copy", "-reset_timestamps", "1", "-segment_times", "11.000,29.500", "-vn", "-f", "segment"}
+	if !reflect.DeepEqual(e, cmd.Args) {
+		t.Errorf("expected %+v, got %+v", e, cmd.Args)
+	}
+}
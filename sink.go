@@ -0,0 +1,275 @@
+package astiffmpeg
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OutputSink receives outputs as they're produced, which allows e.g. uploading them to S3 or over HTTP without
+// waiting for ffmpeg to exit
+// WriteOutput is called with the output path as name, and a reader that returns io.EOF once the whole output has
+// been read. For HLS outputs, it's called for each segment once it's finalized and each time the playlist is
+// updated, with names relative to the directory of the output path.
+type OutputSink interface {
+	WriteOutput(ctx context.Context, name string, r io.Reader) error
+}
+
+// OutputSinkFunc is an adapter allowing the use of a function as an OutputSink
+type OutputSinkFunc func(ctx context.Context, name string, r io.Reader) error
+
+// WriteOutput implements the OutputSink interface
+func (fn OutputSinkFunc) WriteOutput(ctx context.Context, name string, r io.Reader) error {
+	return fn(ctx, name, r)
+}
+
+// How often the playlist of HLS outputs written to a sink is checked
+var outputSinkPlaylistPeriod = time.Second
+
+// Formats whose muxer needs to seek back in the output once done (e.g. to write an index or a header size), and
+// that are therefore written to a temporary file before being sent to the sink
+var seekingOutputFormats = map[string]bool{
+	"ipod": true,
+	"mov":  true,
+	"mp4":  true,
+	"wav":  true,
+}
+
+func outputFormat(o Output) string {
+	if o.Options != nil && len(o.Options.Format) > 0 {
+		return o.Options.Format
+	}
+	ext := strings.ToLower(filepath.Ext(o.Path))
+	if ext == ".m3u8" {
+		return "hls"
+	}
+	return outputFormatsByExtension[ext]
+}
+
+type outputSink struct {
+	dir  string
+	done chan struct{}
+	err  error
+	hls  bool
+	m    *sync.Mutex // Locks err
+	name string
+	path string
+	pr   *io.PipeReader
+	pw   *io.PipeWriter
+	s    OutputSink
+	stop chan struct{}
+	t    *hlsPlaylistTracker
+}
+
+// newOutputSink returns a copy of the output writing either to a pipe, or to a temporary directory in which case
+// files are sent to the sink once complete
+func newOutputSink(o Output) (out Output, s *outputSink, err error) {
+	// Create sink
+	s = &outputSink{
+		done: make(chan struct{}),
+		m:    &sync.Mutex{},
+		name: filepath.ToSlash(o.Path),
+		s:    o.Sink,
+		stop: make(chan struct{}),
+	}
+
+	// Get format
+	f := outputFormat(o)
+	if len(f) == 0 {
+		err = errors.New("astiffmpeg: format can't be inferred from the path and must be set")
+		return
+	} else if f == "image2" || isPatternPath(o.Path) {
+		err = fmt.Errorf("astiffmpeg: format %s: %w", f, ErrNotSupported)
+		return
+	}
+
+	// Copy options
+	c := copyOutputOptions(o.Options)
+	c.Format = f
+	out = o
+	out.Atomic = false
+	out.Options = c
+	out.Sink = nil
+
+	// Pipe
+	if f != "hls" && !seekingOutputFormats[f] {
+		s.pr, s.pw = io.Pipe()
+		out.Writer = s.pw
+		return
+	}
+
+	// Create temporary directory
+	s.hls = f == "hls"
+	if s.dir, err = os.MkdirTemp("", "astiffmpeg-sink-"); err != nil {
+		err = fmt.Errorf("astiffmpeg: creating temporary directory failed: %w", err)
+		return
+	}
+	s.path = filepath.Join(s.dir, filepath.Base(o.Path))
+	s.t = newHLSPlaylistTracker(s.path)
+	out.Path = s.path
+
+	// Segments are written next to the playlist
+	if s.hls && c.Muxing != nil && c.Muxing.HLS != nil && len(c.Muxing.HLS.SegmentFilename) > 0 {
+		if filepath.IsAbs(c.Muxing.HLS.SegmentFilename) {
+			s.close()
+			err = errors.New("astiffmpeg: hls segment filename must be relative")
+			return
+		}
+		m := *c.Muxing
+		h := *m.HLS
+		h.SegmentFilename = filepath.Join(s.dir, h.SegmentFilename)
+		m.HLS = &h
+		c.Muxing = &m
+	}
+	return
+}
+
+func (s *outputSink) setErr(err error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	if s.err == nil {
+		s.err = err
+	}
+}
+
+func (s *outputSink) start(ctx context.Context) {
+	go func() {
+		defer close(s.done)
+
+		// Pipe
+		if s.pr != nil {
+			err := s.s.WriteOutput(ctx, s.name, s.pr)
+			if err != nil {
+				s.setErr(fmt.Errorf("astiffmpeg: writing %s to sink failed: %w", s.name, err))
+			}
+
+			// Make sure writes don't block if the sink has stopped reading early
+			s.pr.CloseWithError(errors.New("astiffmpeg: sink has stopped reading"))
+			return
+		}
+
+		// Only HLS outputs are sent while ffmpeg is running
+		if !s.hls {
+			return
+		}
+
+		// Loop
+		t := time.NewTicker(outputSinkPlaylistPeriod)
+		defer t.Stop()
+		for {
+			select {
+			case <-s.stop:
+				return
+			case <-t.C:
+				if err := s.sendPlaylist(ctx); err != nil {
+					s.setErr(err)
+				}
+			}
+		}
+	}()
+}
+
+// sendPlaylist sends the segments listed in the playlist since the last time, which ffmpeg only lists once they're
+// finalized, and then the playlist itself if it has changed
+func (s *outputSink) sendPlaylist(ctx context.Context) (err error) {
+	// Check playlist
+	var ss []HLSSegment
+	var b []byte
+	if ss, b, err = s.t.check(); err != nil || b == nil {
+		return
+	}
+
+	// Loop through segments
+	for _, sg := range ss {
+		// Send segment
+		// Segments that are not local files are skipped, and segments may have been deleted in the meantime (e.g.
+		// with delete_segments)
+		if !strings.Contains(sg.URI, "://") {
+			if errSend := s.sendFile(ctx, s.t.segmentPath(sg), path.Join(path.Dir(s.name), sg.URI)); errSend != nil && !os.IsNotExist(errSend) {
+				// Segment will be sent again next time
+				if err == nil {
+					err = errSend
+				}
+				continue
+			}
+		}
+		s.t.done(sg)
+	}
+
+	// Playlist must not reference segments that have not been sent
+	if err != nil {
+		return
+	}
+
+	// Send playlist
+	if err = s.s.WriteOutput(ctx, s.name, bytes.NewReader(b)); err != nil {
+		err = fmt.Errorf("astiffmpeg: writing %s to sink failed: %w", s.name, err)
+		return
+	}
+	s.t.playlistDone(b)
+	return
+}
+
+func (s *outputSink) sendFile(ctx context.Context, p, name string) (err error) {
+	// Open
+	var f *os.File
+	if f, err = os.Open(p); err != nil {
+		return
+	}
+	defer f.Close()
+
+	// Write
+	if err = s.s.WriteOutput(ctx, name, f); err != nil {
+		err = fmt.Errorf("astiffmpeg: writing %s to sink failed: %w", name, err)
+		return
+	}
+	return
+}
+
+// wait waits for the output to be sent to the sink once ffmpeg has exited
+func (s *outputSink) wait(ctx context.Context, errExit error) error {
+	defer s.close()
+
+	// Pipe
+	if s.pw != nil {
+		s.pw.CloseWithError(errExit)
+		<-s.done
+		return s.err
+	}
+
+	// Stop sending the playlist periodically
+	close(s.stop)
+	<-s.done
+
+	// Send what remains
+	if errExit == nil {
+		if s.hls {
+			if err := s.sendPlaylist(ctx); err != nil {
+				s.setErr(err)
+			}
+		} else if err := s.sendFile(ctx, s.path, s.name); err != nil {
+			s.setErr(fmt.Errorf("astiffmpeg: sending %s failed: %w", s.path, err))
+		}
+	}
+	return s.err
+}
+
+func (s *outputSink) close() {
+	if s.pr != nil {
+		s.pr.Close()
+	}
+	if s.pw != nil {
+		s.pw.Close()
+	}
+	if len(s.dir) > 0 {
+		os.RemoveAll(s.dir)
+	}
+}
@@ -0,0 +1,115 @@
+package astiffmpeg
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// SlateOptions represents slate options
+type SlateOptions struct {
+	// Background color, defaults to "black"
+	Color string
+	// Defaults to "white"
+	FontColor string
+	// Defaults to 64
+	FontSize int
+	// Defaults to 30
+	Framerate float64
+	// Defaults to 1080
+	Height int
+	// Message written in the middle of the slate, no message is written when empty
+	Message string
+	// Uses a test pattern as background instead of a plain color
+	Pattern bool
+	// Defaults to 48000
+	SampleRate int
+	// Defaults to 1920
+	Width int
+}
+
+// SlateInput creates an input generating a slate video with silent stereo audio, using lavfi
+// When the duration is 0 the slate is infinite.
+func SlateInput(o SlateOptions, d time.Duration) Input {
+	// Default options
+	if len(o.Color) == 0 {
+		o.Color = "black"
+	}
+	if len(o.FontColor) == 0 {
+		o.FontColor = "white"
+	}
+	if o.FontSize <= 0 {
+		o.FontSize = 64
+	}
+	if o.Framerate <= 0 {
+		o.Framerate = 30
+	}
+	if o.Height <= 0 {
+		o.Height = 1080
+	}
+	if o.SampleRate <= 0 {
+		o.SampleRate = 48000
+	}
+	if o.Width <= 0 {
+		o.Width = 1920
+	}
+
+	// Background
+	bg := GenericFilter{Args: map[string]string{
+		"r": strconv.FormatFloat(o.Framerate, 'f', -1, 64),
+		"s": strconv.Itoa(o.Width) + "x" + strconv.Itoa(o.Height),
+	}}
+	if o.Pattern {
+		bg.Name = "testsrc2"
+	} else {
+		bg.Name = "color"
+		bg.Args["c"] = o.Color
+	}
+	v := bg.String()
+
+	// Message
+	if len(o.Message) > 0 {
+		v += "," + GenericFilter{
+			Args: map[string]string{
+				"expansion": "none",
+				"fontcolor": o.FontColor,
+				"fontsize":  strconv.Itoa(o.FontSize),
+				"text":      o.Message,
+				"x":         "(w-text_w)/2",
+				"y":         "(h-text_h)/2",
+			},
+			Name: "drawtext",
+		}.String()
+	}
+
+	// Audio
+	a := GenericFilter{
+		Args: map[string]string{
+			"channel_layout": "stereo",
+			"sample_rate":    strconv.Itoa(o.SampleRate),
+		},
+		Name: "anullsrc",
+	}.String()
+
+	// Create input
+	io := &InputOptions{Format: "lavfi"}
+	if d > 0 {
+		io.Decoding = &DecodingOptions{Duration: d}
+	}
+	return Input{
+		Options: io,
+		Path:    v + "[out0];" + a + "[out1]",
+	}
+}
+
+// GenerateSlate generates a slate of the specified duration, whose format is defined by the output so that it
+// can match a target profile (e.g. OutputProfileWeb1080pH264)
+func (f *FFMpeg) GenerateSlate(ctx context.Context, g GlobalOptions, o SlateOptions, d time.Duration, out Output) (err error) {
+	// Exec
+	if err = f.Exec(ctx, g, []Input{SlateInput(o, d)}, out); err != nil {
+		err = fmt.Errorf("astiffmpeg: executing failed: %w", err)
+		return
+	}
+	return
+}
@@ -0,0 +1,16 @@
+package astiffmpeg
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSlateInput(t *testing.T) {
+	i := SlateInput(SlateOptions{Message: "Back soon: stay tuned"}, time.Minute)
+	if e := `color=c=black:r=30:s=1920x1080,drawtext=expansion=none:fontcolor=white:fontsize=64:text=Back soon\\: stay tuned:x=(w-text_w)/2:y=(h-text_h)/2[out0];anullsrc=channel_layout=stereo:sample_rate=48000[out1]`; i.Path != e {
+		t.Errorf("expected %s, got %s", e, i.Path)
+	}
+	if i.Options.Format != "lavfi" || i.Options.Decoding.Duration != time.Minute {
+		t.Errorf("invalid options %+v", i.Options)
+	}
+}
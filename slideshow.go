@@ -0,0 +1,181 @@
+package astiffmpeg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/asticode/go-astikit"
+)
+
+// SlideshowImage represents a slideshow image
+type SlideshowImage struct {
+	Duration time.Duration
+	Path     string
+}
+
+// SlideshowOptions represents slideshow options
+type SlideshowOptions struct {
+	// Encoding options of the output. Defaults to H.264/AAC.
+	Encoding  *EncodingOptions
+	Framerate float64 // Defaults to 25
+	Height    int     // Defaults to 1080
+	// Slowly zooms into each image
+	KenBurns bool
+	// When set, the music is added as the audio track and the output stops when the shortest of the video and the
+	// music ends
+	MusicPath string
+	// Name of the xfade transition played between images (e.g. "fade", "wipeleft", "dissolve", ...). Empty means
+	// hard cuts.
+	Transition         string
+	TransitionDuration time.Duration // Defaults to 1s
+	Width              int           // Defaults to 1920
+}
+
+// Slideshow creates a video out of a list of images
+func (f *FFMpeg) Slideshow(ctx context.Context, g GlobalOptions, images []SlideshowImage, o SlideshowOptions, outputPath string) (err error) {
+	// Check input
+	if len(images) == 0 {
+		err = errors.New("astiffmpeg: no images provided")
+		return
+	}
+
+	// Default options
+	if o.Framerate <= 0 {
+		o.Framerate = 25
+	}
+	if o.Height <= 0 {
+		o.Height = 1080
+	}
+	if o.TransitionDuration <= 0 {
+		o.TransitionDuration = time.Second
+	}
+	if o.Width <= 0 {
+		o.Width = 1920
+	}
+	if o.Encoding == nil {
+		o.Encoding = &EncodingOptions{
+			Codec: []StreamOption{
+				videoStreamOption(CodecLibx264),
+				audioStreamOption(CodecAAC),
+			},
+			PixelFormat: PixelFormatYUV420P,
+		}
+	}
+
+	// Create inputs
+	var in []Input
+	for _, i := range images {
+		if len(o.Transition) > 0 && i.Duration <= o.TransitionDuration {
+			err = fmt.Errorf("astiffmpeg: image %s duration should be > transition duration", i.Path)
+			return
+		}
+		if o.KenBurns {
+			// Zoompan generates frames out of a single image
+			in = append(in, Input{Path: i.Path})
+		} else {
+			in = append(in, Input{
+				Options: &InputOptions{
+					Decoding: &DecodingOptions{Duration: i.Duration},
+					Loop:     true,
+				},
+				Path: i.Path,
+			})
+		}
+	}
+
+	// Create output options
+	e := copyEncodingOptions(o.Encoding)
+	e.ComplexFilters = slideshowFilters(images, o)
+	oo := &OutputOptions{
+		Encoding: e,
+		Map:      &MapOptions{{Label: "v"}},
+	}
+	if len(o.MusicPath) > 0 {
+		*oo.Map = append(*oo.Map, MapOption{InputFileID: len(in), Stream: &StreamSpecifier{Type: StreamSpecifierTypeAudio}})
+		oo.Shortest = true
+		in = append(in, Input{Path: o.MusicPath})
+	}
+
+	// Exec
+	if err = f.Exec(ctx, g, in, Output{Options: oo, Path: outputPath}); err != nil {
+		err = fmt.Errorf("astiffmpeg: executing failed: %w", err)
+		return
+	}
+	return
+}
+
+func slideshowFilters(images []SlideshowImage, o SlideshowOptions) (cfs []ComplexFilterOption) {
+	// Loop through images
+	var labels []StreamSpecifier
+	fps := strconv.FormatFloat(o.Framerate, 'f', -1, 64)
+	for idx, i := range images {
+		// Fit image in frame
+		fs := []string{
+			"scale=" + Scale{ForceOriginalAspectRatio: "decrease", Height: astikit.IntPtr(o.Height), Width: astikit.IntPtr(o.Width)}.string(),
+			"pad=" + Pad{Height: strconv.Itoa(o.Height), Width: strconv.Itoa(o.Width), X: "(ow-iw)/2", Y: "(oh-ih)/2"}.string(),
+			"setsar=" + Ratio{Antecedent: 1, Consequent: 1}.string(),
+		}
+
+		// Animate
+		if o.KenBurns {
+			fs = append(fs, "zoompan="+ZoomPan{
+				Duration: astikit.IntPtr(int(i.Duration.Seconds() * o.Framerate)),
+				FPS:      astikit.Float64Ptr(o.Framerate),
+				Size:     fmt.Sprintf("%dx%d", o.Width, o.Height),
+				X:        "iw/2-(iw/zoom/2)",
+				Y:        "ih/2-(ih/zoom/2)",
+				Zoom:     "min(zoom+0.0015,1.5)",
+			}.string())
+		} else {
+			fs = append(fs, "fps="+fps)
+		}
+		fs = append(fs, "format="+string(PixelFormatYUV420P))
+
+		// Append filter
+		l := StreamSpecifier{Name: "i" + strconv.Itoa(idx)}
+		cfs = append(cfs, ComplexFilterOption{
+			Filters:       fs,
+			InputStreams:  []StreamSpecifier{{Name: strconv.Itoa(idx) + ":v"}},
+			OutputStreams: []StreamSpecifier{l},
+		})
+		labels = append(labels, l)
+	}
+
+	// No transition
+	if len(o.Transition) == 0 {
+		cfs = append(cfs, ComplexFilterOption{
+			Filters:       []string{fmt.Sprintf("concat=n=%d:v=1:a=0", len(labels))},
+			InputStreams:  labels,
+			OutputStreams: []StreamSpecifier{{Name: "v"}},
+		})
+		return
+	}
+
+	// Chain transitions
+	prev := labels[0]
+	var offset time.Duration
+	for idx := 1; idx < len(labels); idx++ {
+		offset += images[idx-1].Duration - o.TransitionDuration
+		l := StreamSpecifier{Name: "x" + strconv.Itoa(idx)}
+		if idx == len(labels)-1 {
+			l = StreamSpecifier{Name: "v"}
+		}
+		cfs = append(cfs, ComplexFilterOption{
+			Filters: []string{fmt.Sprintf("xfade=transition=%s:duration=%s:offset=%s", o.Transition,
+				strconv.FormatFloat(o.TransitionDuration.Seconds(), 'f', 3, 64),
+				strconv.FormatFloat(offset.Seconds(), 'f', 3, 64))},
+			InputStreams:  []StreamSpecifier{prev, labels[idx]},
+			OutputStreams: []StreamSpecifier{l},
+		})
+		prev = l
+	}
+
+	// Single image
+	if len(labels) == 1 {
+		cfs[0].OutputStreams = []StreamSpecifier{{Name: "v"}}
+	}
+	return
+}
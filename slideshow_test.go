@@ -0,0 +1,31 @@
+package astiffmpeg
+
+import (
+	"os/exec"
+	"testing"
+	"time"
+)
+
+func TestSlideshowFilters(t *testing.T) {
+	cmd := exec.Command("ffmpeg")
+	if err := (EncodingOptions{ComplexFilters: slideshowFilters([]SlideshowImage{
+		{Duration: 3 * time.Second, Path: "1.jpg"},
+		{Duration: 4 * time.Second, Path: "2.jpg"},
+		{Duration: 5 * time.Second, Path: "3.jpg"},
+	}, SlideshowOptions{
+		Framerate:          25,
+		Height:             720,
+		Transition:         "fade",
+		TransitionDuration: time.Second,
+		Width:              1280,
+	})}).adaptCmd(cmd); err != nil {
+		t.Errorf("expected no error, got %s", err.Error())
+	}
+	f := "scale=h=720:w=1280:force_original_aspect_ratio=decrease,pad=w=1280:h=720:x=(ow-iw)/2:y=(oh-ih)/2,setsar=1/1,fps=25,format=yuv420p"
+	e := "[0:v]" + f + "[i0];[1:v]" + f + "[i1];[2:v]" + f + "[i2];" +
+		"[i0][i1]xfade=transition=fade:duration=1.000:offset=2.000[x1];" +
+		"[x1][i2]xfade=transition=fade:duration=1.000:offset=5.000[v]"
+	if len(cmd.Args) != 3 || cmd.Args[2] != e {
+		t.Errorf("expected %s, got %+v", e, cmd.Args)
+	}
+}
@@ -0,0 +1,160 @@
+package astiffmpeg
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/asticode/go-astikit"
+)
+
+// SmartTranscodeTarget represents what streams of a type should look like in the output
+type SmartTranscodeTarget struct {
+	// Only checked when set
+	Channels *int
+	// Codec name as reported by ffprobe (e.g. "h264", "hevc" or "aac")
+	Codec string
+	// Encoder used when the stream doesn't match. Defaults to Codec.
+	Encoder string
+	// Maximum level (e.g. 4.1), only checked when set and only supported for h264 and hevc
+	Level *float64
+	// Only checked when set
+	PixelFormat PixelFormat
+	// Profile as reported by ffprobe (e.g. "High" or "LC"), only checked when set
+	Profile string
+	// Only checked when set
+	SampleRate *int
+}
+
+func (t SmartTranscodeTarget) matches(s ProbeStream) bool {
+	if s.CodecName != t.Codec {
+		return false
+	}
+	if t.Channels != nil && (s.Channels == nil || *s.Channels != *t.Channels) {
+		return false
+	}
+	if t.Level != nil {
+		l, ok := probeStreamLevel(s)
+		if !ok || l > *t.Level {
+			return false
+		}
+	}
+	if len(t.PixelFormat) > 0 && s.PixelFormat != string(t.PixelFormat) {
+		return false
+	}
+	if len(t.Profile) > 0 && !strings.EqualFold(s.Profile, t.Profile) {
+		return false
+	}
+	if t.SampleRate != nil && (s.SampleRate == nil || *s.SampleRate != *t.SampleRate) {
+		return false
+	}
+	return true
+}
+
+// ffprobe reports h264 levels multiplied by 10 and hevc levels multiplied by 30
+func probeStreamLevel(s ProbeStream) (l float64, ok bool) {
+	if s.Level == nil {
+		return
+	}
+	switch s.CodecName {
+	case "h264":
+		return float64(*s.Level) / 10, true
+	case "hevc":
+		return float64(*s.Level) / 30, true
+	}
+	return
+}
+
+// SmartTranscodeOptions represents smart transcode options
+type SmartTranscodeOptions struct {
+	Audio *SmartTranscodeTarget
+	// Shared by all streams, however encoding options only apply to transcoded streams. Since -profile is not stream
+	// specific, the target profile is not set automatically and should be set here when only one stream type may be
+	// transcoded.
+	Options *OutputOptions
+	Video   *SmartTranscodeTarget
+}
+
+// SmartTranscode probes each stream of the input and copies the ones that already match their target, while only
+// transcoding the others, which is far faster than transcoding everything
+// Streams without target (e.g. subtitles) are copied.
+func (f *FFMpeg) SmartTranscode(ctx context.Context, g GlobalOptions, in Input, o SmartTranscodeOptions, outputPath string) (err error) {
+	// Check input
+	if err = in.checkProbable(); err != nil {
+		return
+	}
+
+	// Probe streams
+	var ss []ProbeStream
+	if ss, err = f.ProbeStreams(ctx, in.Path); err != nil {
+		err = fmt.Errorf("astiffmpeg: probing streams failed: %w", err)
+		return
+	}
+
+	// Exec
+	if err = f.Exec(ctx, g, []Input{in}, Output{
+		Options: smartTranscodeOutputOptions(ss, o),
+		Path:    outputPath,
+	}); err != nil {
+		err = fmt.Errorf("astiffmpeg: executing failed: %w", err)
+		return
+	}
+	return
+}
+
+func smartTranscodeOutputOptions(ss []ProbeStream, o SmartTranscodeOptions) *OutputOptions {
+	// Copy options
+	oo := copyOutputOptions(o.Options)
+	e := copyEncodingOptions(oo.Encoding)
+
+	// Loop through streams
+	m := MapOptions{}
+	e.Codec = nil
+	for idx, s := range ss {
+		// Map stream
+		m = append(m, MapOption{Stream: &StreamSpecifier{Index: astikit.IntPtr(s.Index)}})
+
+		// Get target
+		var t *SmartTranscodeTarget
+		switch s.CodecType {
+		case "audio":
+			t = o.Audio
+		case "video":
+			t = o.Video
+		}
+
+		// Get codec
+		c := CodecCopy
+		if t != nil && !t.matches(s) {
+			c = t.Codec
+			if len(t.Encoder) > 0 {
+				c = t.Encoder
+			}
+
+			// Only transcoded streams are affected by these options
+			switch s.CodecType {
+			case "audio":
+				if t.Channels != nil {
+					e.AudioChannels = t.Channels
+				}
+				if t.SampleRate != nil {
+					e.AudioSamplerate = t.SampleRate
+				}
+			case "video":
+				if t.Level != nil {
+					e.Level = t.Level
+				}
+				if len(t.PixelFormat) > 0 {
+					e.PixelFormat = t.PixelFormat
+				}
+			}
+		}
+		e.Codec = append(e.Codec, StreamOption{
+			Stream: &StreamSpecifier{Index: astikit.IntPtr(idx)},
+			Value:  c,
+		})
+	}
+	oo.Encoding = e
+	oo.Map = &m
+	return oo
+}
@@ -0,0 +1,44 @@
+package astiffmpeg
+
+import (
+	"os/exec"
+	"reflect"
+	"testing"
+
+	"github.com/asticode/go-astikit"
+)
+
+func TestSmartTranscodeOutputOptions(t *testing.T) {
+	ss := []ProbeStream{
+		{CodecName: "h264", CodecType: "video", Index: 0, Level: astikit.IntPtr(40), PixelFormat: "yuv420p", Profile: "High"},
+		{CodecName: "ac3", CodecType: "audio", Index: 1, SampleRate: astikit.IntPtr(48000)},
+		{CodecName: "aac", CodecType: "audio", Index: 2, Profile: "LC", SampleRate: astikit.IntPtr(48000)},
+		{CodecName: "subrip", CodecType: "subtitle", Index: 3},
+	}
+	o := SmartTranscodeOptions{
+		Audio: &SmartTranscodeTarget{Codec: "aac", SampleRate: astikit.IntPtr(48000)},
+		Video: &SmartTranscodeTarget{Codec: "h264", Encoder: CodecLibx264, Level: astikit.Float64Ptr(4.1)},
+	}
+	cmd := exec.Command("ffmpeg")
+	if err := smartTranscodeOutputOptions(ss, o).adaptCmd(cmd); err != nil {
+		t.Errorf("expected no error, got %s", err)
+	}
+	e := []string{"ffmpeg",
+		"-map", "0:0", "-map", "0:1", "-map", "0:2", "-map", "0:3",
+		"-ar", "48000", "-codec:0", "copy", "-codec:1", "aac", "-codec:2", "copy", "-codec:3", "copy",
+	}
+	if !reflect.DeepEqual(e, cmd.Args) {
+		t.Errorf("expected %+v, got %+v", e, cmd.Args)
+	}
+
+	// Level is too high
+	o.Video.Level = astikit.Float64Ptr(3.1)
+	cmd = exec.Command("ffmpeg")
+	if err := smartTranscodeOutputOptions(ss[:1], o).adaptCmd(cmd); err != nil {
+		t.Errorf("expected no error, got %s", err)
+	}
+	e = []string{"ffmpeg", "-map", "0:0", "-codec:0", "libx264", "-level", "3.1"}
+	if !reflect.DeepEqual(e, cmd.Args) {
+		t.Errorf("expected %+v, got %+v", e, cmd.Args)
+	}
+}
@@ -0,0 +1,97 @@
+package astiffmpeg
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/asticode/go-astikit"
+)
+
+// SocialTarget represents the constraints of a social media platform deliverable
+type SocialTarget struct {
+	Height      int
+	MaxDuration time.Duration
+	Width       int
+}
+
+// Social targets
+var (
+	SocialTargetSquare   = SocialTarget{Height: 1080, MaxDuration: time.Minute, Width: 1080}
+	SocialTargetVertical = SocialTarget{Height: 1920, MaxDuration: time.Minute, Width: 1080}
+)
+
+// Social fits
+const (
+	// The picture fills the whole frame and what overflows is cropped
+	SocialFitCrop = "crop"
+	// The whole picture is visible and the remaining space is filled with black bars
+	SocialFitPad = "pad"
+)
+
+// SocialOutputOptions creates output options compliant with the specified target: H.264 capped at level 4.1,
+// yuv420p, square pixels, AAC-LC audio, faststart mp4 and duration capped to the target max duration
+// It expects the video to be read from the first input and maps the first input's audio if any
+func SocialOutputOptions(t SocialTarget, fit string) (o OutputOptions, err error) {
+	// Build filters
+	w, h := strconv.Itoa(t.Width), strconv.Itoa(t.Height)
+	var fs []string
+	switch fit {
+	case SocialFitCrop:
+		fs = append(fs,
+			"scale="+Scale{ForceOriginalAspectRatio: "increase", Height: astikit.IntPtr(t.Height), Width: astikit.IntPtr(t.Width)}.string(),
+			"crop="+Crop{Height: h, Width: w}.string(),
+		)
+	case SocialFitPad:
+		fs = append(fs,
+			"scale="+Scale{ForceOriginalAspectRatio: "decrease", Height: astikit.IntPtr(t.Height), Width: astikit.IntPtr(t.Width)}.string(),
+			"pad="+Pad{Color: "black", Height: h, Width: w, X: "(ow-iw)/2", Y: "(oh-ih)/2"}.string(),
+		)
+	default:
+		err = fmt.Errorf("astiffmpeg: invalid fit %s", fit)
+		return
+	}
+	fs = append(fs, "setsar="+Ratio{Antecedent: 1, Consequent: 1}.string())
+
+	// Create options
+	o = OutputOptions{
+		Duration: t.MaxDuration,
+		Encoding: &EncodingOptions{
+			AudioChannels:   astikit.IntPtr(2),
+			AudioSamplerate: astikit.IntPtr(48000),
+			Bitrate:         []StreamOption{audioStreamOption(Number{Prefix: "k", Value: 128})},
+			Codec: []StreamOption{
+				videoStreamOption(CodecLibx264),
+				audioStreamOption(CodecAAC),
+			},
+			ComplexFilters: []ComplexFilterOption{{
+				Filters:       fs,
+				InputStreams:  []StreamSpecifier{{Name: "0:v"}},
+				OutputStreams: []StreamSpecifier{{Name: "v"}},
+			}},
+			CRF:         astikit.IntPtr(23),
+			Level:       astikit.Float64Ptr(4.1),
+			PixelFormat: PixelFormatYUV420P,
+			Preset:      PresetMedium,
+		},
+		Format: "mp4",
+		Map: &MapOptions{
+			{Label: "v"},
+			{Optional: true, Stream: &StreamSpecifier{Type: StreamSpecifierTypeAudio}},
+		},
+		Muxing: &MuxingOptions{MovFlags: []string{MovFlagFaststart}},
+	}
+	return
+}
+
+// OutputProfileSocialSquare1x1 returns output options producing a square video suitable for social media
+func OutputProfileSocialSquare1x1() OutputOptions {
+	o, _ := SocialOutputOptions(SocialTargetSquare, SocialFitCrop)
+	return o
+}
+
+// OutputProfileSocialVertical9x16 returns output options producing a vertical video suitable for social media
+func OutputProfileSocialVertical9x16() OutputOptions {
+	o, _ := SocialOutputOptions(SocialTargetVertical, SocialFitCrop)
+	return o
+}
@@ -0,0 +1,97 @@
+package astiffmpeg
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/asticode/go-astikit"
+)
+
+// SpeechOptions represents speech preprocessing options
+type SpeechOptions struct {
+	// Frequencies lower than this value are removed (e.g. rumble). Defaults to 80Hz.
+	HighpassFrequency int
+	// Frequencies higher than this value are removed (e.g. hiss). Defaults to 7600Hz, which is right under the
+	// Nyquist frequency of the default sample rate.
+	LowpassFrequency int
+	// Disables FFT denoising
+	NoDenoise bool
+	// Disables loudness normalization
+	NoLoudnorm bool
+	// Defaults to 16000 which is what most speech-to-text engines expect
+	SampleRate int
+}
+
+func (o SpeechOptions) withDefaults() SpeechOptions {
+	if o.HighpassFrequency <= 0 {
+		o.HighpassFrequency = 80
+	}
+	if o.LowpassFrequency <= 0 {
+		o.LowpassFrequency = 7600
+	}
+	if o.SampleRate <= 0 {
+		o.SampleRate = 16000
+	}
+	return o
+}
+
+// SpeechFilter creates the filter chain cleaning up speech: highpass and lowpass filters, FFT denoising and loudness
+// normalization
+func SpeechFilter(o SpeechOptions) FilterOptions {
+	o = o.withDefaults()
+	fs := []GenericFilter{
+		{Args: map[string]string{"f": strconv.Itoa(o.HighpassFrequency)}, Name: "highpass"},
+		{Args: map[string]string{"f": strconv.Itoa(o.LowpassFrequency)}, Name: "lowpass"},
+	}
+	if !o.NoDenoise {
+		fs = append(fs, GenericFilter{Name: "afftdn"})
+	}
+	if !o.NoLoudnorm {
+		fs = append(fs, GenericFilter{Args: map[string]string{"I": "-16", "LRA": "11", "TP": "-1.5"}, Name: "loudnorm"})
+	}
+	return FilterOptions{Generic: fs}
+}
+
+// SpeechOutputOptions creates output options producing a 16 bits mono WAV cleaned up with SpeechFilter, which is
+// suitable for speech-to-text engines
+func SpeechOutputOptions(o SpeechOptions) OutputOptions {
+	o = o.withDefaults()
+	return OutputOptions{
+		Encoding: &EncodingOptions{
+			AudioChannels:   astikit.IntPtr(1),
+			AudioSamplerate: astikit.IntPtr(o.SampleRate),
+			Codec:           []StreamOption{audioStreamOption("pcm_s16le")},
+			Filters:         []StreamOption{audioStreamOption(SpeechFilter(o))},
+		},
+		Format:  "wav",
+		Map:     &MapOptions{{Stream: &StreamSpecifier{Index: astikit.IntPtr(0), Type: StreamSpecifierTypeAudio}}},
+		NoVideo: true,
+	}
+}
+
+// OutputProfileSpeechWAV returns output options producing a WAV suitable for speech-to-text engines
+func OutputProfileSpeechWAV() OutputOptions {
+	return SpeechOutputOptions(SpeechOptions{})
+}
+
+// PreprocessSpeech cleans up the first audio stream of the input with SpeechFilter and writes it to w as 16 bits
+// mono raw PCM while it's processed, which allows feeding speech-to-text engines without touching disk
+func (f *FFMpeg) PreprocessSpeech(ctx context.Context, g GlobalOptions, in Input, o SpeechOptions, w io.Writer) (j *Job, err error) {
+	// Create output
+	o = o.withDefaults()
+	out := pcmOutput(PCMOptions{
+		Channels:   1,
+		Format:     PCMFormatS16LE,
+		SampleRate: o.SampleRate,
+	})
+	out.Options.Encoding.Filters = []StreamOption{audioStreamOption(SpeechFilter(o))}
+
+	// Exec
+	if j, err = f.ExecAsyncWithOptions(ctx, g, []Input{in}, out, ExecOptions{Stdout: w}); err != nil {
+		err = fmt.Errorf("astiffmpeg: starting job failed: %w", err)
+		return
+	}
+	return
+}
@@ -0,0 +1,26 @@
+package astiffmpeg
+
+import (
+	"os/exec"
+	"reflect"
+	"testing"
+)
+
+func TestSpeechOutputOptions(t *testing.T) {
+	o, err := OutputProfile(OutputProfileNameSpeechWAV)
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	cmd := exec.Command("ffmpeg")
+	if err = o.adaptCmd(cmd); err != nil {
+		t.Errorf("expected no error, got %s", err)
+	}
+	e := []string{"ffmpeg",
+		"-map", "0:a:0", "-ac", "1", "-ar", "16000", "-codec:a", "pcm_s16le",
+		"-filter:a", "highpass=f=80,lowpass=f=7600,afftdn,loudnorm=I=-16:LRA=11:TP=-1.5",
+		"-vn", "-f", "wav",
+	}
+	if !reflect.DeepEqual(e, cmd.Args) {
+		t.Errorf("expected %+v, got %+v", e, cmd.Args)
+	}
+}
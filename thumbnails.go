@@ -0,0 +1,95 @@
+package astiffmpeg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ScreenshotsAtPercentages extracts one frame at each of the specified percentages (from 0 to 100) of the input
+// duration in a single ffmpeg invocation
+// Input duration is probed first. Output path must be an image2 pattern such as "screenshot-%03d.jpg"
+func (f *FFMpeg) ScreenshotsAtPercentages(ctx context.Context, g GlobalOptions, in Input, percentages []float64, outputPath string) (err error) {
+	// Check input
+	if len(percentages) == 0 {
+		err = errors.New("astiffmpeg: no percentages provided")
+		return
+	}
+
+	// Probe duration
+	var d time.Duration
+	if d, err = f.Duration(ctx, in); err != nil {
+		err = fmt.Errorf("astiffmpeg: probing duration failed: %w", err)
+		return
+	}
+
+	// Exec
+	if err = f.Exec(ctx, g, []Input{in}, Output{
+		Options: &OutputOptions{
+			Encoding: &EncodingOptions{
+				Filters: []StreamOption{videoStreamOption(FilterOptions{Select: "'" + percentagesSelectExpr(d, percentages) + "'"})},
+				Frames:  []StreamOption{videoStreamOption(len(percentages))},
+			},
+			FPSMode: FPSModeVFR,
+			NoAudio: true,
+		},
+		Path: outputPath,
+	}); err != nil {
+		err = fmt.Errorf("astiffmpeg: executing failed: %w", err)
+		return
+	}
+	return
+}
+
+// Selects the first frame whose timestamp is greater than or equal to each percentage of the duration
+func percentagesSelectExpr(d time.Duration, percentages []float64) string {
+	var ss []string
+	for _, p := range percentages {
+		t := strconv.FormatFloat(d.Seconds()*p/100, 'f', 3, 64)
+		ss = append(ss, fmt.Sprintf("gte(t,%s)*(isnan(prev_pts)+lt(prev_pts*TB,%s))", t, t))
+	}
+	return strings.Join(ss, "+")
+}
+
+// PosterOptions represents poster options
+type PosterOptions struct {
+	// Number of frames among which the most representative one is picked. Defaults to 100.
+	BatchSize *int
+	// When set, only frames whose scene change score (from 0 to 1) is greater are considered, which favors frames
+	// with actual content
+	MinSceneScore *float64
+	Scale         *Scale
+}
+
+// PickPoster extracts a single representative frame of the input using the thumbnail filter
+// The thumbnail filter picks the frame closest to the average of the batch which rules out black or fading frames
+func (f *FFMpeg) PickPoster(ctx context.Context, g GlobalOptions, in Input, o PosterOptions, outputPath string) (err error) {
+	// Create filter
+	fo := FilterOptions{
+		Scale:     o.Scale,
+		Thumbnail: &Thumbnail{N: o.BatchSize},
+	}
+	if o.MinSceneScore != nil {
+		fo.Select = "'gt(scene," + strconv.FormatFloat(*o.MinSceneScore, 'f', 3, 64) + ")'"
+	}
+
+	// Exec
+	if err = f.Exec(ctx, g, []Input{in}, Output{
+		Options: &OutputOptions{
+			Encoding: &EncodingOptions{
+				Filters: []StreamOption{videoStreamOption(fo)},
+				Frames:  []StreamOption{videoStreamOption(1)},
+			},
+			FPSMode: FPSModeVFR,
+			NoAudio: true,
+		},
+		Path: outputPath,
+	}); err != nil {
+		err = fmt.Errorf("astiffmpeg: executing failed: %w", err)
+		return
+	}
+	return
+}
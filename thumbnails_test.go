@@ -0,0 +1,13 @@
+package astiffmpeg
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPercentagesSelectExpr(t *testing.T) {
+	e := "gte(t,1.000)*(isnan(prev_pts)+lt(prev_pts*TB,1.000))+gte(t,5.000)*(isnan(prev_pts)+lt(prev_pts*TB,5.000))"
+	if g := percentagesSelectExpr(10*time.Second, []float64{10, 50}); g != e {
+		t.Errorf("expected %s, got %s", e, g)
+	}
+}
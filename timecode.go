@@ -0,0 +1,165 @@
+package astiffmpeg
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Timecode represents a SMPTE timecode such as 01:00:10:12 (non drop frame) or 01:00:10;12 (drop frame)
+// It can be used directly as a position or a duration (e.g. DecodingOptions.PositionTimecode), or through the
+// durations it converts to anywhere else.
+type Timecode struct {
+	// Drop frame timecodes skip frame numbers 0 and 1 (0 to 3 at 59.94 fps) of every minute except every tenth
+	// minute, so that they stay in sync with the wall clock at NTSC frame rates. They only exist for 29.97 and 59.94
+	// fps.
+	DropFrame bool
+	FPS       float64 // e.g. 25 or 30000/1001
+	Frames    int
+	Hours     int
+	Minutes   int
+	Seconds   int
+}
+
+// ParseTimecode parses a timecode such as 01:00:10:12, a ";" or "." separator before frames meaning drop frame
+func ParseTimecode(s string, fps float64) (t Timecode, err error) {
+	// Check fps
+	if fps <= 0 {
+		err = fmt.Errorf("astiffmpeg: invalid fps %v", fps)
+		return
+	}
+	t.FPS = fps
+
+	// Get drop frame
+	v := s
+	if idx := strings.LastIndexAny(v, ";."); idx >= 0 {
+		if !dropFrameSupported(fps) {
+			err = fmt.Errorf("astiffmpeg: drop frame timecode %s is not supported at %v fps", s, fps)
+			return
+		}
+		t.DropFrame = true
+		v = v[:idx] + ":" + v[idx+1:]
+	}
+
+	// Split
+	ps := strings.Split(v, ":")
+	if len(ps) != 4 {
+		err = fmt.Errorf("astiffmpeg: invalid timecode %s", s)
+		return
+	}
+
+	// Parse
+	for idx, p := range []*int{&t.Hours, &t.Minutes, &t.Seconds, &t.Frames} {
+		if *p, err = strconv.Atoi(ps[idx]); err != nil {
+			err = fmt.Errorf("astiffmpeg: atoi of %s failed: %w", ps[idx], err)
+			return
+		}
+	}
+
+	// Validate
+	if t.Minutes > 59 || t.Seconds > 59 || t.Frames >= t.nominalFPS() {
+		err = fmt.Errorf("astiffmpeg: invalid timecode %s", s)
+		return
+	}
+	if t.DropFrame && t.Seconds == 0 && t.Minutes%10 != 0 && t.Frames < t.droppedFrames() {
+		err = fmt.Errorf("astiffmpeg: timecode %s is dropped", s)
+		return
+	}
+	return
+}
+
+// TimecodeFromFrame creates the timecode of the specified frame number
+func TimecodeFromFrame(n int, fps float64, dropFrame bool) (t Timecode, err error) {
+	// Check fps
+	if fps <= 0 {
+		err = fmt.Errorf("astiffmpeg: invalid fps %v", fps)
+		return
+	} else if dropFrame && !dropFrameSupported(fps) {
+		err = fmt.Errorf("astiffmpeg: drop frame timecodes are not supported at %v fps", fps)
+		return
+	}
+	t = Timecode{DropFrame: dropFrame, FPS: fps}
+	nominal := t.nominalFPS()
+
+	// Add dropped frame numbers back
+	if dropFrame {
+		drop := t.droppedFrames()
+		perMinute := nominal*60 - drop
+		per10Minutes := perMinute*10 + drop
+		d, m := n/per10Minutes, n%per10Minutes
+		n += 9 * drop * d
+		if m > drop {
+			n += drop * ((m - drop) / perMinute)
+		}
+	}
+
+	// Split
+	t.Frames = n % nominal
+	t.Seconds = n / nominal % 60
+	t.Minutes = n / nominal / 60 % 60
+	t.Hours = n / nominal / 3600
+	return
+}
+
+// TimecodeFromDuration creates the timecode of the frame displayed at the specified duration
+func TimecodeFromDuration(d time.Duration, fps float64, dropFrame bool) (Timecode, error) {
+	return TimecodeFromFrame(int(math.Floor(d.Seconds()*fps+1e-6)), fps, dropFrame)
+}
+
+// dropFrameSupported checks whether the fps is 29.97 or 59.94
+func dropFrameSupported(fps float64) bool {
+	for _, v := range []float64{30000.0 / 1001, 60000.0 / 1001} {
+		if math.Abs(fps-v) < 0.01 {
+			return true
+		}
+	}
+	return false
+}
+
+// nominalFPS is the number of frame numbers per second, e.g. 30 for 29.97
+func (t Timecode) nominalFPS() int {
+	return int(math.Round(t.FPS))
+}
+
+// droppedFrames is the number of frame numbers dropped per minute, e.g. 2 for 29.97
+func (t Timecode) droppedFrames() int {
+	return int(math.Round(t.FPS * 0.066666))
+}
+
+// Frame returns the frame number of the timecode
+func (t Timecode) Frame() (n int) {
+	n = ((t.Hours*60+t.Minutes)*60+t.Seconds)*t.nominalFPS() + t.Frames
+	if t.DropFrame {
+		m := t.Hours*60 + t.Minutes
+		n -= t.droppedFrames() * (m - m/10)
+	}
+	return
+}
+
+// Duration returns the wall clock duration of the timecode
+func (t Timecode) Duration() time.Duration {
+	if t.FPS <= 0 {
+		return 0
+	}
+	return time.Duration(math.Round(float64(t.Frame()) / t.FPS * float64(time.Second)))
+}
+
+// seconds returns the wall clock duration of the timecode as ffmpeg expects it, in seconds with microseconds
+// precision so that it's not rounded to a neighbouring frame
+func (t Timecode) seconds() string {
+	if t.FPS <= 0 {
+		return "0"
+	}
+	return strconv.FormatFloat(float64(t.Frame())/t.FPS, 'f', 6, 64)
+}
+
+// String returns the timecode in its SMPTE representation
+func (t Timecode) String() string {
+	sep := ":"
+	if t.DropFrame {
+		sep = ";"
+	}
+	return fmt.Sprintf("%02d:%02d:%02d%s%02d", t.Hours, t.Minutes, t.Seconds, sep, t.Frames)
+}
@@ -0,0 +1,92 @@
+package astiffmpeg
+
+import (
+	"os/exec"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTimecode(t *testing.T) {
+	tc, err := ParseTimecode("01:00:10:12", 25)
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	if e, g := 90262, tc.Frame(); e != g {
+		t.Errorf("expected %d, got %d", e, g)
+	}
+	if e, g := time.Hour+10*time.Second+480*time.Millisecond, tc.Duration(); e != g {
+		t.Errorf("expected %s, got %s", e, g)
+	}
+	if tc, err = TimecodeFromDuration(tc.Duration(), 25, false); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	if e, g := "01:00:10:12", tc.String(); e != g {
+		t.Errorf("expected %s, got %s", e, g)
+	}
+
+	for _, i := range []struct {
+		f  int
+		tc string
+	}{
+		{f: 0, tc: "00:00:00;00"},
+		{f: 1799, tc: "00:00:59;29"},
+		{f: 1800, tc: "00:01:00;02"},
+		{f: 17982, tc: "00:10:00;00"},
+		{f: 107892, tc: "01:00:00;00"},
+	} {
+		tc, err = ParseTimecode(i.tc, 30000.0/1001)
+		if err != nil {
+			t.Errorf("expected no error, got %s", err)
+		}
+		if g := tc.Frame(); i.f != g {
+			t.Errorf("expected %d, got %d", i.f, g)
+		}
+		if tc, err = TimecodeFromFrame(i.f, 30000.0/1001, true); err != nil {
+			t.Errorf("expected no error, got %s", err)
+		}
+		if g := tc.String(); i.tc != g {
+			t.Errorf("expected %s, got %s", i.tc, g)
+		}
+	}
+
+	if _, err = ParseTimecode("00:01:00;01", 30000.0/1001); err == nil {
+		t.Error("expected error")
+	}
+	if _, err = ParseTimecode("00:00:00:25", 25); err == nil {
+		t.Error("expected error")
+	}
+	if _, err = ParseTimecode("00:00:10;12", 25); err == nil {
+		t.Error("expected error")
+	}
+	if _, err = TimecodeFromFrame(10, 25, true); err == nil {
+		t.Error("expected error")
+	}
+	if tc, err = TimecodeFromFrame(3600, 60000.0/1001, true); err != nil {
+		t.Errorf("expected no error, got %s", err)
+	}
+	if e, g := "00:01:00;04", tc.String(); e != g {
+		t.Errorf("expected %s, got %s", e, g)
+	}
+}
+
+func TestTimecodeOptions(t *testing.T) {
+	p, err := ParseTimecode("00:00:10;02", 30000.0/1001)
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	d, err := ParseTimecode("00:00:01:00", 25)
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	cmd := exec.Command("ffmpeg")
+	if err = (DecodingOptions{Position: time.Second, PositionTimecode: &p}).adaptCmd(cmd); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	if err = (OutputOptions{DurationTimecode: &d}).adaptCmd(cmd); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	if e := []string{"ffmpeg", "-ss", "10.076733", "-t", "1.000000"}; !reflect.DeepEqual(e, cmd.Args) {
+		t.Errorf("expected %+v, got %+v", e, cmd.Args)
+	}
+}
@@ -0,0 +1,116 @@
+package astiffmpeg
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/asticode/go-astikit"
+)
+
+// VFR severities
+const (
+	// Frame durations never change
+	VFRSeverityNone = "none"
+	// Less than 10% of frame durations change, which is usually caused by a few dropped frames
+	VFRSeverityMild = "mild"
+	// Source is really VFR (e.g. phone or screen recordings) and should be converted to CFR before being edited
+	VFRSeveritySevere = "severe"
+)
+
+// VFRReport represents a VFR report
+type VFRReport struct {
+	// Frame durations, in time base units. Only set when VFRFrames > 0.
+	AvgDelta int64
+	// Frames with the same duration as the previous one
+	CFRFrames int
+	// Frames that would be dropped or duplicated when converting to CFR at the input frame rate
+	Drop     int
+	Dup      int
+	MaxDelta int64
+	MinDelta int64
+	// Ratio of frames whose duration differs from the previous one, from 0 (CFR) to 1
+	Ratio float64
+	// Frames whose duration differs from the previous one
+	VFRFrames int
+}
+
+// IsVFR returns whether the source is VFR
+func (r VFRReport) IsVFR() bool {
+	return r.VFRFrames > 0
+}
+
+// Severity returns how VFR the source is, see VFRSeverity constants
+func (r VFRReport) Severity() string {
+	switch {
+	case r.VFRFrames == 0:
+		return VFRSeverityNone
+	case r.Ratio < 0.1:
+		return VFRSeverityMild
+	}
+	return VFRSeveritySevere
+}
+
+// [Parsed_vfrdet_0 @ 0x55d] VFR:0.400000 (40/60) min: 1001 max: 2002 avg: 1501
+var vfrdetRegexp = regexp.MustCompile(`VFR:([\d.]+) \((\d+)/(\d+)\)(?: min: (-?\d+) max: (-?\d+) avg: (-?\d+))?`)
+
+// DetectVFR decodes the first video stream of the input in a null output pass with the vfrdet filter and reports
+// whether it's VFR and how severe, as well as how many frames converting it to CFR would drop or duplicate
+func (f *FFMpeg) DetectVFR(ctx context.Context, g GlobalOptions, in Input) (r VFRReport, err error) {
+	// Start job
+	var j *Job
+	if j, err = f.ExecAsync(ctx, g, []Input{in}, NullOutput(&OutputOptions{
+		Encoding: &EncodingOptions{Filters: []StreamOption{videoStreamOption(FilterOptions{
+			Generic: []GenericFilter{{Name: "vfrdet"}},
+		})}},
+		FPSMode: FPSModeCFR,
+		Map:     &MapOptions{{Stream: &StreamSpecifier{Index: astikit.IntPtr(0), Type: StreamSpecifierTypeVideo}}},
+		NoAudio: true,
+	})); err != nil {
+		err = fmt.Errorf("astiffmpeg: starting job failed: %w", err)
+		return
+	}
+
+	// Wait
+	if err = j.Wait(); err != nil {
+		err = fmt.Errorf("astiffmpeg: waiting for job failed: %w", err)
+		return
+	}
+
+	// Parse report
+	if r, err = parseVFRReport(j.bufErr.Bytes()); err != nil {
+		err = fmt.Errorf("astiffmpeg: parsing vfr report failed: %w", err)
+		return
+	}
+	return
+}
+
+func parseVFRReport(b []byte) (r VFRReport, err error) {
+	// Parse vfrdet
+	ms := vfrdetRegexp.FindSubmatch(b)
+	if len(ms) < 7 {
+		err = fmt.Errorf("astiffmpeg: no vfrdet report found in %s", b)
+		return
+	}
+	r.Ratio, _ = strconv.ParseFloat(string(ms[1]), 64)
+	r.VFRFrames, _ = strconv.Atoi(string(ms[2]))
+	r.CFRFrames, _ = strconv.Atoi(string(ms[3]))
+	if len(ms[4]) > 0 {
+		r.MinDelta, _ = strconv.ParseInt(string(ms[4]), 10, 64)
+		r.MaxDelta, _ = strconv.ParseInt(string(ms[5]), 10, 64)
+		r.AvgDelta, _ = strconv.ParseInt(string(ms[6]), 10, 64)
+	}
+
+	// Parse drops and dups
+	if l, ok := lastStatsLine(b); ok {
+		sr := defaultStdErrParser{}.parseResults(l)
+		if sr.Drop != nil {
+			r.Drop = *sr.Drop
+		}
+		if sr.Dup != nil {
+			r.Dup = *sr.Dup
+		}
+	}
+	return
+}
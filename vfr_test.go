@@ -0,0 +1,30 @@
+package astiffmpeg
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseVFRReport(t *testing.T) {
+	if _, err := parseVFRReport([]byte("invalid")); err == nil {
+		t.Error("expected error")
+	}
+	r, err := parseVFRReport([]byte("frame=  100 fps=0.0 q=-0.0 Lsize=N/A time=00:00:04.00 bitrate=N/A dup=12 drop=3 speed=80x\n" +
+		"[Parsed_vfrdet_0 @ 0x55d] VFR:0.400000 (40/60) min: 1001 max: 2002 avg: 1501\n"))
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	e := VFRReport{AvgDelta: 1501, CFRFrames: 60, Drop: 3, Dup: 12, MaxDelta: 2002, MinDelta: 1001, Ratio: 0.4, VFRFrames: 40}
+	if !reflect.DeepEqual(e, r) {
+		t.Errorf("expected %+v, got %+v", e, r)
+	}
+	if !r.IsVFR() || r.Severity() != VFRSeveritySevere {
+		t.Errorf("expected severe vfr, got %s", r.Severity())
+	}
+	if r, err = parseVFRReport([]byte("[Parsed_vfrdet_0 @ 0x55d] VFR:0.000000 (0/100)\n")); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	if r.IsVFR() || r.Severity() != VFRSeverityNone {
+		t.Errorf("expected no vfr, got %s", r.Severity())
+	}
+}
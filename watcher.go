@@ -0,0 +1,295 @@
+package astiffmpeg
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// WatchOptions represents watch options
+type WatchOptions struct {
+	// Sources are moved to this directory once processed successfully. They're left in place when empty.
+	DoneDir string
+	// Sources are moved to this directory when processing failed. They're left in place when empty.
+	FailedDir string
+	// Executed when listing the directory failed, which is retried at the next poll, or when file system
+	// notifications failed
+	OnError func(err error)
+	// Executed once a file has been processed
+	OnReport func(r BatchReport)
+	// Only files whose base name matches one of these glob patterns are processed
+	Patterns []string
+	// Polls the directory instead of relying on file system notifications, which are not delivered for network shares
+	// (NFS, SMB)
+	Poll bool
+	// Period at which the directory is listed while files are not stable yet, or when polling. Defaults to 1s.
+	PollPeriod time.Duration
+	// Files are considered complete once their size and modification time haven't changed for this duration.
+	// Defaults to 5s.
+	StableDuration time.Duration
+	Template       JobTemplate
+}
+
+// Watch monitors the directory and processes files once they're complete, one at a time, until the context is
+// done
+// The directory is listed whenever a file system notification is received and then periodically until files are
+// stable, since there's no notification when a copy is complete. It's polled instead when notifications are not
+// available or when WatchOptions.Poll is true.
+// Outputs written to the directory are never processed.
+func (f *FFMpeg) Watch(ctx context.Context, dir string, o WatchOptions) (err error) {
+	// Default options
+	if o.PollPeriod <= 0 {
+		o.PollPeriod = time.Second
+	}
+	if o.StableDuration <= 0 {
+		o.StableDuration = 5 * time.Second
+	}
+
+	// Check patterns, since errors are otherwise only retried while listing
+	for _, pattern := range o.Patterns {
+		if _, err = filepath.Match(pattern, ""); err != nil {
+			err = fmt.Errorf("astiffmpeg: invalid pattern %s: %w", pattern, err)
+			return
+		}
+	}
+
+	// Create directories
+	for _, d := range []string{o.DoneDir, o.FailedDir} {
+		if len(d) == 0 {
+			continue
+		}
+		if err = os.MkdirAll(d, 0755); err != nil {
+			err = fmt.Errorf("astiffmpeg: mkdirall %s failed: %w", d, err)
+			return
+		}
+	}
+
+	// Create notifier
+	// Polling is used as a fallback when notifications are not available
+	var events <-chan fsnotify.Event
+	var errs <-chan error
+	if !o.Poll {
+		if w, errNotify := newWatchNotifier(dir); errNotify != nil {
+			if o.OnError != nil {
+				o.OnError(fmt.Errorf("astiffmpeg: creating notifier failed, polling instead: %w", errNotify))
+			}
+		} else {
+			defer w.Close()
+			events, errs = w.Events, w.Errors
+		}
+	}
+
+	// Loop
+	s := newStabilityTracker(o.StableDuration)
+	t := time.NewTicker(o.PollPeriod)
+	defer t.Stop()
+	for {
+		// Process
+		// Listing may fail temporarily (e.g. when a network share is unavailable)
+		if errWatch := f.watchOnce(ctx, dir, o, s); errWatch != nil && o.OnError != nil {
+			o.OnError(fmt.Errorf("astiffmpeg: watching once failed: %w", errWatch))
+		}
+
+		// Files that are not stable yet need to be checked again even if nothing is notified
+		var tc <-chan time.Time
+		if events == nil || s.pending() {
+			tc = t.C
+		}
+
+		// Wait
+	wait:
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-tc:
+				break wait
+			case _, ok := <-events:
+				// Notifier has been closed
+				if !ok {
+					events, errs = nil, nil
+					break wait
+				}
+
+				// Notifications are ignored until pending files are checked by the ticker, otherwise the directory
+				// would be listed on each write
+				if tc == nil {
+					break wait
+				}
+			case errNotify, ok := <-errs:
+				if !ok {
+					errs = nil
+					continue
+				}
+				if o.OnError != nil {
+					o.OnError(fmt.Errorf("astiffmpeg: notifier failed: %w", errNotify))
+				}
+
+				// Events may have been lost
+				break wait
+			}
+		}
+	}
+}
+
+func newWatchNotifier(dir string) (w *fsnotify.Watcher, err error) {
+	// Create watcher
+	if w, err = fsnotify.NewWatcher(); err != nil {
+		err = fmt.Errorf("astiffmpeg: creating watcher failed: %w", err)
+		return
+	}
+
+	// Add directory
+	if err = w.Add(dir); err != nil {
+		w.Close()
+		err = fmt.Errorf("astiffmpeg: adding %s to watcher failed: %w", dir, err)
+		return
+	}
+	return
+}
+
+func (f *FFMpeg) watchOnce(ctx context.Context, dir string, o WatchOptions, s *stabilityTracker) (err error) {
+	// List files
+	var ps []string
+	if ps, err = batchFiles(dir, o.Patterns, false); err != nil {
+		err = fmt.Errorf("astiffmpeg: listing files failed: %w", err)
+		return
+	}
+
+	// Loop through files
+	n := time.Now()
+	for _, p := range s.update(ps, n) {
+		// Context is done
+		if ctx.Err() != nil {
+			return
+		}
+
+		// Process
+		r := BatchReport{Path: p}
+		var sp JobSpec
+		if sp, r.Err = o.Template(p); r.Err != nil {
+			r.Err = fmt.Errorf("astiffmpeg: creating job failed: %w", r.Err)
+		} else {
+			n := time.Now()
+			if r.Err = f.Exec(ctx, sp.Global, sp.Inputs, sp.Output); r.Err != nil {
+				r.Err = fmt.Errorf("astiffmpeg: executing failed: %w", r.Err)
+			}
+			r.Duration = time.Since(n)
+
+			// Output may be written to the watched directory
+			s.ignore(sp.Output.Path)
+		}
+
+		// Context was cancelled while processing, the file will be processed again next time
+		if ctx.Err() != nil {
+			return
+		}
+
+		// Move source
+		d := o.DoneDir
+		if r.Err != nil {
+			d = o.FailedDir
+		}
+		if len(d) > 0 {
+			if err := os.Rename(p, filepath.Join(d, filepath.Base(p))); err != nil && r.Err == nil {
+				r.Err = fmt.Errorf("astiffmpeg: moving %s to %s failed: %w", p, d, err)
+			}
+		}
+
+		// Callback
+		if o.OnReport != nil {
+			o.OnReport(r)
+		}
+	}
+	return
+}
+
+type stabilityTracker struct {
+	d         time.Duration
+	files     map[string]stabilityTrackerFile
+	processed map[string]bool // Indexed by absolute path
+	stat      func(p string) (os.FileInfo, error)
+}
+
+type stabilityTrackerFile struct {
+	modTime time.Time
+	size    int64
+	since   time.Time
+}
+
+func newStabilityTracker(d time.Duration) *stabilityTracker {
+	return &stabilityTracker{
+		d:         d,
+		files:     make(map[string]stabilityTrackerFile),
+		processed: make(map[string]bool),
+		stat:      os.Stat,
+	}
+}
+
+func stabilityTrackerKey(p string) string {
+	if a, err := filepath.Abs(p); err == nil {
+		return a
+	}
+	return filepath.Clean(p)
+}
+
+// pending returns whether some files are not stable yet
+func (t *stabilityTracker) pending() bool {
+	return len(t.files) > 0
+}
+
+// ignore makes sure the file is never returned as long as it exists
+func (t *stabilityTracker) ignore(p string) {
+	t.processed[stabilityTrackerKey(p)] = true
+	delete(t.files, p)
+}
+
+// update returns the files that have been stable long enough and that have not been returned or ignored yet
+func (t *stabilityTracker) update(ps []string, n time.Time) (stables []string) {
+	// Loop through paths
+	m := make(map[string]bool)
+	for _, p := range ps {
+		// Stat
+		k := stabilityTrackerKey(p)
+		m[k] = true
+		fi, err := t.stat(p)
+		if err != nil || t.processed[k] {
+			continue
+		}
+
+		// File has changed
+		f, ok := t.files[p]
+		if !ok || f.size != fi.Size() || !f.modTime.Equal(fi.ModTime()) {
+			t.files[p] = stabilityTrackerFile{
+				modTime: fi.ModTime(),
+				size:    fi.Size(),
+				since:   n,
+			}
+			continue
+		}
+
+		// File is stable
+		if n.Sub(f.since) >= t.d {
+			t.processed[k] = true
+			delete(t.files, p)
+			stables = append(stables, p)
+		}
+	}
+
+	// Forget files that have disappeared
+	for p := range t.files {
+		if !m[stabilityTrackerKey(p)] {
+			delete(t.files, p)
+		}
+	}
+	for p := range t.processed {
+		if !m[p] {
+			delete(t.processed, p)
+		}
+	}
+	return
+}
@@ -0,0 +1,85 @@
+package astiffmpeg
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type mockFileInfo struct {
+	os.FileInfo
+	modTime time.Time
+	size    int64
+}
+
+func (i mockFileInfo) ModTime() time.Time { return i.modTime }
+func (i mockFileInfo) Size() int64        { return i.size }
+
+func TestStabilityTracker(t *testing.T) {
+	n := time.Unix(0, 0)
+	sizes := map[string]int64{"a": 1, "b": 1}
+	s := newStabilityTracker(time.Second)
+	s.stat = func(p string) (os.FileInfo, error) { return mockFileInfo{modTime: n, size: sizes[p]}, nil }
+	if g := s.update([]string{"a", "b"}, n); len(g) > 0 {
+		t.Errorf("expected no stable files, got %+v", g)
+	}
+	sizes["b"] = 2
+	if g := s.update([]string{"a", "b"}, n.Add(time.Second)); !reflect.DeepEqual([]string{"a"}, g) {
+		t.Errorf("expected [a], got %+v", g)
+	}
+	if g := s.update([]string{"a", "b"}, n.Add(2*time.Second)); !reflect.DeepEqual([]string{"b"}, g) {
+		t.Errorf("expected [b], got %+v", g)
+	}
+	if g := s.update([]string{"a", "b"}, n.Add(3*time.Second)); len(g) > 0 {
+		t.Errorf("expected no stable files, got %+v", g)
+	}
+
+	// Outputs are ignored
+	s.ignore("./c")
+	s.update([]string{"a", "b", "c"}, n.Add(4*time.Second))
+	if g := s.update([]string{"a", "b", "c"}, n.Add(5*time.Second)); len(g) > 0 {
+		t.Errorf("expected no stable files, got %+v", g)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	for _, poll := range []bool{false, true} {
+		dir := t.TempDir()
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		rs := make(chan BatchReport, 1)
+		errTemplate := errors.New("template")
+		done := make(chan error)
+		go func() {
+			done <- New(Configuration{}).Watch(ctx, dir, WatchOptions{
+				OnReport:       func(r BatchReport) { rs <- r },
+				Poll:           poll,
+				PollPeriod:     10 * time.Millisecond,
+				StableDuration: 50 * time.Millisecond,
+				Template:       func(p string) (JobSpec, error) { return JobSpec{}, errTemplate },
+			})
+		}()
+
+		// Wait for the notifier to be created
+		time.Sleep(50 * time.Millisecond)
+		p := filepath.Join(dir, "in.mp4")
+		if err := os.WriteFile(p, []byte("in"), 0644); err != nil {
+			t.Fatalf("expected no error, got %s", err)
+		}
+		select {
+		case r := <-rs:
+			if r.Path != p || !errors.Is(r.Err, errTemplate) {
+				t.Errorf("poll %v: expected report for %s with template error, got %+v", poll, p, r)
+			}
+		case <-ctx.Done():
+			t.Errorf("poll %v: expected report", poll)
+		}
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("poll %v: expected no error, got %s", poll, err)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package astiffmpeg
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// Peaks represents waveform peaks
+// Its JSON representation is compatible with audiowaveform's one and can therefore be used by waveform player
+// libraries such as peaks.js or wavesurfer.js
+type Peaks struct {
+	Bits            int   `json:"bits"`
+	Channels        int   `json:"channels"`
+	Data            []int `json:"data"` // Min and max values of each bucket, interleaved
+	Length          int   `json:"length"`
+	SampleRate      int   `json:"sample_rate"`
+	SamplesPerPixel int   `json:"samples_per_pixel"`
+	Version         int   `json:"version"`
+}
+
+// PeaksOptions represents peaks options
+type PeaksOptions struct {
+	SampleRate      int // Defaults to 44100
+	SamplesPerPixel int // Number of samples per bucket, defaults to 256
+}
+
+// Peaks decodes the input's audio into mono 16 bits PCM through a pipe and computes the min and max values of each
+// bucket of samples
+func (f *FFMpeg) Peaks(ctx context.Context, g GlobalOptions, in Input, o PeaksOptions) (p Peaks, err error) {
+	// Default options
+	if o.SampleRate <= 0 {
+		o.SampleRate = 44100
+	}
+	if o.SamplesPerPixel <= 0 {
+		o.SamplesPerPixel = 256
+	}
+
+	// Create writer
+	w := newPeaksWriter(o.SamplesPerPixel)
+
+	// Start job
+	var j *Job
+	if j, err = f.DecodePCM(ctx, g, in, PCMOptions{
+		Channels:   1,
+		Format:     PCMFormatS16LE,
+		SampleRate: o.SampleRate,
+	}, w); err != nil {
+		err = fmt.Errorf("astiffmpeg: decoding pcm failed: %w", err)
+		return
+	}
+
+	// Wait
+	if err = j.Wait(); err != nil {
+		err = fmt.Errorf("astiffmpeg: waiting for job failed: %w", err)
+		return
+	}
+
+	// Create peaks
+	p = Peaks{
+		Bits:            16,
+		Channels:        1,
+		Data:            w.data(),
+		SampleRate:      o.SampleRate,
+		SamplesPerPixel: o.SamplesPerPixel,
+		Version:         2,
+	}
+	p.Length = len(p.Data) / 2
+	return
+}
+
+type peaksWriter struct {
+	count           int
+	d               []int
+	max, min        int16
+	rest            []byte
+	samplesPerPixel int
+}
+
+func newPeaksWriter(samplesPerPixel int) *peaksWriter {
+	return &peaksWriter{
+		max:             math.MinInt16,
+		min:             math.MaxInt16,
+		samplesPerPixel: samplesPerPixel,
+	}
+}
+
+func (w *peaksWriter) Write(b []byte) (int, error) {
+	// Samples may be split between writes
+	n := len(b)
+	if len(w.rest) > 0 {
+		b = append(w.rest, b...)
+		w.rest = nil
+	}
+
+	// Loop through samples
+	for ; len(b) >= 2; b = b[2:] {
+		// Update min/max
+		s := int16(binary.LittleEndian.Uint16(b))
+		if s < w.min {
+			w.min = s
+		}
+		if s > w.max {
+			w.max = s
+		}
+
+		// Bucket is complete
+		w.count++
+		if w.count == w.samplesPerPixel {
+			w.flush()
+		}
+	}
+
+	// Store rest
+	if len(b) > 0 {
+		w.rest = append(w.rest, b...)
+	}
+	return n, nil
+}
+
+func (w *peaksWriter) flush() {
+	w.d = append(w.d, int(w.min), int(w.max))
+	w.count = 0
+	w.max = math.MinInt16
+	w.min = math.MaxInt16
+}
+
+func (w *peaksWriter) data() []int {
+	if w.count > 0 {
+		w.flush()
+	}
+	return w.d
+}
@@ -0,0 +1,17 @@
+package astiffmpeg
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPeaksWriter(t *testing.T) {
+	w := newPeaksWriter(2)
+	// Samples: 1, -2, 300, 4, -5
+	w.Write([]byte{0x01, 0x00, 0xfe})
+	w.Write([]byte{0xff, 0x2c, 0x01, 0x04})
+	w.Write([]byte{0x00, 0xfb, 0xff})
+	if e, g := []int{-2, 1, 4, 300, -5, -5}, w.data(); !reflect.DeepEqual(e, g) {
+		t.Errorf("expected %+v, got %+v", e, g)
+	}
+}
@@ -0,0 +1,73 @@
+package astiffmpeg
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"time"
+
+	"github.com/asticode/go-astikit"
+)
+
+// WHIPOptions represents options of the whip muxer, which is only available in recent ffmpeg builds (>= 8.0)
+// ICE servers can't be configured since the whip muxer only gathers host candidates.
+type WHIPOptions struct {
+	// Bearer token sent in the Authorization header
+	BearerToken string
+	// Timeout of the ICE and DTLS handshakes
+	HandshakeTimeout *time.Duration
+	// Maximum size of RTP packets, defaults to 1200
+	PacketSize *int
+}
+
+func (o WHIPOptions) adaptCmd(cmd *exec.Cmd) {
+	if len(o.BearerToken) > 0 {
+		cmd.Args = append(cmd.Args, "-authorization", o.BearerToken)
+	}
+	if o.HandshakeTimeout != nil {
+		cmd.Args = append(cmd.Args, "-handshake_timeout", strconv.FormatInt(o.HandshakeTimeout.Milliseconds(), 10))
+	}
+	if o.PacketSize != nil {
+		cmd.Args = append(cmd.Args, "-pkt_size", strconv.Itoa(*o.PacketSize))
+	}
+}
+
+// WHIPOutput creates an output publishing to a WHIP endpoint, which lets browsers be reached directly over WebRTC
+// Video is encoded with H.264 without B-frames and audio with Opus, which is what browsers support.
+func WHIPOutput(url string, o WHIPOptions) Output {
+	return Output{
+		Options: &OutputOptions{
+			Encoding: &EncodingOptions{
+				AudioSamplerate: astikit.IntPtr(48000),
+				BFrames:         astikit.IntPtr(0),
+				Codec: []StreamOption{
+					videoStreamOption(CodecLibx264),
+					audioStreamOption(CodecLibopus),
+				},
+				PixelFormat: PixelFormatYUV420P,
+				Tune:        TuneZerolatency,
+			},
+			Format: "whip",
+			Muxing: &MuxingOptions{WHIP: &o},
+		},
+		Path: url,
+	}
+}
+
+// PublishWHIP publishes the input to a WHIP endpoint
+// It returns an error wrapping ErrMuxerNotAvailable when the local ffmpeg build doesn't support WHIP.
+func (f *FFMpeg) PublishWHIP(ctx context.Context, g GlobalOptions, in Input, url string, o WHIPOptions) (err error) {
+	// Check muxer
+	if err = f.CheckMuxer(ctx, "whip"); err != nil {
+		err = fmt.Errorf("astiffmpeg: checking muxer failed: %w", err)
+		return
+	}
+
+	// Exec
+	if err = f.Exec(ctx, g, []Input{in}, WHIPOutput(url, o)); err != nil {
+		err = fmt.Errorf("astiffmpeg: executing failed: %w", err)
+		return
+	}
+	return
+}
@@ -0,0 +1,273 @@
+package astiffmpeg
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ZMQFilter creates a zmq filter (azmq for audio) receiving commands sent to the specified bind address (e.g.
+// "tcp://127.0.0.1:5555") and forwarding them to the other filters of the graph, which allows adjusting e.g.
+// overlay text, volume or EQ while the stream is running. See ZMQClient.
+// It's only available when ffmpeg is built with libzmq.
+func ZMQFilter(bindAddress string, audio bool) GenericFilter {
+	f := GenericFilter{
+		Args: map[string]string{"bind_address": bindAddress},
+		Name: "zmq",
+	}
+	if audio {
+		f.Name = "azmq"
+	}
+	return f
+}
+
+// ZMQClient sends commands to a zmq filter
+// It implements the subset of ZMTP 3.0 (REQ socket, NULL security mechanism) the zmq filter requires, which means
+// it doesn't depend on libzmq. It's safe for concurrent use. Once a command has failed because of its context, the
+// client should be closed since the reply may still be pending.
+type ZMQClient struct {
+	c net.Conn
+	m *sync.Mutex
+	r *bufio.Reader
+}
+
+// DialZMQ connects to a zmq filter bind address (e.g. "tcp://127.0.0.1:5555")
+func DialZMQ(ctx context.Context, address string) (c *ZMQClient, err error) {
+	// Only tcp is supported
+	addr := strings.TrimPrefix(address, "tcp://")
+	if strings.Contains(addr, "://") {
+		err = fmt.Errorf("astiffmpeg: unsupported zmq address %s", address)
+		return
+	}
+
+	// Dial
+	var d net.Dialer
+	var conn net.Conn
+	if conn, err = d.DialContext(ctx, "tcp", addr); err != nil {
+		err = fmt.Errorf("astiffmpeg: dialing %s failed: %w", addr, err)
+		return
+	}
+
+	// Create client
+	c = &ZMQClient{
+		c: conn,
+		m: &sync.Mutex{},
+		r: bufio.NewReader(conn),
+	}
+
+	// Handshake
+	if err = c.withContext(ctx, c.handshake); err != nil {
+		conn.Close()
+		err = fmt.Errorf("astiffmpeg: handshake failed: %w", err)
+		return
+	}
+	return
+}
+
+// Close closes the connection
+func (c *ZMQClient) Close() error {
+	return c.c.Close()
+}
+
+// SendCommand sends a command to the target filter (e.g. "Parsed_drawtext_1" or the instance name "drawtext@title")
+// and returns an error if the filter rejected it
+func (c *ZMQClient) SendCommand(ctx context.Context, target, command, arg string) (err error) {
+	// Send request
+	var reply []byte
+	if reply, err = c.request(ctx, []byte(target+" "+command+" "+arg)); err != nil {
+		err = fmt.Errorf("astiffmpeg: sending request failed: %w", err)
+		return
+	}
+
+	// Parse reply, e.g. "0 Success" or "-22 Invalid argument"
+	ps := strings.SplitN(string(reply), " ", 2)
+	if code, errAtoi := strconv.Atoi(ps[0]); errAtoi != nil || code < 0 {
+		err = fmt.Errorf("astiffmpeg: command failed: %s", reply)
+		return
+	}
+	return
+}
+
+// Replies of the zmq filter are short, which means bigger frames are only sent by malformed or hostile peers
+const zmqMaxFrameSize = 64 * 1024
+
+// ZMTP frame flags
+const (
+	zmtpFlagCommand = 0x04
+	zmtpFlagLong    = 0x02
+	zmtpFlagMore    = 0x01
+)
+
+func (c *ZMQClient) handshake() (err error) {
+	// Send greeting: signature, version 3.0, NULL mechanism and as-server set to false
+	g := make([]byte, 64)
+	g[0], g[9], g[10] = 0xff, 0x7f, 3
+	copy(g[12:], "NULL")
+	if _, err = c.c.Write(g); err != nil {
+		err = fmt.Errorf("astiffmpeg: writing greeting failed: %w", err)
+		return
+	}
+
+	// Read greeting
+	if _, err = io.ReadFull(c.r, g); err != nil {
+		err = fmt.Errorf("astiffmpeg: reading greeting failed: %w", err)
+		return
+	}
+	if g[0] != 0xff || g[9] != 0x7f || g[10] < 3 {
+		err = errors.New("astiffmpeg: invalid greeting")
+		return
+	}
+
+	// Send READY command
+	b := []byte("\x05READY\x0bSocket-Type\x00\x00\x00\x03REQ")
+	if err = c.writeFrame(zmtpFlagCommand, b); err != nil {
+		err = fmt.Errorf("astiffmpeg: writing ready command failed: %w", err)
+		return
+	}
+
+	// Read READY command
+	var flags byte
+	if flags, b, err = c.readFrame(); err != nil {
+		err = fmt.Errorf("astiffmpeg: reading ready command failed: %w", err)
+		return
+	}
+	if flags&zmtpFlagCommand == 0 || len(b) < 6 || string(b[1:6]) != "READY" {
+		err = errors.New("astiffmpeg: invalid ready command")
+		return
+	}
+	return
+}
+
+// withContext executes the function with the connection deadline set from the context, and interrupts it once the
+// context is cancelled
+func (c *ZMQClient) withContext(ctx context.Context, fn func() error) error {
+	// Set deadline
+	if d, ok := ctx.Deadline(); ok {
+		c.c.SetDeadline(d)
+	}
+	defer c.c.SetDeadline(time.Time{})
+
+	// Interrupt on cancellation
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		select {
+		case <-ctx.Done():
+			c.c.SetDeadline(time.Unix(1, 0))
+		case <-done:
+		}
+	}()
+	defer func() {
+		close(done)
+		<-exited
+	}()
+
+	// Execute
+	if err := fn(); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("%w: %s", ctx.Err(), err)
+		}
+		return err
+	}
+	return nil
+}
+
+func (c *ZMQClient) request(ctx context.Context, msg []byte) (reply []byte, err error) {
+	// Lock
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	// Execute
+	err = c.withContext(ctx, func() (err error) {
+		reply, err = c.exchange(msg)
+		return
+	})
+	return
+}
+
+func (c *ZMQClient) exchange(msg []byte) (reply []byte, err error) {
+	// REQ sockets prefix messages with an empty delimiter
+	if err = c.writeFrame(zmtpFlagMore, nil); err != nil {
+		err = fmt.Errorf("astiffmpeg: writing delimiter failed: %w", err)
+		return
+	}
+	if err = c.writeFrame(0, msg); err != nil {
+		err = fmt.Errorf("astiffmpeg: writing message failed: %w", err)
+		return
+	}
+
+	// Read frames until the last one
+	for {
+		var flags byte
+		var b []byte
+		if flags, b, err = c.readFrame(); err != nil {
+			err = fmt.Errorf("astiffmpeg: reading frame failed: %w", err)
+			return
+		}
+		if flags&zmtpFlagCommand > 0 {
+			continue
+		}
+		if len(b) > 0 {
+			reply = append(reply, b...)
+		}
+		if flags&zmtpFlagMore == 0 {
+			return
+		}
+	}
+}
+
+func (c *ZMQClient) writeFrame(flags byte, body []byte) (err error) {
+	var b []byte
+	if len(body) > 255 {
+		b = make([]byte, 9)
+		b[0] = flags | zmtpFlagLong
+		binary.BigEndian.PutUint64(b[1:], uint64(len(body)))
+	} else {
+		b = []byte{flags, byte(len(body))}
+	}
+	_, err = c.c.Write(append(b, body...))
+	return
+}
+
+func (c *ZMQClient) readFrame() (flags byte, body []byte, err error) {
+	// Read flags
+	if flags, err = c.r.ReadByte(); err != nil {
+		return
+	}
+
+	// Read size
+	var size uint64
+	if flags&zmtpFlagLong > 0 {
+		b := make([]byte, 8)
+		if _, err = io.ReadFull(c.r, b); err != nil {
+			return
+		}
+		size = binary.BigEndian.Uint64(b)
+	} else {
+		var s byte
+		if s, err = c.r.ReadByte(); err != nil {
+			return
+		}
+		size = uint64(s)
+	}
+
+	// Frame is too big
+	if size > zmqMaxFrameSize {
+		err = fmt.Errorf("astiffmpeg: frame size %d exceeds the max %d", size, zmqMaxFrameSize)
+		return
+	}
+
+	// Read body
+	body = make([]byte, size)
+	_, err = io.ReadFull(c.r, body)
+	return
+}
@@ -0,0 +1,92 @@
+package astiffmpeg
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestZMQClient(t *testing.T) {
+	// Create server
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("listening failed: %s", err)
+	}
+	defer l.Close()
+	msgs := make(chan string, 2)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		s := &ZMQClient{c: conn, r: bufio.NewReader(conn)}
+
+		// Greeting and ready
+		g := make([]byte, 64)
+		if _, err = io.ReadFull(s.r, g); err != nil {
+			return
+		}
+		g[0], g[9], g[10] = 0xff, 0x7f, 3
+		copy(g[12:], "NULL")
+		conn.Write(g)
+		if _, _, err = s.readFrame(); err != nil {
+			return
+		}
+		s.writeFrame(zmtpFlagCommand, []byte("\x05READY\x0bSocket-Type\x00\x00\x00\x03REP"))
+
+		// Requests
+		for _, reply := range []string{"0 Success", "-22 Invalid argument"} {
+			if _, _, err = s.readFrame(); err != nil {
+				return
+			}
+			_, b, err := s.readFrame()
+			if err != nil {
+				return
+			}
+			msgs <- string(b)
+			s.writeFrame(zmtpFlagMore, nil)
+			s.writeFrame(0, []byte(reply))
+		}
+
+		// Stop responding
+		io.Copy(io.Discard, conn)
+	}()
+
+	// Dial
+	c, err := DialZMQ(context.Background(), "tcp://"+l.Addr().String())
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	defer c.Close()
+
+	// Send commands
+	if err = c.SendCommand(context.Background(), "drawtext@title", "reinit", "text=Hello"); err != nil {
+		t.Errorf("expected no error, got %s", err)
+	}
+	if e, g := "drawtext@title reinit text=Hello", <-msgs; e != g {
+		t.Errorf("expected %s, got %s", e, g)
+	}
+	if err = c.SendCommand(context.Background(), "volume@v", "volume", "abc"); err == nil {
+		t.Error("expected error")
+	}
+
+	// Peer stops responding
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err = c.SendCommand(ctx, "volume@v", "volume", "1"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestZMQClientReadFrame(t *testing.T) {
+	c := &ZMQClient{r: bufio.NewReader(bytes.NewReader([]byte{zmtpFlagLong, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}))}
+	if _, _, err := c.readFrame(); err == nil {
+		t.Error("expected error")
+	}
+}